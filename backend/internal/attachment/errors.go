@@ -0,0 +1,35 @@
+package attachment
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Violation reasons reported to clients.
+const (
+	ReasonTooLarge       = "too_large"
+	ReasonTooManyFiles   = "too_many_files"
+	ReasonTypeNotAllowed = "type_not_allowed"
+	ReasonExecutable     = "executable"
+)
+
+// Violation names a rejected file and why it was rejected.
+type Violation struct {
+	File    string `json:"file"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
+
+// RejectedError is returned by Policy.Check when one or more files break the
+// policy.
+type RejectedError struct {
+	Violations []Violation
+}
+
+func (e *RejectedError) Error() string {
+	parts := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		parts = append(parts, fmt.Sprintf("%s: %s", v.File, v.Message))
+	}
+	return "attachment rejected: " + strings.Join(parts, "; ")
+}
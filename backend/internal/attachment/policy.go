@@ -0,0 +1,214 @@
+package attachment
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+
+	"werk-ticketing/internal/constants"
+)
+
+// Policy limits the files a tenant may attach. Zero values fall back to the
+// defaults, see Resolve.
+type Policy struct {
+	// MaxFileSize is the per-file limit in bytes.
+	MaxFileSize int64 `json:"max_file_size,omitempty"`
+	MaxFiles    int   `json:"max_files,omitempty"`
+	// AllowedTypes lists MIME types verified against the file content.
+	// Entries such as "image/*" match a whole family.
+	AllowedTypes []string `json:"allowed_types,omitempty"`
+}
+
+// DefaultTicketTypes are accepted on tickets and comments when a tenant does
+// not configure its own list.
+var DefaultTicketTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"application/pdf",
+	"text/plain",
+	"text/csv",
+	"application/zip",
+	"application/msword",
+	"application/vnd.ms-excel",
+	"application/vnd.ms-powerpoint",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// ImageTypes are accepted by the image upload endpoint.
+var ImageTypes = []string{
+	"image/png",
+	"image/jpeg",
+	"image/gif",
+	"image/webp",
+	"image/svg+xml",
+}
+
+// Executables are rejected regardless of AllowedTypes, both by sniffed type
+// (including any parent type) and by file extension. Extensions are only
+// listed when they always name a program; generic ones such as .bin also
+// hold firmware and data files, and binaries among them are caught by the
+// sniffed type.
+var (
+	executableTypes = map[string]bool{
+		"application/vnd.microsoft.portable-executable": true,
+		"application/x-elf":                             true,
+		"application/x-mach-binary":                     true,
+		"application/x-ms-installer":                    true,
+		"application/jar":                               true,
+		"application/vnd.android.package-archive":       true,
+	}
+	executableExtensions = map[string]bool{
+		".exe": true, ".dll": true, ".com": true, ".scr": true, ".msi": true,
+		".bat": true, ".cmd": true, ".ps1": true, ".vbs": true, ".js": true,
+		".jar": true, ".apk": true, ".app": true, ".sh": true,
+	}
+)
+
+// Resolve returns the policy with defaults applied for unset fields.
+// defaultTypes is used when AllowedTypes is empty. The defaults are also the
+// upper limits: a tenant may lower MaxFileSize and MaxFiles, never raise them.
+func (p *Policy) Resolve(defaultTypes []string) Policy {
+	resolved := Policy{
+		MaxFileSize:  constants.AttachmentMaxFileSize,
+		MaxFiles:     constants.AttachmentMaxFiles,
+		AllowedTypes: defaultTypes,
+	}
+	if p == nil {
+		return resolved
+	}
+	if p.MaxFileSize > 0 && p.MaxFileSize < resolved.MaxFileSize {
+		resolved.MaxFileSize = p.MaxFileSize
+	}
+	if p.MaxFiles > 0 && p.MaxFiles < resolved.MaxFiles {
+		resolved.MaxFiles = p.MaxFiles
+	}
+	if len(p.AllowedTypes) > 0 {
+		resolved.AllowedTypes = p.AllowedTypes
+	}
+	return resolved
+}
+
+// Validate checks a policy submitted by an administrator. Limits must not
+// be negative or above the defaults, and each allowed type must be a MIME
+// type ("type/subtype") or family ("type/*") that is not an executable.
+func (p *Policy) Validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.MaxFileSize < 0 || p.MaxFileSize > constants.AttachmentMaxFileSize {
+		return fmt.Errorf("max_file_size must be between 1 and %d", int64(constants.AttachmentMaxFileSize))
+	}
+	if p.MaxFiles < 0 || p.MaxFiles > constants.AttachmentMaxFiles {
+		return fmt.Errorf("max_files must be between 1 and %d", constants.AttachmentMaxFiles)
+	}
+	for _, entry := range p.AllowedTypes {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		family, subtype, ok := strings.Cut(entry, "/")
+		if !ok || family == "" || subtype == "" || strings.ContainsAny(entry, " ;,") || strings.Contains(subtype, "/") {
+			return fmt.Errorf("allowed_types: %q is not a MIME type", entry)
+		}
+		if executableTypes[entry] {
+			return fmt.Errorf("allowed_types: %s is an executable type", entry)
+		}
+	}
+	return nil
+}
+
+// Check validates every file against the policy. It returns a *RejectedError
+// listing each offending file, or another error when a file cannot be read.
+func (p Policy) Check(files []*multipart.FileHeader) error {
+	var violations []Violation
+	for i, fh := range files {
+		if fh == nil {
+			continue
+		}
+		if p.MaxFiles > 0 && i >= p.MaxFiles {
+			violations = append(violations, Violation{
+				File:    fh.Filename,
+				Reason:  ReasonTooManyFiles,
+				Message: fmt.Sprintf("at most %d files may be attached", p.MaxFiles),
+			})
+			continue
+		}
+
+		v, err := p.checkFile(fh)
+		if err != nil {
+			return fmt.Errorf("inspect %s: %w", fh.Filename, err)
+		}
+		if v != nil {
+			violations = append(violations, *v)
+		}
+	}
+
+	if len(violations) > 0 {
+		return &RejectedError{Violations: violations}
+	}
+	return nil
+}
+
+func (p Policy) checkFile(fh *multipart.FileHeader) (*Violation, error) {
+	if executableExtensions[strings.ToLower(filepath.Ext(fh.Filename))] {
+		return &Violation{File: fh.Filename, Reason: ReasonExecutable, Message: "executable files are not allowed"}, nil
+	}
+	if p.MaxFileSize > 0 && fh.Size > p.MaxFileSize {
+		return &Violation{
+			File:    fh.Filename,
+			Reason:  ReasonTooLarge,
+			Message: fmt.Sprintf("file exceeds the %d byte limit", p.MaxFileSize),
+		}, nil
+	}
+
+	detected, err := Sniff(fh)
+	if err != nil {
+		return nil, err
+	}
+	for m := detected; m != nil; m = m.Parent() {
+		if executableTypes[m.String()] {
+			return &Violation{File: fh.Filename, Reason: ReasonExecutable, Message: "executable files are not allowed"}, nil
+		}
+	}
+
+	contentType, _, _ := strings.Cut(detected.String(), ";")
+	if !typeAllowed(p.AllowedTypes, contentType) {
+		return &Violation{
+			File:    fh.Filename,
+			Reason:  ReasonTypeNotAllowed,
+			Message: fmt.Sprintf("file type %s is not allowed", contentType),
+		}, nil
+	}
+	return nil, nil
+}
+
+// Sniff detects the MIME type of an uploaded file from its content.
+func Sniff(fh *multipart.FileHeader) (*mimetype.MIME, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return mimetype.DetectReader(io.LimitReader(file, constants.AttachmentSniffBytes))
+}
+
+func typeAllowed(allowed []string, contentType string) bool {
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if family, ok := strings.CutSuffix(entry, "/*"); ok {
+			if strings.HasPrefix(contentType, family+"/") {
+				return true
+			}
+			continue
+		}
+		if entry == contentType {
+			return true
+		}
+	}
+	return false
+}
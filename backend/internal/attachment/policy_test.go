@@ -0,0 +1,120 @@
+package attachment_test
+
+import (
+	stdErrors "errors"
+	"mime/multipart"
+	"testing"
+
+	"werk-ticketing/internal/attachment"
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/invgate/invgatetest"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00")
+
+func violations(t *testing.T, err error) []attachment.Violation {
+	t.Helper()
+	var rejected *attachment.RejectedError
+	if !stdErrors.As(err, &rejected) {
+		t.Fatalf("Check error = %v, want *RejectedError", err)
+	}
+	return rejected.Violations
+}
+
+func TestResolveAppliesDefaults(t *testing.T) {
+	policy := (&attachment.Policy{MaxFiles: 2}).Resolve(attachment.DefaultTicketTypes)
+	if policy.MaxFiles != 2 || policy.MaxFileSize == 0 || len(policy.AllowedTypes) == 0 {
+		t.Errorf("Resolve = %+v", policy)
+	}
+}
+
+func TestResolveClampsToLimits(t *testing.T) {
+	policy := (&attachment.Policy{MaxFileSize: 1 << 40, MaxFiles: 1000}).Resolve(attachment.DefaultTicketTypes)
+	if policy.MaxFileSize != constants.AttachmentMaxFileSize || policy.MaxFiles != constants.AttachmentMaxFiles {
+		t.Errorf("Resolve = %+v, want the default limits", policy)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy attachment.Policy
+		valid  bool
+	}{
+		{"lower limits", attachment.Policy{MaxFileSize: 1 << 20, MaxFiles: 1, AllowedTypes: []string{"image/*", "application/pdf"}}, true},
+		{"negative size", attachment.Policy{MaxFileSize: -1}, false},
+		{"size above limit", attachment.Policy{MaxFileSize: constants.AttachmentMaxFileSize + 1}, false},
+		{"files above limit", attachment.Policy{MaxFiles: constants.AttachmentMaxFiles + 1}, false},
+		{"not a MIME type", attachment.Policy{AllowedTypes: []string{"pdf"}}, false},
+		{"executable type", attachment.Policy{AllowedTypes: []string{"application/x-elf"}}, false},
+	} {
+		if err := tc.policy.Validate(); (err == nil) != tc.valid {
+			t.Errorf("%s: Validate = %v, want valid %v", tc.name, err, tc.valid)
+		}
+	}
+}
+
+func TestCheckAcceptsAllowedContent(t *testing.T) {
+	policy := (*attachment.Policy)(nil).Resolve(attachment.DefaultTicketTypes)
+	files := []*multipart.FileHeader{
+		invgatetest.FileHeader(t, "shot.png", pngHeader),
+		invgatetest.FileHeader(t, "notes.txt", []byte("plain notes")),
+	}
+	if err := policy.Check(files); err != nil {
+		t.Errorf("Check = %v", err)
+	}
+}
+
+func TestCheckRejectsByContentNotExtension(t *testing.T) {
+	policy := attachment.Policy{AllowedTypes: []string{"image/*"}}
+
+	got := violations(t, policy.Check([]*multipart.FileHeader{
+		invgatetest.FileHeader(t, "fake.png", []byte("%PDF-1.7 not an image")),
+	}))
+	if len(got) != 1 || got[0].File != "fake.png" || got[0].Reason != attachment.ReasonTypeNotAllowed {
+		t.Errorf("violations = %+v", got)
+	}
+}
+
+func TestCheckBlocksExecutables(t *testing.T) {
+	policy := attachment.Policy{AllowedTypes: []string{"application/octet-stream", "text/plain"}}
+
+	got := violations(t, policy.Check([]*multipart.FileHeader{
+		invgatetest.FileHeader(t, "report.txt", []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00>\x00")),
+		invgatetest.FileHeader(t, "run.bat", []byte("echo hi")),
+	}))
+	if len(got) != 2 || got[0].Reason != attachment.ReasonExecutable || got[1].File != "run.bat" {
+		t.Errorf("violations = %+v", got)
+	}
+}
+
+func TestCheckJudgesBinFilesByContent(t *testing.T) {
+	policy := attachment.Policy{AllowedTypes: []string{"application/octet-stream"}}
+
+	if err := policy.Check([]*multipart.FileHeader{
+		invgatetest.FileHeader(t, "firmware.bin", []byte{0x00, 0x13, 0x37, 0xfe, 0xca, 0x01, 0x02}),
+	}); err != nil {
+		t.Errorf("Check(firmware.bin) = %v, want accepted", err)
+	}
+
+	got := violations(t, policy.Check([]*multipart.FileHeader{
+		invgatetest.FileHeader(t, "tool.bin", []byte("\x7fELF\x02\x01\x01\x00\x00\x00\x00\x00\x00\x00\x00\x00\x02\x00>\x00")),
+	}))
+	if len(got) != 1 || got[0].Reason != attachment.ReasonExecutable {
+		t.Errorf("violations = %+v", got)
+	}
+}
+
+func TestCheckEnforcesSizeAndCount(t *testing.T) {
+	policy := attachment.Policy{MaxFileSize: 4, MaxFiles: 2, AllowedTypes: []string{"text/plain"}}
+
+	got := violations(t, policy.Check([]*multipart.FileHeader{
+		invgatetest.FileHeader(t, "a.txt", []byte("ok")),
+		invgatetest.FileHeader(t, "b.txt", []byte("too long")),
+		invgatetest.FileHeader(t, "c.txt", []byte("ok")),
+	}))
+	if len(got) != 2 || got[0].File != "b.txt" || got[0].Reason != attachment.ReasonTooLarge ||
+		got[1].File != "c.txt" || got[1].Reason != attachment.ReasonTooManyFiles {
+		t.Errorf("violations = %+v", got)
+	}
+}
@@ -0,0 +1,29 @@
+// Package audittest provides an audit.Recorder that keeps events for tests.
+package audittest
+
+import (
+	"context"
+	"sync"
+
+	"werk-ticketing/internal/audit"
+)
+
+// Recorder keeps every recorded event in order.
+type Recorder struct {
+	mu     sync.Mutex
+	events []*audit.Event
+}
+
+// Record keeps ev.
+func (r *Recorder) Record(ctx context.Context, ev *audit.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, ev)
+}
+
+// Events returns the recorded events.
+func (r *Recorder) Events() []*audit.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*audit.Event(nil), r.events...)
+}
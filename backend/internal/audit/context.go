@@ -0,0 +1,34 @@
+package audit
+
+import "context"
+
+// Request describes the HTTP request an action came from.
+type Request struct {
+	IP        string
+	UserAgent string
+	RequestID string
+	// ActorEmail is the authenticated user, if any.
+	ActorEmail string
+}
+
+type requestKey struct{}
+
+// WithRequest returns a context carrying the request details recorded with
+// audit events.
+func WithRequest(ctx context.Context, r Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// WithActor returns a context whose request is attributed to the
+// authenticated user email.
+func WithActor(ctx context.Context, email string) context.Context {
+	r := RequestFrom(ctx)
+	r.ActorEmail = email
+	return WithRequest(ctx, r)
+}
+
+// RequestFrom returns the request details stored in ctx.
+func RequestFrom(ctx context.Context) Request {
+	r, _ := ctx.Value(requestKey{}).(Request)
+	return r
+}
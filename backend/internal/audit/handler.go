@@ -0,0 +1,170 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/response"
+)
+
+// Export formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+var csvHeader = []string{
+	"id", "created_at", "tenant_id", "actor_id", "actor_email", "action",
+	"target_type", "target_id", "ip", "user_agent", "request_id", "changes",
+}
+
+// Handler handles HTTP requests for the audit log
+type Handler struct {
+	service Service
+	// tenantID returns the caller's tenant, whose events are the only ones
+	// served. It is middleware.GetTenantID, passed in because the
+	// middleware package records audit events and cannot be imported here.
+	tenantID func(*gin.Context) string
+	logger   *logrus.Logger
+}
+
+// NewHandler creates a new audit handler
+func NewHandler(service Service, tenantID func(*gin.Context) string, logger *logrus.Logger) *Handler {
+	return &Handler{service: service, tenantID: tenantID, logger: logger}
+}
+
+// List handles GET /admin/audit-events
+func (h *Handler) List(c *gin.Context) {
+	f, ok := h.parseFilter(c)
+	if !ok {
+		return
+	}
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+
+	page, err := h.service.List(c.Request.Context(), f, c.Query("cursor"), limit)
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, err.Error())
+		return
+	}
+	response.Success(c, http.StatusOK, page)
+}
+
+// Export handles GET /admin/audit-events/export. It streams the matching
+// events as CSV or JSON Lines (format=csv|jsonl), newest first.
+func (h *Handler) Export(c *gin.Context) {
+	f, ok := h.parseFilter(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", FormatCSV)
+	if format != FormatCSV && format != FormatJSONL {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "format must be csv or jsonl")
+		return
+	}
+
+	filename := fmt.Sprintf("audit-events-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format)
+	header := c.Writer.Header()
+	header.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	header.Set("Cache-Control", "no-store")
+
+	var write func(*Event) error
+	var flush func() error
+	if format == FormatCSV {
+		header.Set("Content-Type", "text/csv; charset=utf-8")
+		w := csv.NewWriter(c.Writer)
+		if err := w.Write(csvHeader); err != nil {
+			return
+		}
+		write = func(ev *Event) error { return w.Write(csvRecord(ev)) }
+		flush = func() error { w.Flush(); return w.Error() }
+	} else {
+		header.Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(c.Writer)
+		write = func(ev *Event) error { return enc.Encode(ev) }
+		flush = func() error { return nil }
+	}
+	c.Status(http.StatusOK)
+
+	// The status line is already sent, so a failure can only cut the file short.
+	if err := h.service.Export(c.Request.Context(), f, write); err != nil {
+		h.logger.WithError(err).Error("failed to export audit events")
+	}
+	if err := flush(); err != nil {
+		h.logger.WithError(err).Error("failed to write audit export")
+	}
+}
+
+// parseFilter reads the filter from the query string, writing an error
+// response when it is invalid. The tenant is always the caller's.
+func (h *Handler) parseFilter(c *gin.Context) (Filter, bool) {
+	f := Filter{
+		TenantID:   h.tenantID(c),
+		ActorEmail: c.Query("actor"),
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+		TargetID:   c.Query("target_id"),
+		RequestID:  c.Query("request_id"),
+	}
+	if f.TenantID == "" {
+		response.ErrorWithCode(c, http.StatusForbidden, errors.ErrCodeForbidden, "tenant context is required")
+		return f, false
+	}
+	for name, dst := range map[string]*time.Time{"from": &f.From, "to": &f.To} {
+		raw := c.Query(name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, name+" must be an RFC 3339 time")
+			return f, false
+		}
+		*dst = t.UTC()
+	}
+	return f, true
+}
+
+func csvRecord(ev *Event) []string {
+	changes := ""
+	if len(ev.Changes) > 0 {
+		data, _ := json.Marshal(ev.Changes)
+		changes = string(data)
+	}
+	record := []string{
+		ev.ID, ev.CreatedAt.UTC().Format(time.RFC3339Nano), ev.TenantID, ev.ActorID, ev.ActorEmail, ev.Action,
+		ev.TargetType, ev.TargetID, ev.IP, ev.UserAgent, ev.RequestID, changes,
+	}
+	for i, v := range record {
+		record[i] = csvSafe(v)
+	}
+	return record
+}
+
+// csvSafe stops spreadsheet programs from running a value as a formula.
+func csvSafe(v string) string {
+	if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+		return "'" + v
+	}
+	return v
+}
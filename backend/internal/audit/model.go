@@ -0,0 +1,149 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Actions recorded in the audit log.
+const (
+	ActionLogin                  = "auth.login"
+	ActionLoginFailed            = "auth.login_failed"
+	ActionRegister               = "auth.register"
+	ActionLogout                 = "auth.logout"
+	ActionPasswordResetRequested = "auth.password_reset_requested"
+	ActionPasswordReset          = "auth.password_reset"
+
+	ActionTenantCreated     = "tenant.created"
+	ActionTenantUpdated     = "tenant.updated"
+	ActionTenantActivated   = "tenant.activated"
+	ActionTenantDeactivated = "tenant.deactivated"
+	ActionTenantDeleted     = "tenant.deleted"
+
+	ActionProfileUpdated  = "user.profile_updated"
+	ActionPasswordChanged = "user.password_changed"
+
+	ActionTicketCreated          = "ticket.created"
+	ActionTicketUpdated          = "ticket.updated"
+	ActionTicketCommented        = "ticket.commented"
+	ActionTicketSolutionAccepted = "ticket.solution_accepted"
+	ActionTicketSolutionRejected = "ticket.solution_rejected"
+)
+
+// Target types.
+const (
+	TargetTenant = "tenant"
+	TargetUser   = "user"
+	TargetTicket = "ticket"
+)
+
+// Event is one entry of the append-only audit log.
+type Event struct {
+	ID       string `gorm:"type:char(36);primaryKey" json:"id"`
+	TenantID string `gorm:"type:char(36);not null;default:'';index:idx_audit_events_tenant_created,priority:1" json:"tenant_id"`
+	// ActorID and ActorEmail identify the user who acted; both are empty
+	// for anonymous requests such as a password reset link.
+	ActorID    string    `gorm:"type:char(36);not null;default:''" json:"actor_id,omitempty"`
+	ActorEmail string    `gorm:"size:255;not null;default:'';index" json:"actor_email,omitempty"`
+	Action     string    `gorm:"size:64;not null;index" json:"action"`
+	TargetType string    `gorm:"size:32;not null;default:'';index:idx_audit_events_target,priority:1" json:"target_type,omitempty"`
+	TargetID   string    `gorm:"size:64;not null;default:'';index:idx_audit_events_target,priority:2" json:"target_id,omitempty"`
+	IP         string    `gorm:"size:45;not null;default:''" json:"ip,omitempty"`
+	UserAgent  string    `gorm:"size:512;not null;default:''" json:"user_agent,omitempty"`
+	RequestID  string    `gorm:"size:64;not null;default:'';index" json:"request_id,omitempty"`
+	Changes    Changes   `gorm:"type:json;serializer:json" json:"changes,omitempty"`
+	CreatedAt  time.Time `gorm:"index:idx_audit_events_tenant_created,priority:2;index" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Event) TableName() string {
+	return "audit_events"
+}
+
+// Change is the value of one field before and after an action.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Changes maps field names to their change.
+type Changes map[string]Change
+
+// redacted stands in for secrets in Changes.
+const redacted = "[redacted]"
+
+// Diff returns the fields whose JSON values differ between before and
+// after, which are structs of the same type or nil for a created or deleted
+// target. Fields hidden from JSON are not compared; see Secret. Row
+// timestamps are left out.
+func Diff(before, after any) Changes {
+	b, a := jsonFields(before), jsonFields(after)
+	for _, fields := range []map[string]any{b, a} {
+		delete(fields, "created_at")
+		delete(fields, "updated_at")
+	}
+	changes := Changes{}
+	for name, value := range b {
+		if !reflect.DeepEqual(value, a[name]) {
+			changes[name] = Change{Before: value, After: a[name]}
+		}
+	}
+	for name, value := range a {
+		if _, ok := b[name]; !ok && value != nil {
+			changes[name] = Change{Before: nil, After: value}
+		}
+	}
+	return changes
+}
+
+// Secret records that a secret field was set without its values.
+func (c Changes) Secret(name string) Changes {
+	c[name] = Change{Before: redacted, After: redacted}
+	return c
+}
+
+// Fields returns the changed field names in order.
+func (c Changes) Fields() []string {
+	names := make([]string, 0, len(c))
+	for name := range c {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func jsonFields(v any) map[string]any {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+// Filter selects audit events. Zero fields match everything.
+type Filter struct {
+	TenantID   string
+	ActorEmail string
+	Action     string
+	TargetType string
+	TargetID   string
+	RequestID  string
+	From       time.Time // inclusive
+	To         time.Time // exclusive
+}
+
+// EventPage is one page of audit events, newest first. NextCursor is empty
+// on the last page.
+type EventPage struct {
+	Data       []*Event `json:"data"`
+	NextCursor string   `json:"next_cursor,omitempty"`
+}
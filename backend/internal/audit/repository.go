@@ -0,0 +1,101 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// Repository stores audit events. There is deliberately no way to change
+// or delete an event.
+type Repository interface {
+	Create(ctx context.Context, ev *Event) error
+	// List returns up to limit events matching f, newest first, starting
+	// after the cursor position when after is not nil.
+	List(ctx context.Context, f Filter, after *Cursor, limit int) ([]*Event, error)
+}
+
+// appendOnlyTriggers make the database itself refuse changes to logged
+// events, also from clients that bypass the Repository. They match
+// migrations/019_create_audit_events.sql.
+var appendOnlyTriggers = []struct{ name, event string }{
+	{"audit_events_no_update", "UPDATE"},
+	{"audit_events_no_delete", "DELETE"},
+}
+
+// EnsureAppendOnly creates the triggers that reject updates and deletes of
+// audit events when they are missing. AutoMigrate creates the table but
+// not its triggers, so this runs at startup after it.
+func EnsureAppendOnly(ctx context.Context, db *gorm.DB) error {
+	var existing []string
+	err := db.WithContext(ctx).Raw(
+		"SELECT trigger_name FROM information_schema.triggers WHERE trigger_schema = DATABASE() AND event_object_table = ?",
+		Event{}.TableName(),
+	).Scan(&existing).Error
+	if err != nil {
+		return err
+	}
+
+	for _, trigger := range appendOnlyTriggers {
+		if slices.Contains(existing, trigger.name) {
+			continue
+		}
+		stmt := fmt.Sprintf("CREATE TRIGGER %s BEFORE %s ON %s FOR EACH ROW "+
+			"SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = '%s is append-only'",
+			trigger.name, trigger.event, Event{}.TableName(), Event{}.TableName())
+		if err := db.WithContext(ctx).Exec(stmt).Error; err != nil {
+			return fmt.Errorf("create trigger %s: %w", trigger.name, err)
+		}
+	}
+	return nil
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new audit repository
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) Create(ctx context.Context, ev *Event) error {
+	return r.db.WithContext(ctx).Create(ev).Error
+}
+
+func (r *gormRepository) List(ctx context.Context, f Filter, after *Cursor, limit int) ([]*Event, error) {
+	query := r.db.WithContext(ctx)
+	if f.TenantID != "" {
+		query = query.Where("tenant_id = ?", f.TenantID)
+	}
+	if f.ActorEmail != "" {
+		query = query.Where("actor_email = ?", f.ActorEmail)
+	}
+	if f.Action != "" {
+		query = query.Where("action = ?", f.Action)
+	}
+	if f.TargetType != "" {
+		query = query.Where("target_type = ?", f.TargetType)
+	}
+	if f.TargetID != "" {
+		query = query.Where("target_id = ?", f.TargetID)
+	}
+	if f.RequestID != "" {
+		query = query.Where("request_id = ?", f.RequestID)
+	}
+	if !f.From.IsZero() {
+		query = query.Where("created_at >= ?", f.From)
+	}
+	if !f.To.IsZero() {
+		query = query.Where("created_at < ?", f.To)
+	}
+	if after != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", after.CreatedAt, after.CreatedAt, after.ID)
+	}
+
+	var events []*Event
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,159 @@
+package audit
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/errors"
+)
+
+// Recorder appends events to the audit log.
+type Recorder interface {
+	// Record fills in the event's ID, time and the request details from
+	// ctx (see WithRequest), using the request's actor when ActorEmail is
+	// empty. The audited action already happened, so a failure is only
+	// logged.
+	Record(ctx context.Context, ev *Event)
+}
+
+// Service records and queries the audit log.
+type Service interface {
+	Recorder
+	// List returns a page of matching events, newest first. cursor is the
+	// NextCursor of the previous page, or empty for the first page.
+	List(ctx context.Context, f Filter, cursor string, limit int) (*EventPage, error)
+	// Export calls fn for each matching event, newest first, stopping after
+	// constants.AuditExportMaxRows events.
+	Export(ctx context.Context, f Filter, fn func(*Event) error) error
+}
+
+// Cursor is the position of the last event on a page.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type service struct {
+	repo   Repository
+	logger *logrus.Logger
+	now    func() time.Time
+}
+
+// NewService creates a new audit service
+func NewService(repo Repository, logger *logrus.Logger) Service {
+	return &service{repo: repo, logger: logger, now: time.Now}
+}
+
+func (s *service) Record(ctx context.Context, ev *Event) {
+	req := RequestFrom(ctx)
+	ev.ID = uuid.New().String()
+	// Stored with millisecond precision, which the cursor relies on.
+	ev.CreatedAt = s.now().UTC().Truncate(time.Millisecond)
+	ev.IP = req.IP
+	ev.UserAgent = truncate(req.UserAgent, 512)
+	ev.RequestID = req.RequestID
+	if ev.ActorEmail == "" {
+		ev.ActorEmail = req.ActorEmail
+	}
+
+	// Keep the record even if the client went away after the action.
+	if err := s.repo.Create(context.WithoutCancel(ctx), ev); err != nil {
+		s.logger.WithError(err).WithFields(logrus.Fields{
+			"action":     ev.Action,
+			"tenantID":   ev.TenantID,
+			"actorEmail": ev.ActorEmail,
+			"targetID":   ev.TargetID,
+			"requestID":  ev.RequestID,
+		}).Error("failed to record audit event")
+	}
+}
+
+func (s *service) List(ctx context.Context, f Filter, cursor string, limit int) (*EventPage, error) {
+	var after *Cursor
+	if cursor != "" {
+		var err error
+		if after, err = decodeCursor(cursor); err != nil {
+			return nil, errors.NewAppError(errors.ErrCodeInvalidInput, "invalid cursor", err)
+		}
+	}
+	if limit <= 0 {
+		limit = constants.AuditPageSize
+	}
+	limit = min(limit, constants.AuditMaxPageSize)
+
+	// Fetch one extra row to learn whether there is a next page.
+	events, err := s.repo.List(ctx, f, after, limit+1)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInternal, "failed to load audit events", err)
+	}
+
+	page := &EventPage{Data: events}
+	if len(events) > limit {
+		page.Data = events[:limit]
+		page.NextCursor = encodeCursor(page.Data[limit-1])
+	}
+	if page.Data == nil {
+		page.Data = []*Event{}
+	}
+	return page, nil
+}
+
+func (s *service) Export(ctx context.Context, f Filter, fn func(*Event) error) error {
+	var after *Cursor
+	for exported := 0; exported < constants.AuditExportMaxRows; {
+		limit := min(constants.AuditExportBatchSize, constants.AuditExportMaxRows-exported)
+		events, err := s.repo.List(ctx, f, after, limit)
+		if err != nil {
+			return fmt.Errorf("failed to load audit events: %w", err)
+		}
+		for _, ev := range events {
+			if err := fn(ev); err != nil {
+				return err
+			}
+		}
+		if len(events) < limit {
+			return nil
+		}
+		exported += len(events)
+		last := events[len(events)-1]
+		after = &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	return nil
+}
+
+// encodeCursor returns an opaque cursor for the position of ev.
+func encodeCursor(ev *Event) string {
+	raw := strconv.FormatInt(ev.CreatedAt.UnixMilli(), 10) + "_" + ev.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	millis, id, ok := strings.Cut(string(raw), "_")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &Cursor{CreatedAt: time.UnixMilli(ms).UTC(), ID: id}, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,145 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type memoryRepo struct {
+	Repository
+	events []*Event
+}
+
+func (r *memoryRepo) Create(ctx context.Context, ev *Event) error {
+	r.events = append(r.events, ev)
+	return nil
+}
+
+func (r *memoryRepo) List(ctx context.Context, f Filter, after *Cursor, limit int) ([]*Event, error) {
+	var found []*Event
+	for _, ev := range r.events {
+		if (f.TenantID != "" && ev.TenantID != f.TenantID) || (f.Action != "" && ev.Action != f.Action) {
+			continue
+		}
+		if after != nil && !(ev.CreatedAt.Before(after.CreatedAt) || (ev.CreatedAt.Equal(after.CreatedAt) && ev.ID < after.ID)) {
+			continue
+		}
+		found = append(found, ev)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if !found[i].CreatedAt.Equal(found[j].CreatedAt) {
+			return found[i].CreatedAt.After(found[j].CreatedAt)
+		}
+		return found[i].ID > found[j].ID
+	})
+	return found[:min(limit, len(found))], nil
+}
+
+func newTestService() (*service, *memoryRepo, *time.Time) {
+	repo := &memoryRepo{}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	svc := NewService(repo, logger).(*service)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, repo, &now
+}
+
+func TestRecordUsesRequestDetails(t *testing.T) {
+	svc, repo, _ := newTestService()
+	ctx := WithActor(WithRequest(context.Background(), Request{IP: "10.0.0.1", UserAgent: "curl/8", RequestID: "req-1"}), "admin@acme.co.id")
+
+	svc.Record(ctx, &Event{Action: ActionTenantUpdated, TargetType: TargetTenant, TargetID: "t1"})
+	svc.Record(ctx, &Event{Action: ActionLogin, ActorEmail: "budi@acme.co.id"})
+
+	first, second := repo.events[0], repo.events[1]
+	if first.ID == "" || first.IP != "10.0.0.1" || first.UserAgent != "curl/8" || first.RequestID != "req-1" || first.ActorEmail != "admin@acme.co.id" {
+		t.Errorf("event = %+v", first)
+	}
+	if second.ActorEmail != "budi@acme.co.id" {
+		t.Errorf("actor = %q, want the event's own actor", second.ActorEmail)
+	}
+}
+
+func TestDiffSkipsUnchangedAndHiddenFields(t *testing.T) {
+	type tenant struct {
+		Name      string    `json:"name"`
+		Color     string    `json:"color"`
+		Password  string    `json:"-"`
+		UpdatedAt time.Time `json:"updated_at"`
+	}
+	before := tenant{Name: "Acme", Color: "#fff", Password: "a", UpdatedAt: time.Now()}
+	after := tenant{Name: "Acme Corp", Color: "#fff", Password: "b"}
+
+	changes := Diff(&before, &after)
+	if len(changes) != 1 || changes["name"].Before != "Acme" || changes["name"].After != "Acme Corp" {
+		t.Errorf("changes = %+v", changes)
+	}
+	if created := Diff(nil, &after); len(created) != 2 || created["color"].Before != nil {
+		t.Errorf("created = %+v", created)
+	}
+}
+
+func TestListPagesNewestFirst(t *testing.T) {
+	svc, _, now := newTestService()
+	ctx := context.Background()
+	for i := 0; i < 5; i++ {
+		*now = now.Add(time.Second)
+		svc.Record(ctx, &Event{Action: ActionTicketCreated, TargetID: string(rune('a' + i))})
+	}
+
+	var targets string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not end")
+		}
+		page, err := svc.List(ctx, Filter{}, cursor, 2)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		for _, ev := range page.Data {
+			targets += ev.TargetID
+		}
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if targets != "edcba" {
+		t.Errorf("listed %q, want edcba", targets)
+	}
+}
+
+func TestExportCSVNeutralizesFormulas(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	svc, _, _ := newTestService()
+	svc.Record(context.Background(), &Event{TenantID: "t1", Action: ActionTicketCommented, ActorEmail: "=HYPERLINK(\"x\")"})
+	svc.Record(context.Background(), &Event{TenantID: "t2", Action: ActionTicketCommented, ActorEmail: "other@globex.com"})
+
+	router := gin.New()
+	callerTenant := func(*gin.Context) string { return "t1" }
+	router.GET("/export", NewHandler(svc, callerTenant, svc.logger).Export)
+	w := httptest.NewRecorder()
+	// The tenant_id query value cannot widen the export beyond the caller's tenant.
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export?format=csv&tenant_id=t2", nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv; charset=utf-8" {
+		t.Fatalf("status %d, headers %v", w.Code, w.Header())
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("csv: %v", err)
+	}
+	if len(records) != 2 || records[1][4] != "'=HYPERLINK(\"x\")" || records[1][5] != ActionTicketCommented {
+		t.Errorf("records = %q", records)
+	}
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/audit/audittest"
+	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/invgate/invgatetest"
+	"werk-ticketing/internal/tenant"
+	"werk-ticketing/internal/user/usertest"
+)
+
+// memoryTenantRepo implements the tenant.Repository methods used by Register.
+type memoryTenantRepo struct {
+	tenant.Repository
+	tenants map[string]*tenant.Tenant
+}
+
+func (r *memoryTenantRepo) FindByID(ctx context.Context, id string) (*tenant.Tenant, error) {
+	return r.tenants[id], nil
+}
+
+// registerFixture is an auth service for one tenant, registering users in
+// memory and in a fake InvGate.
+type registerFixture struct {
+	service Service
+	users   *usertest.Repository
+	audit   *audittest.Recorder
+	fake    *invgatetest.Server
+	tenant  *tenant.Tenant
+}
+
+func newRegisterFixture(t *testing.T) *registerFixture {
+	t.Helper()
+
+	fake := invgatetest.NewServer()
+	t.Cleanup(fake.Close)
+
+	tn := &tenant.Tenant{
+		ID:                uuid.NewString(),
+		Slug:              "acme",
+		InvGateCompanyID:  135,
+		InvGateGroupID:    134,
+		InvGateLocationID: 136,
+	}
+	users := usertest.NewRepository()
+	auditLog := &audittest.Recorder{}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	svc := NewService(
+		users,
+		&memoryTenantRepo{tenants: map[string]*tenant.Tenant{tn.ID: tn}},
+		invgate.NewService(fake.Config()),
+		"test-secret",
+		logger,
+		nil,
+		"http://localhost",
+		auditLog,
+	)
+	return &registerFixture{service: svc, users: users, audit: auditLog, fake: fake, tenant: tn}
+}
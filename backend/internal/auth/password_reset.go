@@ -7,6 +7,7 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/errors"
 	"werk-ticketing/internal/user"
 )
@@ -49,13 +50,25 @@ func (s *service) RequestPasswordReset(ctx context.Context, tenantID, email stri
 	// Build reset link
 	resetLink := fmt.Sprintf("%s/reset-password?token=%s", s.frontendURL, token)
 
-	// Send email
-	if err := s.emailClient.SendPasswordResetEmail(u.Email, resetLink); err != nil {
+	// Send email with the tenant's own sender and branding
+	t, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		s.logger.WithError(err).Warn("failed to load tenant for password reset email, using defaults")
+		t = nil
+	}
+	if err := s.emailClient.SendPasswordResetEmail(ctx, t, u.Email, resetLink); err != nil {
 		s.logger.WithError(err).Error("failed to send password reset email")
 		return errors.NewAppError(errors.ErrCodeInternal, "failed to send password reset email", err)
 	}
 
 	s.logger.Infof("password reset email sent to: %s", u.Email)
+	s.record(ctx, &audit.Event{
+		TenantID:   tenantID,
+		ActorEmail: u.Email,
+		Action:     audit.ActionPasswordResetRequested,
+		TargetType: audit.TargetUser,
+		TargetID:   u.ID,
+	})
 	return nil
 }
 
@@ -99,5 +112,13 @@ func (s *service) ResetPassword(ctx context.Context, token, newPassword string)
 	}
 
 	s.logger.Infof("password reset successful for user: %s", resetToken.UserID)
+	s.record(ctx, &audit.Event{
+		TenantID:   resetToken.TenantID,
+		ActorID:    resetToken.UserID,
+		Action:     audit.ActionPasswordReset,
+		TargetType: audit.TargetUser,
+		TargetID:   resetToken.UserID,
+		Changes:    audit.Changes{}.Secret("password"),
+	})
 	return nil
 }
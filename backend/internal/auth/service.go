@@ -6,6 +6,7 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/sirupsen/logrus"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/invgate"
 	"werk-ticketing/internal/tenant"
 	"werk-ticketing/internal/user"
@@ -34,9 +35,10 @@ type service struct {
 	logger        *logrus.Logger
 	emailClient   EmailClient
 	frontendURL   string
+	audit         audit.Recorder
 }
 
-// NewService instantiates auth service.
+// NewService instantiates auth service. auditLog may be nil.
 func NewService(
 	userRepo user.Repository,
 	tenantRepo tenant.Repository,
@@ -45,6 +47,7 @@ func NewService(
 	logger *logrus.Logger,
 	emailClient EmailClient,
 	frontendURL string,
+	auditLog audit.Recorder,
 ) Service {
 	return &service{
 		userRepo:      userRepo,
@@ -55,10 +58,20 @@ func NewService(
 		logger:        logger,
 		emailClient:   emailClient,
 		frontendURL:   frontendURL,
+		audit:         auditLog,
+	}
+}
+
+// record appends an authentication event to the audit log.
+func (s *service) record(ctx context.Context, ev *audit.Event) {
+	if s.audit != nil {
+		s.audit.Record(ctx, ev)
 	}
 }
 
 // EmailClient interface for sending emails
 type EmailClient interface {
-	SendPasswordResetEmail(to, resetLink string) error
+	// SendPasswordResetEmail sends with the tenant's email settings and
+	// branding; a nil tenant uses the global defaults.
+	SendPasswordResetEmail(ctx context.Context, t *tenant.Tenant, to, resetLink string) error
 }
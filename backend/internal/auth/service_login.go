@@ -5,6 +5,7 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/errors"
 	"werk-ticketing/internal/validator"
 )
@@ -37,6 +38,7 @@ func (s *service) Login(ctx context.Context, tenantID string, req LoginRequest)
 	}
 	if existing == nil {
 		s.logger.Warn("login attempt with non-existent email")
+		s.record(ctx, &audit.Event{TenantID: tenantID, ActorEmail: req.Email, Action: audit.ActionLoginFailed})
 		return nil, errors.NewAppError(
 			errors.ErrCodeInvalidCredentials,
 			"email or password invalid",
@@ -46,6 +48,14 @@ func (s *service) Login(ctx context.Context, tenantID string, req LoginRequest)
 
 	if err := bcrypt.CompareHashAndPassword([]byte(existing.Password), []byte(req.Password)); err != nil {
 		s.logger.Warn("login attempt with invalid password")
+		s.record(ctx, &audit.Event{
+			TenantID:   tenantID,
+			ActorID:    existing.ID,
+			ActorEmail: existing.Email,
+			Action:     audit.ActionLoginFailed,
+			TargetType: audit.TargetUser,
+			TargetID:   existing.ID,
+		})
 		return nil, errors.NewAppError(
 			errors.ErrCodeInvalidCredentials,
 			"email or password invalid",
@@ -74,6 +84,14 @@ func (s *service) Login(ctx context.Context, tenantID string, req LoginRequest)
 	}
 
 	s.logger.Info("user logged in successfully")
+	s.record(ctx, &audit.Event{
+		TenantID:   tenantID,
+		ActorID:    existing.ID,
+		ActorEmail: existing.Email,
+		Action:     audit.ActionLogin,
+		TargetType: audit.TargetUser,
+		TargetID:   existing.ID,
+	})
 
 	return &AuthResponse{
 		Token:        token,
@@ -8,6 +8,7 @@ import (
 
 	"golang.org/x/crypto/bcrypt"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/errors"
 	"werk-ticketing/internal/invgate"
 	"werk-ticketing/internal/tenant"
@@ -102,20 +103,16 @@ func (s *service) Register(ctx context.Context, tenantID string, req RegisterReq
 	})
 	if err != nil {
 		s.logger.WithError(err).Error("failed to create user in InvGate")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to create user in external service",
-			err,
-		)
+		return nil, invgate.AsAppError(err, "failed to create user in external service")
 	}
 
-	invGateUserID, err := extractInvGateUserID(response)
-	if err != nil {
-		s.logger.WithError(err).WithField("response", response).Error("failed to extract InvGate user ID")
+	invGateUserID := response.ID
+	if invGateUserID == 0 {
+		s.logger.WithField("status", response.Status).Error("InvGate response did not include a user ID")
 		return nil, errors.NewAppError(
 			errors.ErrCodeExternalService,
 			"failed to process external user response",
-			err,
+			fmt.Errorf("user ID not found in InvGate response"),
 		)
 	}
 
@@ -126,6 +123,7 @@ func (s *service) Register(ctx context.Context, tenantID string, req RegisterReq
 		Email:         req.Email,
 		Password:      string(hashed),
 		InvGateUserID: invGateUserID,
+		Role:          user.RoleUser,
 		CreatedBy:     req.Email,
 		UpdatedBy:     req.Email,
 	}
@@ -223,6 +221,20 @@ func (s *service) Register(ctx context.Context, tenantID string, req RegisterReq
 	}
 
 	s.logger.Info("user registered successfully")
+	s.record(ctx, &audit.Event{
+		TenantID:   tenantID,
+		ActorID:    newUser.ID,
+		ActorEmail: newUser.Email,
+		Action:     audit.ActionRegister,
+		TargetType: audit.TargetUser,
+		TargetID:   newUser.ID,
+		Changes: audit.Changes{
+			"email":           {After: newUser.Email},
+			"name":            {After: newUser.Name},
+			"lastname":        {After: newUser.LastName},
+			"invgate_user_id": {After: newUser.InvGateUserID},
+		},
+	})
 
 	return &AuthResponse{
 		Token:        token,
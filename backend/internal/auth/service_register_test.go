@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"werk-ticketing/internal/audit"
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/invgate/invgatetest"
+)
+
+func validRegisterRequest() RegisterRequest {
+	return RegisterRequest{
+		Name:     "Ada",
+		LastName: "Lovelace",
+		Email:    "ada@example.com",
+		Password: "Secret123",
+	}
+}
+
+func TestRegisterCreatesAndAssignsInvGateUser(t *testing.T) {
+	f := newRegisterFixture(t)
+	ctx := context.Background()
+
+	resp, err := f.service.Register(ctx, f.tenant.ID, validRegisterRequest())
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if resp.Token == "" || resp.RefreshToken == "" {
+		t.Errorf("Register returned no tokens: %+v", resp)
+	}
+
+	local, _ := f.users.GetByEmail(ctx, f.tenant.ID, "ada@example.com")
+	if local == nil || local.InvGateUserID == 0 {
+		t.Fatalf("local user = %+v, want linked InvGate user", local)
+	}
+	remote, ok := f.fake.User(local.InvGateUserID)
+	if !ok || remote.Email != "ada@example.com" || remote.Pass != "Secret123" {
+		t.Errorf("InvGate user = %+v, %v", remote, ok)
+	}
+	if local.Password == "Secret123" {
+		t.Error("local password stored in plain text")
+	}
+	if events := f.audit.Events(); len(events) != 1 || events[0].Action != audit.ActionRegister || events[0].TargetID != local.ID {
+		t.Errorf("audit events = %+v, want one registration", events)
+	} else if _, ok := events[0].Changes["password"]; ok {
+		t.Error("password recorded in the audit log")
+	}
+
+	for endpoint, entityID := range map[string]int{
+		"companies.users": f.tenant.InvGateCompanyID,
+		"groups.users":    f.tenant.InvGateGroupID,
+		"locations.users": f.tenant.InvGateLocationID,
+	} {
+		if got := f.fake.Assigned(endpoint, entityID); len(got) != 1 || got[0] != local.InvGateUserID {
+			t.Errorf("%s = %v, want [%d]", endpoint, got, local.InvGateUserID)
+		}
+	}
+}
+
+func TestRegisterRejectsEmailKnownToInvGate(t *testing.T) {
+	f := newRegisterFixture(t)
+	f.fake.AddUser(invgatetest.User{Email: "ada@example.com"})
+
+	_, err := f.service.Register(context.Background(), f.tenant.ID, validRegisterRequest())
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != errors.ErrCodeEmailAlreadyExist {
+		t.Fatalf("Register error = %v, want EMAIL_ALREADY_EXIST", err)
+	}
+	if calls := f.fake.Calls(http.MethodPost, "user"); calls != 0 {
+		t.Errorf("InvGate user created %d times, want 0", calls)
+	}
+}
+
+func TestRegisterCompensatesWhenAssignmentFails(t *testing.T) {
+	f := newRegisterFixture(t)
+	ctx := context.Background()
+	f.fake.Fail(invgatetest.Failure{Endpoint: "groups.users", Status: http.StatusBadRequest})
+
+	_, err := f.service.Register(ctx, f.tenant.ID, validRegisterRequest())
+	if err == nil {
+		t.Fatal("Register succeeded, want error")
+	}
+
+	if local, _ := f.users.GetByEmail(ctx, f.tenant.ID, "ada@example.com"); local != nil {
+		t.Errorf("local user not rolled back: %+v", local)
+	}
+	if calls := f.fake.Calls(http.MethodDelete, "user"); calls != 1 {
+		t.Errorf("InvGate user deleted %d times, want 1", calls)
+	}
+	if events := f.audit.Events(); len(events) != 0 {
+		t.Errorf("rolled back registration audited: %+v", events)
+	}
+}
+
+func TestRegisterMapsInvGateValidationErrors(t *testing.T) {
+	f := newRegisterFixture(t)
+	f.fake.Fail(invgatetest.Failure{
+		Method:   http.MethodPost,
+		Endpoint: "user",
+		Status:   http.StatusUnprocessableEntity,
+		Body:     `{"error":"name is too long"}`,
+	})
+
+	_, err := f.service.Register(context.Background(), f.tenant.ID, validRegisterRequest())
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != errors.ErrCodeInvalidInput {
+		t.Fatalf("Register error = %v, want INVALID_INPUT", err)
+	}
+}
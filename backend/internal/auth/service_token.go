@@ -6,6 +6,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/constants"
 	"werk-ticketing/internal/errors"
 	"werk-ticketing/internal/user"
@@ -110,6 +111,7 @@ func (s *service) RevokeToken(ctx context.Context, token string) error {
 	}
 
 	s.logger.Info("token revoked")
+	s.record(ctx, &audit.Event{ActorEmail: claims.Subject, Action: audit.ActionLogout})
 	return nil
 }
 
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
@@ -8,6 +11,10 @@ import (
 	"github.com/joho/godotenv"
 )
 
+// storageSigningKeyLabel separates the derived URL signing key from the JWT
+// secret it is derived from.
+const storageSigningKeyLabel = "werk-ticketing storage url signing v1"
+
 // Config holds all runtime configuration for the backend service.
 type Config struct {
 	// Application
@@ -40,14 +47,60 @@ type Config struct {
 	LogLevel  string
 	GinMode   string
 	LogFormat string
+	// Fraction of successful, fast requests written to the access log (0..1)
+	AccessLogSampleRate float64
+	// Bearer token required on /metrics; empty leaves it open
+	MetricsToken string
+
+	// OpenTelemetry tracing, exported over OTLP/HTTP
+	TracingEnabled     bool
+	TracingServiceName string
+	OTLPEndpoint       string  // e.g. http://localhost:4318
+	TracingSampleRatio float64 // share of new traces recorded (0..1)
+
+	// Probe InvGate, globally and per tenant, in the readiness check
+	ReadinessInvGateProbe bool
+
+	// Email transport: "mailgun" (default), "smtp" or "outbox"
+	EmailDriver    string
+	EmailOutboxDir string
 
 	// Mailgun Email
-	MailgunDomain string
-	MailgunAPIKey string
-	MailgunSender string
+	MailgunDomain  string
+	MailgunAPIKey  string
+	MailgunSender  string
+	MailgunBaseURL string // e.g. https://api.eu.mailgun.net/v3 for the EU region
+
+	// SMTP relay
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPTLS      string // "starttls", "tls" or "none"
 
 	// Frontend
 	FrontendURL string
+	// PublicBaseURL is the externally reachable backend URL, used for
+	// links to uploaded files in emails
+	PublicBaseURL string
+
+	// TicketSyncEnabled polls InvGate for ticket changes to email requesters
+	TicketSyncEnabled bool
+
+	// Malware scanning; empty disables it
+	ClamdAddress string
+
+	// File storage
+	StorageDriver     string // "local" or "s3"
+	StorageLocalDir   string
+	StoragePublicPath string // route serving local signed URLs
+	StorageSigningKey string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKey       string
+	S3SecretKey       string
+	S3UseSSL          bool
 }
 
 // Load loads configuration from environment variables (optionally via .env files).
@@ -56,34 +109,80 @@ func Load() (*Config, error) {
 	_ = godotenv.Load("../.env") // best-effort when running from cmd/
 
 	cfg := &Config{
-		AppEnv:            getEnv("APP_ENV", "development"),
-		ServerPort:        getEnv("SERVER_PORT", "8080"),
-		DBUser:            getEnv("DB_USER", "root"),
-		DBPass:            getEnv("DB_PASSWORD", ""),
-		DBHost:            getEnv("DB_HOST", "db"),
-		DBPort:            getEnv("DB_PORT", "3306"),
-		DBName:            getEnv("DB_NAME", "armmada"),
-		JWTSecret:         getEnv("JWT_SECRET", ""),
-		ArmMadaBaseURL:    getEnv("ARMMADA_BASE_URL", ""),
-		ArmMadaUsername:   getEnv("ARMMADA_USERNAME", ""),
-		ArmMadaPassword:   getEnv("ARMMADA_PASSWORD", ""),
-		ArmMadaPageKey:    getEnv("ARMMADA_PAGE_KEY", ""),
-		ArmMadaCompanyID:  getEnvInt("ARMMADA_COMPANY_ID", 135),
-		ArmMadaGroupID:    getEnvInt("ARMMADA_GROUP_ID", 134),
-		ArmMadaLocationID: getEnvInt("ARMMADA_LOCATION_ID", 136),
-		LogLevel:          getEnv("LOG_LEVEL", "info"),
-		GinMode:           getEnv("GIN_MODE", "debug"),
-		LogFormat:         getEnv("LOG_FORMAT", "text"),
-		MailgunDomain:     getEnv("MAILGUN_DOMAIN", "mg.werk.co.id"),
-		MailgunAPIKey:     getEnv("MAILGUN_API_KEY", ""),
-		MailgunSender:     getEnv("MAILGUN_SENDER", "Werk <no-reply@mg.werk.co.id>"),
-		FrontendURL:       getEnv("FRONTEND_URL", "http://localhost:5173"),
+		AppEnv:                getEnv("APP_ENV", "development"),
+		ServerPort:            getEnv("SERVER_PORT", "8080"),
+		DBUser:                getEnv("DB_USER", "root"),
+		DBPass:                getEnv("DB_PASSWORD", ""),
+		DBHost:                getEnv("DB_HOST", "db"),
+		DBPort:                getEnv("DB_PORT", "3306"),
+		DBName:                getEnv("DB_NAME", "armmada"),
+		JWTSecret:             getEnv("JWT_SECRET", ""),
+		ArmMadaBaseURL:        getEnv("ARMMADA_BASE_URL", ""),
+		ArmMadaUsername:       getEnv("ARMMADA_USERNAME", ""),
+		ArmMadaPassword:       getEnv("ARMMADA_PASSWORD", ""),
+		ArmMadaPageKey:        getEnv("ARMMADA_PAGE_KEY", ""),
+		ArmMadaCompanyID:      getEnvInt("ARMMADA_COMPANY_ID", 135),
+		ArmMadaGroupID:        getEnvInt("ARMMADA_GROUP_ID", 134),
+		ArmMadaLocationID:     getEnvInt("ARMMADA_LOCATION_ID", 136),
+		LogLevel:              getEnv("LOG_LEVEL", "info"),
+		GinMode:               getEnv("GIN_MODE", "debug"),
+		LogFormat:             getEnv("LOG_FORMAT", "text"),
+		AccessLogSampleRate:   getEnvFloat("ACCESS_LOG_SAMPLE_RATE", 1),
+		MetricsToken:          getEnv("METRICS_TOKEN", ""),
+		TracingEnabled:        getEnv("TRACING_ENABLED", "false") == "true",
+		TracingServiceName:    getEnv("OTEL_SERVICE_NAME", "werk-ticketing-backend"),
+		OTLPEndpoint:          getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+		TracingSampleRatio:    getEnvFloat("TRACING_SAMPLE_RATIO", 1),
+		ReadinessInvGateProbe: getEnv("READINESS_INVGATE_PROBE", "false") == "true",
+		MailgunDomain:         getEnv("MAILGUN_DOMAIN", "mg.werk.co.id"),
+		MailgunAPIKey:         getEnv("MAILGUN_API_KEY", ""),
+		MailgunSender:         getEnv("MAILGUN_SENDER", "Werk <no-reply@mg.werk.co.id>"),
+		MailgunBaseURL:        getEnv("MAILGUN_BASE_URL", ""),
+		EmailDriver:           getEnv("EMAIL_DRIVER", "mailgun"),
+		EmailOutboxDir:        getEnv("EMAIL_OUTBOX_DIR", "./outbox"),
+		SMTPHost:              getEnv("SMTP_HOST", ""),
+		SMTPPort:              getEnvInt("SMTP_PORT", 0),
+		SMTPUsername:          getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:          getEnv("SMTP_PASSWORD", ""),
+		SMTPTLS:               getEnv("SMTP_TLS", "starttls"),
+		FrontendURL:           getEnv("FRONTEND_URL", "http://localhost:5173"),
+		PublicBaseURL:         getEnv("PUBLIC_BASE_URL", "http://localhost:8080"),
+		TicketSyncEnabled:     getEnv("TICKET_SYNC_ENABLED", "true") == "true",
+		ClamdAddress:          getEnv("CLAMD_ADDRESS", ""),
+		StorageDriver:         getEnv("STORAGE_DRIVER", "local"),
+		StorageLocalDir:       getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+		StoragePublicPath:     getEnv("STORAGE_PUBLIC_PATH", "/files"),
+		StorageSigningKey:     getEnv("STORAGE_SIGNING_KEY", ""),
+		S3Endpoint:            getEnv("S3_ENDPOINT", ""),
+		S3Region:              getEnv("S3_REGION", ""),
+		S3Bucket:              getEnv("S3_BUCKET", ""),
+		S3AccessKey:           getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:           getEnv("S3_SECRET_KEY", ""),
+		S3UseSSL:              getEnv("S3_USE_SSL", "true") == "true",
 	}
 
 	if cfg.JWTSecret == "" {
 		return nil, fmt.Errorf("JWT_SECRET must be provided")
 	}
 
+	// Local download URLs are signed with a key derived from the JWT secret
+	// unless a dedicated key is set, so a leaked URL key cannot sign tokens
+	if cfg.StorageSigningKey == "" {
+		key, err := hkdf.Key(sha256.New, []byte(cfg.JWTSecret), nil, storageSigningKeyLabel, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive storage signing key: %w", err)
+		}
+		cfg.StorageSigningKey = hex.EncodeToString(key)
+	}
+
+	if cfg.AccessLogSampleRate < 0 || cfg.AccessLogSampleRate > 1 {
+		return nil, fmt.Errorf("ACCESS_LOG_SAMPLE_RATE must be between 0 and 1")
+	}
+
+	if cfg.TracingSampleRatio < 0 || cfg.TracingSampleRatio > 1 {
+		return nil, fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+
 	if cfg.ArmMadaBaseURL == "" || cfg.ArmMadaUsername == "" || cfg.ArmMadaPassword == "" {
 		return nil, fmt.Errorf("InvGate ARMMADA credentials must be provided")
 	}
@@ -112,3 +211,17 @@ func getEnvInt(key string, fallback int) int {
 
 	return parsed
 }
+
+func getEnvFloat(key string, fallback float64) float64 {
+	val := os.Getenv(key)
+	if val == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return fallback
+	}
+
+	return parsed
+}
@@ -11,6 +11,11 @@ const (
 // HTTP timeout
 const (
 	HTTPClientTimeoutSeconds = 15
+	// Attachment downloads are streamed, so instead of a total timeout they
+	// fail when no data flows for this long.
+	DownloadIdleTimeoutSeconds = 30
+	// Upper bound for a single malware scan, including the upload to clamd.
+	ScannerTimeoutSeconds = 60
 )
 
 // Database connection pool
@@ -24,7 +29,125 @@ const (
 // Server configuration
 const (
 	GracefulShutdownTimeout = 30 * time.Second
-	MaxRequestSize          = 10 << 20 // 10 MB
+	// Upload bodies may exceed their attachments' size by MaxRequestSize.
+	MaxRequestSize = 10 << 20 // 10 MB
+	// Multipart file parts above this size are spooled to temp files by
+	// net/http instead of being held in memory.
+	MultipartMemoryLimit = 1 << 20 // 1 MB
+	// Upload routes replace the server read/write timeouts: the upload fails
+	// when no data arrives for UploadIdleTimeout or the body is still being
+	// sent after UploadMaxDuration, and the response must be written within
+	// UploadForwardTimeout of the last byte.
+	UploadIdleTimeout    = 30 * time.Second
+	UploadMaxDuration    = 15 * time.Minute
+	UploadForwardTimeout = 5 * time.Minute
+)
+
+// Attachment policy defaults, used when a tenant does not override them.
+// They are also the upper limits; a tenant policy can only lower them.
+const (
+	AttachmentMaxFileSize = 10 << 20 // 10 MB per file
+	AttachmentMaxFiles    = 5
+	AttachmentSniffBytes  = 3072 // bytes read to detect the content type
+)
+
+// File storage
+const (
+	SignedURLTTL = 15 * time.Minute // lifetime of signed download URLs
+	// Logos no tenant references are deleted once older than the grace period.
+	OrphanUploadGracePeriod   = 24 * time.Hour
+	OrphanUploadSweepInterval = time.Hour
+	// Raster logos above this width or height are rejected before decoding.
+	LogoMaxDimension = 4096
+)
+
+// Outbound email queue
+const (
+	EmailQueuePollInterval = 5 * time.Second
+	EmailQueueBatchSize    = 20
+	EmailSendTimeout       = 30 * time.Second
+	// Claimed emails become available to other workers again after the lease.
+	EmailSendLease       = 2 * time.Minute
+	EmailMaxAttempts     = 8 // dead-lettered after this many failed attempts
+	EmailRetryBaseDelay  = 30 * time.Second
+	EmailRetryMaxDelay   = 2 * time.Hour
+	EmailOutboxListLimit = 100
+	// Sent and dead-lettered emails are deleted after EmailOutboxRetention.
+	EmailOutboxRetention     = 30 * 24 * time.Hour
+	EmailOutboxPruneInterval = time.Hour
+)
+
+// Ticket update notifications
+const (
+	InvGateTicketViewID = 7 // InvGate view listing the portal's tickets
+	TicketSyncInterval  = 2 * time.Minute
+	TicketSyncMaxPages  = 50 // bound on view pages fetched per sync
+	DigestCheckInterval = 5 * time.Minute
+	// Runes of an agent comment quoted in notification emails
+	NotificationCommentExcerpt = 500
+	// In-portal notification center
+	NotificationPageSize      = 20
+	NotificationMaxPageSize   = 100
+	NotificationPruneInterval = time.Hour
+)
+
+// Outbound tenant webhooks
+const (
+	WebhookPollInterval = 5 * time.Second
+	WebhookBatchSize    = 20
+	WebhookTimeout      = 10 * time.Second
+	// Claimed deliveries become available to other workers again after the lease.
+	WebhookLease             = time.Minute
+	WebhookMaxAttempts       = 10 // marked failed after this many attempts
+	WebhookRetryBaseDelay    = 30 * time.Second
+	WebhookRetryMaxDelay     = 6 * time.Hour
+	WebhookResponseBodyLimit = 2048 // bytes of the receiver's response kept in the log
+	WebhookDeliveryListLimit = 100
+)
+
+// Real-time ticket stream
+const (
+	StreamHeartbeatInterval = 15 * time.Second
+	StreamRetryMillis       = 5000 // reconnect delay suggested to clients
+	StreamHistorySize       = 1000 // recent events kept for Last-Event-ID resume
+	// Events buffered per client; a client that falls further behind is
+	// disconnected and resumes from its last event.
+	StreamClientBuffer = 64
+)
+
+// Inbound InvGate webhooks
+const (
+	InvGateWebhookMaxBody = 1 << 20 // 1MB
+	// Signed webhooks whose timestamp is further off than this are rejected
+	InvGateWebhookMaxSkew = 5 * time.Minute
+	// Delivery IDs are kept this long to drop redelivered webhooks
+	InvGateWebhookDedupeWindow   = 7 * 24 * time.Hour
+	InvGateWebhookPruneInterval  = time.Hour
+	InvGateWebhookProcessTimeout = 20 * time.Second
+)
+
+// Audit log
+const (
+	AuditPageSize        = 50
+	AuditMaxPageSize     = 200
+	AuditExportBatchSize = 500
+	AuditExportMaxRows   = 100000 // narrow the filter to export more
+)
+
+// Request logging
+const (
+	// Requests slower than this are always logged, whatever the sample rate.
+	AccessLogSlowRequest = 2 * time.Second
+	RequestIDMaxLength   = 128 // longer incoming X-Request-ID values are replaced
+)
+
+// Health checks
+const (
+	HealthCheckTimeout  = 2 * time.Second
+	HealthCheckCacheTTL = 10 * time.Second // results reused by later probes
+	// InvGate is probed less often to avoid adding load on its API.
+	InvGateProbeTimeout  = 5 * time.Second
+	InvGateProbeCacheTTL = time.Minute
 )
 
 // Rate limiting
@@ -43,4 +166,6 @@ const (
 	RetryInitialDelayMs    = 500  // 500ms
 	RetryMaxDelayMs        = 5000 // 5 seconds
 	RetryBackoffMultiplier = 2.0
+	RetryJitterFraction    = 0.5   // up to 50% of the delay is randomized
+	RetryAfterMaxMs        = 30000 // give up instead of honoring Retry-After above 30 seconds
 )
@@ -0,0 +1,197 @@
+package email
+
+import (
+	stdErrors "errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/response"
+	"werk-ticketing/internal/tenant"
+)
+
+// Handler handles HTTP requests for email template management
+type Handler struct {
+	mailer    *Mailer
+	registry  *Registry
+	templates TemplateRepository
+	queue     QueueRepository
+}
+
+// NewHandler creates a new email template handler
+func NewHandler(mailer *Mailer, registry *Registry, templates TemplateRepository, queue QueueRepository) *Handler {
+	return &Handler{mailer: mailer, registry: registry, templates: templates, queue: queue}
+}
+
+// ListTemplates handles GET /admin/email-templates
+func (h *Handler) ListTemplates(c *gin.Context) {
+	response.Success(c, http.StatusOK, h.registry.Templates())
+}
+
+// Preview handles POST /admin/email-templates/:name/preview. The template
+// is rendered with the branding and overrides of the caller's tenant.
+func (h *Handler) Preview(c *gin.Context) {
+	var req PreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid request body")
+		return
+	}
+
+	t, ok := h.loadTenant(c)
+	if !ok {
+		return
+	}
+
+	var draft *TemplateOverride
+	if req.Draft != nil {
+		draft = &TemplateOverride{Subject: req.Draft.Subject, HTML: req.Draft.HTML, Text: req.Draft.Text}
+	}
+
+	rendered, err := h.mailer.Preview(c.Request.Context(), t, c.Param("name"), req.Locale, draft)
+	if err != nil {
+		h.renderError(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, rendered)
+}
+
+// ListOverrides handles GET /admin/tenants/:id/email-templates
+func (h *Handler) ListOverrides(c *gin.Context) {
+	t, ok := h.loadTenant(c)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.templates.ListByTenant(c.Request.Context(), t.ID)
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to list email templates")
+		return
+	}
+	response.Success(c, http.StatusOK, overrides)
+}
+
+// SaveOverride handles PUT /admin/tenants/:id/email-templates/:name/:locale
+func (h *Handler) SaveOverride(c *gin.Context) {
+	name, locale := c.Param("name"), c.Param("locale")
+	if NormalizeLocale(locale) != locale {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "unsupported locale")
+		return
+	}
+
+	var req TemplateOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid request body")
+		return
+	}
+	if req.Subject == "" && req.HTML == "" && req.Text == "" {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "at least one of subject, html or text is required")
+		return
+	}
+
+	t, ok := h.loadTenant(c)
+	if !ok {
+		return
+	}
+
+	override := &TemplateOverride{
+		ID:       uuid.New().String(),
+		TenantID: t.ID,
+		Name:     name,
+		Locale:   locale,
+		Subject:  req.Subject,
+		HTML:     req.HTML,
+		Text:     req.Text,
+	}
+	// Rendering with sample data catches syntax errors and unknown fields
+	// before the override can break real emails.
+	if _, err := h.mailer.Preview(c.Request.Context(), t, name, locale, override); err != nil {
+		h.renderError(c, err)
+		return
+	}
+
+	if err := h.templates.Save(c.Request.Context(), override); err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to save email template")
+		return
+	}
+	response.Success(c, http.StatusOK, override)
+}
+
+// DeleteOverride handles DELETE /admin/tenants/:id/email-templates/:name/:locale
+func (h *Handler) DeleteOverride(c *gin.Context) {
+	t, ok := h.loadTenant(c)
+	if !ok {
+		return
+	}
+
+	if err := h.templates.Delete(c.Request.Context(), t.ID, c.Param("name"), c.Param("locale")); err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to delete email template")
+		return
+	}
+	response.Success(c, http.StatusOK, gin.H{"message": "email template reset to default"})
+}
+
+// ListOutbox handles GET /admin/email-outbox?status=dead, listing the
+// caller's tenant's emails
+func (h *Handler) ListOutbox(c *gin.Context) {
+	status := c.DefaultQuery("status", StatusDead)
+	switch status {
+	case StatusPending, StatusSent, StatusDead:
+	default:
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "status must be pending, sent or dead")
+		return
+	}
+
+	emails, err := h.queue.ListByStatus(c.Request.Context(), middleware.GetTenantID(c), status, constants.EmailOutboxListLimit)
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to list queued emails")
+		return
+	}
+	response.Success(c, http.StatusOK, emails)
+}
+
+// RetryOutbox handles POST /admin/email-outbox/:id/retry
+func (h *Handler) RetryOutbox(c *gin.Context) {
+	queued, err := h.queue.FindByID(c.Request.Context(), middleware.GetTenantID(c), c.Param("id"))
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to get queued email")
+		return
+	}
+	if queued == nil {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "queued email not found")
+		return
+	}
+	if queued.Status != StatusDead {
+		response.ErrorWithCode(c, http.StatusConflict, errors.ErrCodeConflict, "only dead-lettered emails can be retried")
+		return
+	}
+
+	if err := h.queue.Requeue(c.Request.Context(), queued.ID, time.Now()); err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to requeue email")
+		return
+	}
+	response.Success(c, http.StatusOK, gin.H{"message": "email requeued"})
+}
+
+// loadTenant returns the caller's tenant. Routes with a tenant :id only
+// reach the caller's own tenant; any other ID is reported as not found.
+func (h *Handler) loadTenant(c *gin.Context) (*tenant.Tenant, bool) {
+	t := middleware.GetTenant(c)
+	if t == nil || (c.Param("id") != "" && c.Param("id") != t.ID) {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "tenant not found")
+		return nil, false
+	}
+	return t, true
+}
+
+func (h *Handler) renderError(c *gin.Context, err error) {
+	if stdErrors.Is(err, ErrUnknownTemplate) {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "email template not found")
+		return
+	}
+	response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "template error: "+err.Error())
+}
@@ -0,0 +1,262 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"werk-ticketing/internal/metrics"
+	"werk-ticketing/internal/tenant"
+	"werk-ticketing/internal/tracing"
+)
+
+// Settings identify the Mailgun account and sender used for a message.
+type Settings struct {
+	Domain string
+	APIKey string
+	Sender string
+}
+
+// Branding is the tenant look applied to email templates.
+type Branding struct {
+	Name         string
+	LogoURL      string // absolute URL, empty when the tenant has no logo
+	PrimaryColor string
+}
+
+// LogoResolver returns the URL of each standard logo size for a tenant logo.
+type LogoResolver interface {
+	LogoURLs(url string) map[string]string
+}
+
+// Mailer sends application emails with per-tenant settings and branding,
+// falling back to the global defaults for anything a tenant leaves unset.
+type Mailer struct {
+	sender          Sender
+	registry        *Registry
+	defaults        Settings
+	defaultBranding Branding
+	logos           LogoResolver
+	publicBaseURL   string
+	queue           QueueRepository
+}
+
+// NewMailer creates a Mailer. publicBaseURL is prefixed to relative logo
+// URLs, since email clients need absolute ones. With a queue, Send only
+// enqueues and a Worker delivers; with a nil queue it sends right away.
+func NewMailer(sender Sender, registry *Registry, defaults Settings, logos LogoResolver, publicBaseURL string, queue QueueRepository) *Mailer {
+	return &Mailer{
+		sender:   sender,
+		registry: registry,
+		defaults: defaults,
+		defaultBranding: Branding{
+			Name:         "Werk Ticketing",
+			PrimaryColor: "#6929C4",
+		},
+		logos:         logos,
+		publicBaseURL: strings.TrimRight(publicBaseURL, "/"),
+		queue:         queue,
+	}
+}
+
+// SettingsFor resolves the sending settings for a tenant. The tenant's
+// domain is only used together with its own API key, since the global
+// account cannot send for arbitrary domains. EmailSender may be a full
+// address or just a display name.
+func (m *Mailer) SettingsFor(t *tenant.Tenant) Settings {
+	s := m.defaults
+	if t == nil {
+		return s
+	}
+
+	// A full sender address is only honoured on the tenant's own account.
+	name := t.Name
+	if t.EmailSender != "" {
+		if _, err := mail.ParseAddress(t.EmailSender); err == nil {
+			if t.EmailAPIKey != "" {
+				s.APIKey, s.Sender = t.EmailAPIKey, t.EmailSender
+				if t.EmailDomain != "" {
+					s.Domain = t.EmailDomain
+				}
+				return s
+			}
+		} else {
+			name = t.EmailSender
+		}
+	}
+
+	if t.EmailAPIKey != "" {
+		s.APIKey = t.EmailAPIKey
+		if t.EmailDomain != "" {
+			s.Domain = t.EmailDomain
+		}
+		s.Sender = (&mail.Address{Name: name, Address: "no-reply@" + s.Domain}).String()
+		return s
+	}
+
+	if addr, err := mail.ParseAddress(m.defaults.Sender); err == nil && name != "" {
+		addr.Name = name
+		s.Sender = addr.String()
+	}
+	return s
+}
+
+// CheckConfig reports whether the default settings and the transport are
+// configured to send. Tenants' own accounts are not checked.
+func (m *Mailer) CheckConfig() error {
+	if _, err := mail.ParseAddress(m.defaults.Sender); err != nil {
+		return fmt.Errorf("invalid default sender %q: %w", m.defaults.Sender, err)
+	}
+	if checker, ok := m.sender.(ConfigChecker); ok {
+		return checker.CheckConfig(m.defaults)
+	}
+	return nil
+}
+
+// BrandingFor resolves the template branding for a tenant.
+func (m *Mailer) BrandingFor(t *tenant.Tenant) Branding {
+	b := m.defaultBranding
+	if t == nil {
+		return b
+	}
+	if t.Name != "" {
+		b.Name = t.Name
+	}
+	if t.PrimaryColor != "" {
+		b.PrimaryColor = t.PrimaryColor
+	}
+	if t.LogoURL != "" {
+		logo := t.LogoURL
+		if m.logos != nil {
+			if url := m.logos.LogoURLs(t.LogoURL)["email"]; url != "" {
+				logo = url
+			}
+		}
+		if strings.HasPrefix(logo, "/") {
+			logo = m.publicBaseURL + logo
+		}
+		b.LogoURL = logo
+	}
+	return b
+}
+
+// Render renders a template with the tenant's branding and overrides. An
+// empty locale uses the tenant's default language.
+func (m *Mailer) Render(ctx context.Context, t *tenant.Tenant, name, locale string, vars map[string]any) (*Rendered, error) {
+	locale, tenantID := m.localeFor(t, locale), ""
+	if t != nil {
+		tenantID = t.ID
+	}
+	return m.registry.Render(ctx, tenantID, name, locale, m.templateData(t, locale, vars))
+}
+
+// Preview renders a template with its sample variables. A non-nil draft is
+// rendered in place of the tenant's saved override.
+func (m *Mailer) Preview(ctx context.Context, t *tenant.Tenant, name, locale string, draft *TemplateOverride) (*Rendered, error) {
+	sample, err := m.registry.Sample(name)
+	if err != nil {
+		return nil, err
+	}
+	if draft == nil {
+		return m.Render(ctx, t, name, locale, sample)
+	}
+	locale = m.localeFor(t, locale)
+	return m.registry.RenderOverride(name, locale, draft, m.templateData(t, locale, sample))
+}
+
+// unqueuedTemplates are always sent right away: they carry short-lived
+// secrets such as reset links, which must neither be stored in the outbox
+// nor arrive late after a retry. The user can simply ask again.
+var unqueuedTemplates = map[string]bool{
+	TemplatePasswordReset: true,
+}
+
+// Send renders a template and sends it on behalf of a tenant, or queues it
+// when the mailer has a queue and the template may be queued. A nil tenant
+// uses the global settings and branding.
+func (m *Mailer) Send(ctx context.Context, t *tenant.Tenant, to, name, locale string, vars map[string]any) error {
+	rendered, err := m.Render(ctx, t, name, locale, vars)
+	if err != nil {
+		return fmt.Errorf("failed to render %s email: %w", name, err)
+	}
+
+	if m.queue != nil && !unqueuedTemplates[name] {
+		queued := &QueuedEmail{
+			ID:            uuid.New().String(),
+			Template:      name,
+			Recipient:     to,
+			Subject:       rendered.Subject,
+			HTMLBody:      rendered.HTML,
+			TextBody:      rendered.Text,
+			Status:        StatusPending,
+			NextAttemptAt: time.Now(),
+		}
+		if t != nil {
+			queued.TenantID = t.ID
+		}
+		if err := m.queue.Enqueue(ctx, queued); err != nil {
+			return fmt.Errorf("failed to queue %s email: %w", name, err)
+		}
+		metrics.EmailSends.WithLabelValues(name, metrics.EmailQueued).Inc()
+		return nil
+	}
+
+	_, err = m.transmit(ctx, m.SettingsFor(t), name, EmailRequest{
+		To:      to,
+		Subject: rendered.Subject,
+		HTML:    rendered.HTML,
+		Text:    rendered.Text,
+	})
+	if err != nil {
+		metrics.EmailSends.WithLabelValues(name, metrics.EmailFailed).Inc()
+		return err
+	}
+	metrics.EmailSends.WithLabelValues(name, metrics.EmailSent).Inc()
+	return nil
+}
+
+// transmit hands a rendered email to the transport inside a span named
+// after its template.
+func (m *Mailer) transmit(ctx context.Context, settings Settings, template string, req EmailRequest) (string, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "email send "+template,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("email.template", template)))
+	messageID, err := m.sender.Send(ctx, settings, req)
+	tracing.End(span, err)
+	return messageID, err
+}
+
+// SendPasswordResetEmail sends a password reset email on behalf of a tenant.
+func (m *Mailer) SendPasswordResetEmail(ctx context.Context, t *tenant.Tenant, to, resetLink string) error {
+	return m.Send(ctx, t, to, TemplatePasswordReset, "", map[string]any{"ResetLink": resetLink})
+}
+
+func (m *Mailer) localeFor(t *tenant.Tenant, locale string) string {
+	if l := NormalizeLocale(locale); l != "" {
+		return l
+	}
+	if t != nil {
+		if l := NormalizeLocale(t.PortalBranding().DefaultLanguage); l != "" {
+			return l
+		}
+	}
+	return DefaultLocale
+}
+
+// templateData adds the common variables (Brand, Locale, Year) to vars.
+func (m *Mailer) templateData(t *tenant.Tenant, locale string, vars map[string]any) map[string]any {
+	data := make(map[string]any, len(vars)+3)
+	for k, v := range vars {
+		data[k] = v
+	}
+	data["Brand"] = m.BrandingFor(t)
+	data["Locale"] = locale
+	data["Year"] = time.Now().Year()
+	return data
+}
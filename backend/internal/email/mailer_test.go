@@ -0,0 +1,167 @@
+package email
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"werk-ticketing/internal/tenant"
+)
+
+type stubLogos struct{}
+
+func (stubLogos) LogoURLs(url string) map[string]string {
+	return map[string]string{"original": url, "email": url + "?size=email"}
+}
+
+func newTestMailer(t *testing.T, overrides TemplateRepository) *Mailer {
+	t.Helper()
+	registry, err := NewRegistry(overrides)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	return NewMailer(NewMailgunClient(""), registry, Settings{
+		Domain: "mg.werk.co.id",
+		APIKey: "global-key",
+		Sender: "Werk <no-reply@mg.werk.co.id>",
+	}, stubLogos{}, "https://api.example.com/", nil)
+}
+
+func TestSettingsForFallsBackToDefaults(t *testing.T) {
+	m := newTestMailer(t, nil)
+
+	if s := m.SettingsFor(nil); s.Domain != "mg.werk.co.id" || s.APIKey != "global-key" || s.Sender != "Werk <no-reply@mg.werk.co.id>" {
+		t.Errorf("SettingsFor(nil) = %+v", s)
+	}
+
+	s := m.SettingsFor(&tenant.Tenant{Name: "Acme"})
+	if s.Domain != "mg.werk.co.id" || s.APIKey != "global-key" || s.Sender != `"Acme" <no-reply@mg.werk.co.id>` {
+		t.Errorf("SettingsFor(no settings) = %+v", s)
+	}
+
+	// A domain without the tenant's own API key is not used for sending.
+	s = m.SettingsFor(&tenant.Tenant{Name: "Acme", EmailDomain: "acme.com", EmailSender: "Acme Support"})
+	if s.Domain != "mg.werk.co.id" || s.APIKey != "global-key" || s.Sender != `"Acme Support" <no-reply@mg.werk.co.id>` {
+		t.Errorf("SettingsFor(sender name) = %+v", s)
+	}
+
+	s = m.SettingsFor(&tenant.Tenant{Name: "Acme", EmailDomain: "mail.acme.com", EmailAPIKey: "acme-key"})
+	if s.Domain != "mail.acme.com" || s.APIKey != "acme-key" || s.Sender != `"Acme" <no-reply@mail.acme.com>` {
+		t.Errorf("SettingsFor(own account) = %+v", s)
+	}
+
+	s = m.SettingsFor(&tenant.Tenant{Name: "Acme", EmailDomain: "mail.acme.com", EmailAPIKey: "acme-key", EmailSender: "Acme Support <help@mail.acme.com>"})
+	if s.Sender != "Acme Support <help@mail.acme.com>" {
+		t.Errorf("SettingsFor(own sender) = %+v", s)
+	}
+}
+
+func TestPasswordResetUsesTenantBranding(t *testing.T) {
+	m := newTestMailer(t, nil)
+	brand := m.BrandingFor(&tenant.Tenant{Name: "Acme <Corp>", PrimaryColor: "#1976D2", LogoURL: "/uploads/logo-id"})
+	if brand.LogoURL != "https://api.example.com/uploads/logo-id?size=email" {
+		t.Errorf("LogoURL = %q", brand.LogoURL)
+	}
+
+	rendered, err := m.Render(context.Background(), &tenant.Tenant{Name: "Acme <Corp>", PrimaryColor: "#1976D2", LogoURL: "/uploads/logo-id"},
+		TemplatePasswordReset, "", map[string]any{"ResetLink": "https://portal.example.com/reset-password?token=abc"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	html := rendered.HTML
+	for _, want := range []string{
+		"background-color: #1976D2",
+		"Tim Acme &lt;Corp&gt;",
+		`src="https://api.example.com/uploads/logo-id?size=email"`,
+		`href="https://portal.example.com/reset-password?token=abc"`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("email is missing %q", want)
+		}
+	}
+	if strings.Contains(html, "Werk Ticketing") {
+		t.Error("email still mentions the default brand")
+	}
+	if rendered.Subject != "Reset Password Anda - Acme <Corp>" || !strings.Contains(rendered.Text, "https://portal.example.com/reset-password?token=abc") {
+		t.Errorf("subject %q, text:\n%s", rendered.Subject, rendered.Text)
+	}
+}
+
+type memoryTemplates struct {
+	TemplateRepository
+	overrides map[string]*TemplateOverride
+}
+
+func (r *memoryTemplates) Find(ctx context.Context, tenantID, name, locale string) (*TemplateOverride, error) {
+	return r.overrides[tenantID+"/"+name+"/"+locale], nil
+}
+
+func TestRenderLocalesAndOverrides(t *testing.T) {
+	templates := &memoryTemplates{overrides: map[string]*TemplateOverride{
+		"tenant-1/password_reset/en": {Subject: "Password help for {{.Brand.Name}}", HTML: `<p>Custom {{.ResetLink}}</p>`},
+	}}
+	m := newTestMailer(t, templates)
+	ctx := context.Background()
+	vars := map[string]any{"ResetLink": "https://portal.example.com/r?t=1"}
+
+	other := &tenant.Tenant{ID: "tenant-2", Name: "Other", Branding: &tenant.Branding{DefaultLanguage: "en"}}
+	rendered, err := m.Render(ctx, other, TemplatePasswordReset, "", vars)
+	if err != nil || rendered.Subject != "Reset your password - Other" || !strings.Contains(rendered.HTML, `lang="en"`) {
+		t.Fatalf("tenant default language: %+v, %v", rendered, err)
+	}
+
+	acme := &tenant.Tenant{ID: "tenant-1", Name: "Acme"}
+	rendered, err = m.Render(ctx, acme, TemplatePasswordReset, "en-US", vars)
+	if err != nil {
+		t.Fatalf("Render override: %v", err)
+	}
+	if rendered.Subject != "Password help for Acme" || !strings.Contains(rendered.HTML, "<p>Custom https://portal.example.com/r?t=1</p>") {
+		t.Errorf("override not applied: %+v", rendered)
+	}
+	// The override leaves the layout and the plain-text part intact.
+	if !strings.Contains(rendered.HTML, "Please do not reply") || !strings.Contains(rendered.Text, "We received a request") {
+		t.Errorf("override dropped built-in parts: %+v", rendered)
+	}
+
+	// Indonesian has no override, so the built-in template is used.
+	if rendered, _ := m.Render(ctx, acme, TemplatePasswordReset, "id", vars); !strings.HasPrefix(rendered.Subject, "Reset Password Anda") {
+		t.Errorf("id subject = %q", rendered.Subject)
+	}
+}
+
+func TestPreviewRejectsBrokenDraft(t *testing.T) {
+	m := newTestMailer(t, nil)
+	ctx := context.Background()
+
+	rendered, err := m.Preview(ctx, nil, TemplatePasswordReset, "en", nil)
+	if err != nil || !strings.Contains(rendered.HTML, "sample-token") {
+		t.Fatalf("Preview = %+v, %v", rendered, err)
+	}
+	for _, draft := range []*TemplateOverride{
+		{HTML: "{{if}}"},
+		{Subject: "{{.Unknown}}"},
+	} {
+		if _, err := m.Preview(ctx, nil, TemplatePasswordReset, "en", draft); err == nil {
+			t.Errorf("Preview(%+v) succeeded", draft)
+		}
+	}
+	if _, err := m.Preview(ctx, nil, "missing", "en", nil); err != ErrUnknownTemplate {
+		t.Errorf("Preview(missing) = %v, want ErrUnknownTemplate", err)
+	}
+}
+
+func TestTemplateSamplesRender(t *testing.T) {
+	m := newTestMailer(t, nil)
+	for _, info := range m.registry.Templates() {
+		for _, locale := range info.Locales {
+			rendered, err := m.Preview(context.Background(), nil, info.Name, locale, nil)
+			if err != nil {
+				t.Errorf("%s.%s: %v", info.Name, locale, err)
+				continue
+			}
+			if rendered.Subject == "" || !strings.Contains(rendered.HTML, "</html>") || rendered.Text == "" {
+				t.Errorf("%s.%s rendered incompletely: %+v", info.Name, locale, rendered)
+			}
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package email
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Outbox writes each email as an .eml file instead of sending it, so mail
+// can be inspected during development and in tests.
+type Outbox struct {
+	dir string
+}
+
+// NewOutbox creates an outbox writing into dir, creating it if needed.
+func NewOutbox(dir string) (*Outbox, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("email outbox directory must be provided")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create email outbox: %w", err)
+	}
+	return &Outbox{dir: dir}, nil
+}
+
+// CheckConfig reports when the outbox directory is gone.
+func (o *Outbox) CheckConfig(settings Settings) error {
+	info, err := os.Stat(o.dir)
+	if err != nil {
+		return fmt.Errorf("email outbox unavailable: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("email outbox %s is not a directory", o.dir)
+	}
+	return nil
+}
+
+// Send writes req to a new file named after the time and a random suffix.
+func (o *Outbox) Send(ctx context.Context, settings Settings, req EmailRequest) (string, error) {
+	now := time.Now()
+	msg, id, err := buildMessage(settings.Sender, req, now)
+	if err != nil {
+		return "", err
+	}
+
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+
+	// Write to a temp name first so readers never see a partial file.
+	tmp := filepath.Join(o.dir, "."+name)
+	if err := os.WriteFile(tmp, msg, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(o.dir, name)); err != nil {
+		return "", err
+	}
+	return id, nil
+}
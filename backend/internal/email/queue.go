@@ -0,0 +1,171 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+
+	"werk-ticketing/internal/constants"
+)
+
+// Queue statuses.
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusDead    = "dead" // gave up after too many attempts or a permanent error
+)
+
+// QueuedEmail is a rendered email waiting in the outbox. The sending
+// settings are resolved from the tenant when the message is sent, so
+// credentials are never copied into the queue.
+type QueuedEmail struct {
+	ID                string     `gorm:"type:char(36);primaryKey" json:"id"`
+	TenantID          string     `gorm:"type:char(36);index" json:"tenant_id,omitempty"`
+	Template          string     `gorm:"size:100" json:"template"`
+	Recipient         string     `gorm:"size:255;not null" json:"recipient"`
+	Subject           string     `gorm:"type:text" json:"subject"`
+	HTMLBody          string     `gorm:"column:html_body;type:mediumtext" json:"-"`
+	TextBody          string     `gorm:"column:text_body;type:text" json:"-"`
+	Status            string     `gorm:"size:16;not null;default:pending;index:idx_email_outbox_due,priority:1" json:"status"`
+	Attempts          int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt     time.Time  `gorm:"not null;index:idx_email_outbox_due,priority:2" json:"next_attempt_at"`
+	LockedUntil       *time.Time `json:"-"`
+	ClaimToken        string     `gorm:"size:36;index" json:"-"`
+	LastError         string     `gorm:"type:text" json:"last_error,omitempty"`
+	ProviderMessageID string     `gorm:"size:255" json:"provider_message_id,omitempty"`
+	SentAt            *time.Time `json:"sent_at,omitempty"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (QueuedEmail) TableName() string {
+	return "email_outbox"
+}
+
+// QueueRepository stores the outbound email queue
+type QueueRepository interface {
+	Enqueue(ctx context.Context, email *QueuedEmail) error
+	// Claim locks up to limit due pending emails for lease and returns them,
+	// so several workers never send the same email at once.
+	Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*QueuedEmail, error)
+	MarkSent(ctx context.Context, id, providerMessageID string, sentAt time.Time) error
+	// MarkFailed records a failed attempt; status is StatusPending to retry
+	// at next or StatusDead to give up.
+	MarkFailed(ctx context.Context, id string, attempts int, status string, next time.Time, lastError string) error
+	// FindByID returns the tenant's queued email, or nil if the tenant has none
+	// with that ID.
+	FindByID(ctx context.Context, tenantID, id string) (*QueuedEmail, error)
+	ListByStatus(ctx context.Context, tenantID, status string, limit int) ([]*QueuedEmail, error)
+	// Requeue resets a dead email so it is retried right away.
+	Requeue(ctx context.Context, id string, now time.Time) error
+	// PruneBefore deletes sent and dead emails last updated before cutoff.
+	PruneBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type gormQueueRepository struct {
+	db *gorm.DB
+}
+
+// NewQueueRepository creates a new email queue repository
+func NewQueueRepository(db *gorm.DB) QueueRepository {
+	return &gormQueueRepository{db: db}
+}
+
+func (r *gormQueueRepository) Enqueue(ctx context.Context, email *QueuedEmail) error {
+	return r.db.WithContext(ctx).Create(email).Error
+}
+
+func (r *gormQueueRepository) Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*QueuedEmail, error) {
+	token := uuid.New().String()
+	err := r.db.WithContext(ctx).Model(&QueuedEmail{}).
+		Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+		Where("(locked_until IS NULL OR locked_until < ?)", now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Updates(map[string]interface{}{"claim_token": token, "locked_until": now.Add(lease)}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var emails []*QueuedEmail
+	err = r.db.WithContext(ctx).Where("claim_token = ? AND status = ?", token, StatusPending).Find(&emails).Error
+	return emails, err
+}
+
+func (r *gormQueueRepository) MarkSent(ctx context.Context, id, providerMessageID string, sentAt time.Time) error {
+	return r.db.WithContext(ctx).Model(&QueuedEmail{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":              StatusSent,
+		"attempts":            gorm.Expr("attempts + 1"),
+		"provider_message_id": providerMessageID,
+		"sent_at":             sentAt,
+		"locked_until":        nil,
+		"last_error":          "",
+	}).Error
+}
+
+func (r *gormQueueRepository) MarkFailed(ctx context.Context, id string, attempts int, status string, next time.Time, lastError string) error {
+	return r.db.WithContext(ctx).Model(&QueuedEmail{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          status,
+		"attempts":        attempts,
+		"next_attempt_at": next,
+		"last_error":      lastError,
+		"locked_until":    nil,
+	}).Error
+}
+
+func (r *gormQueueRepository) FindByID(ctx context.Context, tenantID, id string) (*QueuedEmail, error) {
+	var email QueuedEmail
+	err := r.db.WithContext(ctx).Where("id = ? AND tenant_id = ?", id, tenantID).First(&email).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &email, nil
+}
+
+func (r *gormQueueRepository) ListByStatus(ctx context.Context, tenantID, status string, limit int) ([]*QueuedEmail, error) {
+	var emails []*QueuedEmail
+	err := r.db.WithContext(ctx).Where("tenant_id = ? AND status = ?", tenantID, status).Order("created_at DESC").Limit(limit).Find(&emails).Error
+	return emails, err
+}
+
+func (r *gormQueueRepository) Requeue(ctx context.Context, id string, now time.Time) error {
+	return r.db.WithContext(ctx).Model(&QueuedEmail{}).Where("id = ? AND status = ?", id, StatusDead).Updates(map[string]interface{}{
+		"status":          StatusPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+		"locked_until":    nil,
+	}).Error
+}
+
+func (r *gormQueueRepository) PruneBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Delete(&QueuedEmail{}, "status IN ? AND updated_at < ?", []string{StatusSent, StatusDead}, cutoff)
+	return result.RowsAffected, result.Error
+}
+
+// PruneOutbox deletes sent and dead emails past EmailOutboxRetention
+// periodically until ctx is cancelled, so rendered bodies are not kept
+// forever.
+func PruneOutbox(ctx context.Context, queue QueueRepository, logger *logrus.Logger) {
+	ticker := time.NewTicker(constants.EmailOutboxPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if removed, err := queue.PruneBefore(ctx, now.Add(-constants.EmailOutboxRetention)); err != nil {
+				logger.WithError(err).Warn("email outbox prune failed")
+			} else if removed > 0 {
+				logger.WithField("removed", removed).Info("old outbox emails pruned")
+			}
+		}
+	}
+}
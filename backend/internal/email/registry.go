@@ -0,0 +1,266 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	stdErrors "errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Template names.
+const (
+	TemplatePasswordReset = "password_reset"
+	TemplateTicketUpdate  = "ticket_update"
+	TemplateTicketDigest  = "ticket_digest"
+)
+
+// Supported locales; DefaultLocale is used when none matches.
+const (
+	LocaleIndonesian = "id"
+	LocaleEnglish    = "en"
+	DefaultLocale    = LocaleIndonesian
+)
+
+// Locales lists the supported locales.
+var Locales = []string{LocaleIndonesian, LocaleEnglish}
+
+// ErrUnknownTemplate is returned for template names not in the registry.
+var ErrUnknownTemplate = stdErrors.New("unknown email template")
+
+// Built-in templates. Each name has <name>.<locale>.html defining "title"
+// and "content" for the shared layout, and <name>.<locale>.txt defining
+// "subject" and "text".
+//
+//go:embed templates
+var templateFS embed.FS
+
+// TemplateInfo describes a template for admins.
+type TemplateInfo struct {
+	Name    string   `json:"name"`
+	Locales []string `json:"locales"`
+	// Sample holds the template variables used for previews.
+	Sample map[string]any `json:"sample"`
+}
+
+// templateSamples registers the templates and the variables each one uses.
+var templateSamples = map[string]map[string]any{
+	TemplatePasswordReset: {
+		"ResetLink": "https://portal.example.com/reset-password?token=sample-token",
+	},
+	// Event is status_changed, comment_added or solution_proposed.
+	TemplateTicketUpdate: {
+		"Event":                 "comment_added",
+		"TicketID":              1234,
+		"TicketTitle":           "Laptop tidak bisa menyala",
+		"TicketLink":            "https://portal.example.com/tickets/1234",
+		"Status":                "Open",
+		"Comment":               "Silakan bawa laptop ke meja IT di lantai 3.",
+		"UnsubscribeTicketLink": "https://portal.example.com/unsubscribe?token=sample-ticket-token",
+		"UnsubscribeAllLink":    "https://portal.example.com/unsubscribe?token=sample-token",
+	},
+	TemplateTicketDigest: {
+		"Updates": []map[string]any{
+			{
+				"Event":       "status_changed",
+				"TicketID":    1234,
+				"TicketTitle": "Laptop tidak bisa menyala",
+				"TicketLink":  "https://portal.example.com/tickets/1234",
+				"Status":      "Open",
+				"Comment":     "",
+			},
+			{
+				"Event":       "solution_proposed",
+				"TicketID":    1240,
+				"TicketTitle": "Akses VPN",
+				"TicketLink":  "https://portal.example.com/tickets/1240",
+				"Status":      "Resolved",
+				"Comment":     "Akun VPN Anda sudah diaktifkan.",
+			},
+		},
+		"UnsubscribeAllLink": "https://portal.example.com/unsubscribe?token=sample-token",
+	},
+}
+
+// Rendered is a rendered email.
+type Rendered struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+type compiledTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+	// htmlSource is kept because executed html templates cannot be cloned;
+	// overrides are parsed from source instead.
+	htmlSource string
+}
+
+// Registry renders the built-in templates, applying per-tenant overrides.
+type Registry struct {
+	layout    string
+	builtin   map[string]*compiledTemplate // keyed by name + "." + locale
+	overrides TemplateRepository
+}
+
+// NewRegistry parses the built-in templates. overrides may be nil.
+func NewRegistry(overrides TemplateRepository) (*Registry, error) {
+	layout, err := fs.ReadFile(templateFS, "templates/layout.html")
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Registry{layout: string(layout), builtin: map[string]*compiledTemplate{}, overrides: overrides}
+	for name := range templateSamples {
+		for _, locale := range Locales {
+			key := name + "." + locale
+			html, err := fs.ReadFile(templateFS, "templates/"+key+".html")
+			if err != nil {
+				return nil, err
+			}
+			text, err := fs.ReadFile(templateFS, "templates/"+key+".txt")
+			if err != nil {
+				return nil, err
+			}
+
+			compiled := &compiledTemplate{htmlSource: string(html)}
+			if compiled.html, err = r.parseHTML(compiled.htmlSource, ""); err != nil {
+				return nil, fmt.Errorf("template %s.html: %w", key, err)
+			}
+			if compiled.text, err = texttemplate.New(key).Option("missingkey=error").Parse(string(text)); err != nil {
+				return nil, fmt.Errorf("template %s.txt: %w", key, err)
+			}
+			r.builtin[key] = compiled
+		}
+	}
+	return r, nil
+}
+
+// Templates lists the registered templates.
+func (r *Registry) Templates() []TemplateInfo {
+	infos := make([]TemplateInfo, 0, len(templateSamples))
+	for name, sample := range templateSamples {
+		infos = append(infos, TemplateInfo{Name: name, Locales: Locales, Sample: sample})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// Sample returns the preview variables of a template.
+func (r *Registry) Sample(name string) (map[string]any, error) {
+	sample, ok := templateSamples[name]
+	if !ok {
+		return nil, ErrUnknownTemplate
+	}
+	return sample, nil
+}
+
+// NormalizeLocale maps a locale such as "en-US" to a supported one, or ""
+// when it is not supported.
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	for _, l := range Locales {
+		if l == locale {
+			return l
+		}
+	}
+	return ""
+}
+
+// Render renders a template for a tenant, using the tenant's override for
+// the locale when one is saved. data is passed to the templates as is.
+func (r *Registry) Render(ctx context.Context, tenantID, name, locale string, data map[string]any) (*Rendered, error) {
+	if locale = NormalizeLocale(locale); locale == "" {
+		locale = DefaultLocale
+	}
+
+	var override *TemplateOverride
+	if r.overrides != nil && tenantID != "" {
+		var err error
+		if override, err = r.overrides.Find(ctx, tenantID, name, locale); err != nil {
+			return nil, fmt.Errorf("failed to load template override: %w", err)
+		}
+	}
+	return r.RenderOverride(name, locale, override, data)
+}
+
+// RenderOverride renders a template with an unsaved override, as used for
+// validation and previews. A nil override renders the built-in template.
+func (r *Registry) RenderOverride(name, locale string, override *TemplateOverride, data map[string]any) (*Rendered, error) {
+	builtin, ok := r.builtin[name+"."+locale]
+	if !ok {
+		if _, known := templateSamples[name]; !known {
+			return nil, ErrUnknownTemplate
+		}
+		builtin = r.builtin[name+"."+DefaultLocale]
+	}
+
+	html, text := builtin.html, builtin.text
+	subject := builtin.text.Lookup("subject")
+	if override != nil {
+		var err error
+		if override.HTML != "" {
+			if html, err = r.parseHTML(builtin.htmlSource, override.HTML); err != nil {
+				return nil, fmt.Errorf("html: %w", err)
+			}
+		}
+		if override.Text != "" {
+			if text, err = texttemplate.New("text").Option("missingkey=error").Parse(override.Text); err != nil {
+				return nil, fmt.Errorf("text: %w", err)
+			}
+		}
+		if override.Subject != "" {
+			if subject, err = texttemplate.New("subject").Option("missingkey=error").Parse(override.Subject); err != nil {
+				return nil, fmt.Errorf("subject: %w", err)
+			}
+		}
+	}
+
+	var out Rendered
+	var buf bytes.Buffer
+	if err := subject.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("subject: %w", err)
+	}
+	// Header values cannot span lines.
+	out.Subject = strings.Join(strings.Fields(buf.String()), " ")
+
+	buf.Reset()
+	if err := html.ExecuteTemplate(&buf, "layout", data); err != nil {
+		return nil, fmt.Errorf("html: %w", err)
+	}
+	out.HTML = buf.String()
+
+	buf.Reset()
+	if err := text.ExecuteTemplate(&buf, "text", data); err != nil {
+		return nil, fmt.Errorf("text: %w", err)
+	}
+	out.Text = strings.TrimSpace(buf.String()) + "\n"
+	return &out, nil
+}
+
+// parseHTML parses the layout with a template's blocks, replacing the
+// "content" block when content is not empty.
+func (r *Registry) parseHTML(source, content string) (*htmltemplate.Template, error) {
+	t, err := htmltemplate.New("layout").Option("missingkey=error").Parse(r.layout)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := t.Parse(source); err != nil {
+		return nil, err
+	}
+	if content != "" {
+		if _, err := t.New("content").Parse(content); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
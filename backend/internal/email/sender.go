@@ -0,0 +1,59 @@
+package email
+
+import (
+	"context"
+	"fmt"
+
+	"werk-ticketing/internal/config"
+)
+
+// EmailRequest represents an email to be sent
+type EmailRequest struct {
+	To      string
+	Subject string
+	HTML    string
+	Text    string // plain-text alternative; optional
+}
+
+// Sender delivers a rendered email and returns the provider's message ID.
+// Settings.Sender is the From address; Domain and APIKey only apply to
+// Mailgun.
+type Sender interface {
+	Send(ctx context.Context, settings Settings, req EmailRequest) (string, error)
+}
+
+// ConfigChecker is implemented by transports that can tell whether they
+// are configured to send, without sending anything.
+type ConfigChecker interface {
+	CheckConfig(settings Settings) error
+}
+
+// PermanentError marks a failure that retrying cannot fix, such as an
+// invalid address or rejected credentials.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// NewSender returns the transport selected by EMAIL_DRIVER.
+func NewSender(cfg *config.Config) (Sender, error) {
+	switch cfg.EmailDriver {
+	case "", "mailgun":
+		return NewMailgunClient(cfg.MailgunBaseURL), nil
+	case "smtp":
+		return NewSMTP(SMTPOptions{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			TLS:      cfg.SMTPTLS,
+		})
+	case "outbox":
+		return NewOutbox(cfg.EmailOutboxDir)
+	default:
+		return nil, fmt.Errorf("unknown email driver %q", cfg.EmailDriver)
+	}
+}
@@ -0,0 +1,174 @@
+package email
+
+import (
+	"bufio"
+	"context"
+	stdErrors "errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var testSettings = Settings{Domain: "mg.example.com", APIKey: "key-123", Sender: "Acme <no-reply@mg.example.com>"}
+
+var testRequest = EmailRequest{To: "user@example.com", Subject: "Reset Password Anda - Acme", HTML: "<p>Halo</p>", Text: "Halo"}
+
+func TestMailgunUsesBaseURL(t *testing.T) {
+	var gotPath, gotAuth, gotFrom string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth, _, _ = strings.Cut(r.Header.Get("Authorization"), " ")
+		_ = r.ParseForm()
+		gotFrom = r.PostForm.Get("from")
+		io.WriteString(w, `{"id":"<20261018.1@mg.example.com>","message":"Queued. Thank you."}`)
+	}))
+	defer srv.Close()
+
+	id, err := NewMailgunClient(srv.URL+"/v3/").Send(context.Background(), testSettings, testRequest)
+	if err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if id != "<20261018.1@mg.example.com>" {
+		t.Errorf("message id = %q", id)
+	}
+	if gotPath != "/v3/mg.example.com/messages" || gotAuth != "Basic" || gotFrom != testSettings.Sender {
+		t.Errorf("request = %s auth=%s from=%q", gotPath, gotAuth, gotFrom)
+	}
+}
+
+func TestMailgunClientErrorsArePermanent(t *testing.T) {
+	status := http.StatusBadRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	var permanent *PermanentError
+	_, err := NewMailgunClient(srv.URL).Send(context.Background(), testSettings, testRequest)
+	if !stdErrors.As(err, &permanent) {
+		t.Errorf("400 error = %v, want permanent", err)
+	}
+
+	status = http.StatusTooManyRequests
+	_, err = NewMailgunClient(srv.URL).Send(context.Background(), testSettings, testRequest)
+	if err == nil || stdErrors.As(err, &permanent) {
+		t.Errorf("429 error = %v, want retryable", err)
+	}
+}
+
+func TestOutboxWritesEML(t *testing.T) {
+	dir := t.TempDir()
+	outbox, err := NewOutbox(dir)
+	if err != nil {
+		t.Fatalf("NewOutbox: %v", err)
+	}
+	if _, err := outbox.Send(context.Background(), testSettings, testRequest); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("outbox files = %v", files)
+	}
+	data, _ := os.ReadFile(files[0])
+	for _, want := range []string{"From: \"Acme\" <no-reply@mg.example.com>\r\n", "To: <user@example.com>\r\n", "Subject: Reset Password Anda - Acme\r\n", "Content-Type: multipart/alternative; boundary=", "Content-Type: text/plain; charset=\"utf-8\"", "<p>Halo</p>"} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("eml is missing %q:\n%s", want, data)
+		}
+	}
+}
+
+// startStubSMTP accepts one plain-text SMTP session and returns the
+// envelope and message it received.
+func startStubSMTP(t *testing.T) (port int, received <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	out := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+		var session strings.Builder
+		reply("220 stub ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimRight(line, "\r\n")
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250 stub")
+			case strings.HasPrefix(cmd, "MAIL FROM"), strings.HasPrefix(cmd, "RCPT TO"):
+				session.WriteString(cmd + "\n")
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				for {
+					data, err := r.ReadString('\n')
+					if err != nil || data == ".\r\n" {
+						break
+					}
+					session.WriteString(data)
+				}
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				out <- session.String()
+				return
+			default:
+				reply("502 unsupported")
+			}
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port, out
+}
+
+func TestSMTPSend(t *testing.T) {
+	port, received := startStubSMTP(t)
+	sender, err := NewSMTP(SMTPOptions{Host: "127.0.0.1", Port: port, TLS: SMTPNone})
+	if err != nil {
+		t.Fatalf("NewSMTP: %v", err)
+	}
+	if _, err := sender.Send(context.Background(), testSettings, testRequest); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	session := <-received
+	for _, want := range []string{"MAIL FROM:<no-reply@mg.example.com>", "RCPT TO:<user@example.com>", "Subject: Reset Password Anda - Acme", "<p>Halo</p>"} {
+		if !strings.Contains(session, want) {
+			t.Errorf("session is missing %q:\n%s", want, session)
+		}
+	}
+}
+
+func TestSMTPRequiresSTARTTLS(t *testing.T) {
+	port, _ := startStubSMTP(t)
+	sender, _ := NewSMTP(SMTPOptions{Host: "127.0.0.1", Port: port})
+	_, err := sender.Send(context.Background(), testSettings, testRequest)
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Errorf("Send without STARTTLS support = %v, want error", err)
+	}
+	if _, err := NewSMTP(SMTPOptions{Host: "smtp.example.com", TLS: "ssl3"}); err == nil {
+		t.Error("unknown TLS mode accepted")
+	}
+	if s, _ := NewSMTP(SMTPOptions{Host: "smtp.example.com", TLS: SMTPImplicit}); !strings.HasSuffix(s.addr, ":"+strconv.Itoa(465)) {
+		t.Errorf("implicit TLS address = %s", s.addr)
+	}
+}
@@ -0,0 +1,160 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	stdErrors "errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// SMTP TLS modes.
+const (
+	SMTPStartTLS = "starttls" // plain connection upgraded with STARTTLS (port 587)
+	SMTPImplicit = "tls"      // TLS from the first byte (port 465)
+	SMTPNone     = "none"     // no TLS; only for local relays
+)
+
+// SMTPOptions configures an SMTP relay.
+type SMTPOptions struct {
+	Host     string
+	Port     int // 0 uses the default port for the TLS mode
+	Username string
+	Password string
+	TLS      string // SMTPStartTLS (default), SMTPImplicit or SMTPNone
+
+	// TLSConfig overrides the TLS settings, e.g. to trust a test CA.
+	TLSConfig *tls.Config
+}
+
+// SMTPSender delivers email through an SMTP relay.
+type SMTPSender struct {
+	opts SMTPOptions
+	addr string
+}
+
+// NewSMTP creates an SMTP sender.
+func NewSMTP(opts SMTPOptions) (*SMTPSender, error) {
+	if opts.Host == "" {
+		return nil, fmt.Errorf("SMTP host must be provided")
+	}
+	if opts.TLS == "" {
+		opts.TLS = SMTPStartTLS
+	}
+	if opts.Port == 0 {
+		switch opts.TLS {
+		case SMTPImplicit:
+			opts.Port = 465
+		case SMTPStartTLS:
+			opts.Port = 587
+		default:
+			opts.Port = 25
+		}
+	}
+	switch opts.TLS {
+	case SMTPStartTLS, SMTPImplicit, SMTPNone:
+	default:
+		return nil, fmt.Errorf("unknown SMTP TLS mode %q", opts.TLS)
+	}
+	if opts.TLSConfig == nil {
+		opts.TLSConfig = &tls.Config{ServerName: opts.Host, MinVersion: tls.VersionTLS12}
+	}
+	return &SMTPSender{opts: opts, addr: net.JoinHostPort(opts.Host, strconv.Itoa(opts.Port))}, nil
+}
+
+// Send delivers req using settings.Sender as the From address.
+func (s *SMTPSender) Send(ctx context.Context, settings Settings, req EmailRequest) (string, error) {
+	msg, id, err := buildMessage(settings.Sender, req, time.Now())
+	if err != nil {
+		return "", err
+	}
+	from, _ := mail.ParseAddress(settings.Sender)
+	to, _ := mail.ParseAddress(req.To)
+
+	client, err := s.dial(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	defer client.Close()
+
+	if s.opts.TLS == SMTPStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return "", fmt.Errorf("SMTP server %s does not support STARTTLS", s.addr)
+		}
+		if err := client.StartTLS(s.opts.TLSConfig); err != nil {
+			return "", fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+	if s.opts.Username != "" {
+		auth := smtp.PlainAuth("", s.opts.Username, s.opts.Password, s.opts.Host)
+		if err := client.Auth(auth); err != nil {
+			return "", smtpError("SMTP authentication failed", err)
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return "", smtpError("SMTP MAIL FROM failed", err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return "", smtpError("SMTP RCPT TO failed", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return "", smtpError("SMTP DATA failed", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return "", fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", smtpError("SMTP server rejected message", err)
+	}
+	// The message was accepted; a failed QUIT does not undo that.
+	_ = client.Quit()
+	return id, nil
+}
+
+// dial connects to the relay, honouring the context deadline for the
+// whole session.
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	if s.opts.TLS == SMTPImplicit {
+		tlsConn := tls.Client(conn, s.opts.TLSConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, s.opts.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// smtpError wraps a failed SMTP command; 5xx replies are permanent.
+func smtpError(stage string, err error) error {
+	wrapped := fmt.Errorf("%s: %w", stage, err)
+	var reply *textproto.Error
+	if stdErrors.As(err, &reply) && reply.Code >= 500 {
+		return &PermanentError{Err: wrapped}
+	}
+	return wrapped
+}
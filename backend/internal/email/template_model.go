@@ -0,0 +1,37 @@
+package email
+
+import "time"
+
+// TemplateOverride replaces parts of a built-in template for one tenant and
+// locale. Empty parts fall back to the built-in version. HTML replaces the
+// "content" block inside the shared branded layout.
+type TemplateOverride struct {
+	ID        string    `gorm:"type:char(36);primaryKey" json:"id"`
+	TenantID  string    `gorm:"type:char(36);not null;uniqueIndex:idx_email_templates_tenant_name_locale" json:"tenant_id"`
+	Name      string    `gorm:"size:100;not null;uniqueIndex:idx_email_templates_tenant_name_locale" json:"name"`
+	Locale    string    `gorm:"size:10;not null;uniqueIndex:idx_email_templates_tenant_name_locale" json:"locale"`
+	Subject   string    `gorm:"type:text" json:"subject"`
+	HTML      string    `gorm:"column:html_body;type:mediumtext" json:"html"`
+	Text      string    `gorm:"column:text_body;type:text" json:"text"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (TemplateOverride) TableName() string {
+	return "email_templates"
+}
+
+// TemplateOverrideRequest is the DTO for saving or previewing an override
+type TemplateOverrideRequest struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
+}
+
+// PreviewRequest is the DTO for rendering a template with sample data
+type PreviewRequest struct {
+	Locale string `json:"locale,omitempty"`
+	// Draft, when set, is rendered instead of the saved override.
+	Draft *TemplateOverrideRequest `json:"draft,omitempty"`
+}
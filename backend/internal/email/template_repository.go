@@ -0,0 +1,60 @@
+package email
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TemplateRepository stores per-tenant template overrides
+type TemplateRepository interface {
+	// Find returns nil (and no error) when the tenant has no override.
+	Find(ctx context.Context, tenantID, name, locale string) (*TemplateOverride, error)
+	ListByTenant(ctx context.Context, tenantID string) ([]*TemplateOverride, error)
+	// Save creates the override or replaces the one for the same tenant, name and locale.
+	Save(ctx context.Context, override *TemplateOverride) error
+	Delete(ctx context.Context, tenantID, name, locale string) error
+}
+
+type gormTemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewTemplateRepository creates a new template override repository
+func NewTemplateRepository(db *gorm.DB) TemplateRepository {
+	return &gormTemplateRepository{db: db}
+}
+
+func (r *gormTemplateRepository) Find(ctx context.Context, tenantID, name, locale string) (*TemplateOverride, error) {
+	var override TemplateOverride
+	err := r.db.WithContext(ctx).
+		Where("tenant_id = ? AND name = ? AND locale = ?", tenantID, name, locale).
+		First(&override).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &override, nil
+}
+
+func (r *gormTemplateRepository) ListByTenant(ctx context.Context, tenantID string) ([]*TemplateOverride, error) {
+	var overrides []*TemplateOverride
+	err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("name, locale").Find(&overrides).Error
+	return overrides, err
+}
+
+func (r *gormTemplateRepository) Save(ctx context.Context, override *TemplateOverride) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tenant_id"}, {Name: "name"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "html_body", "text_body", "updated_at"}),
+	}).Create(override).Error
+}
+
+func (r *gormTemplateRepository) Delete(ctx context.Context, tenantID, name, locale string) error {
+	return r.db.WithContext(ctx).
+		Delete(&TemplateOverride{}, "tenant_id = ? AND name = ? AND locale = ?", tenantID, name, locale).Error
+}
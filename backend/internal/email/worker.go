@@ -0,0 +1,140 @@
+package email
+
+import (
+	"context"
+	stdErrors "errors"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/metrics"
+	"werk-ticketing/internal/tenant"
+)
+
+// Worker sends queued emails, retrying failures with exponential backoff
+// and dead-lettering those that keep failing.
+type Worker struct {
+	queue   QueueRepository
+	mailer  *Mailer
+	tenants tenant.Repository
+	logger  *logrus.Logger
+	now     func() time.Time
+}
+
+// NewWorker creates a queue worker sending through the mailer's transport.
+func NewWorker(queue QueueRepository, mailer *Mailer, tenants tenant.Repository, logger *logrus.Logger) *Worker {
+	return &Worker{queue: queue, mailer: mailer, tenants: tenants, logger: logger, now: time.Now}
+}
+
+// Run processes the queue periodically until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(constants.EmailQueuePollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain full batches right away; wait for the next tick otherwise.
+		for {
+			n, err := w.ProcessDue(ctx)
+			if err != nil {
+				w.logger.WithError(err).Error("failed to claim queued emails")
+			}
+			if err != nil || n < constants.EmailQueueBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue sends one batch of due emails and returns how many it claimed.
+func (w *Worker) ProcessDue(ctx context.Context) (int, error) {
+	emails, err := w.queue.Claim(ctx, w.now(), constants.EmailQueueBatchSize, constants.EmailSendLease)
+	if err != nil {
+		return 0, err
+	}
+	for _, e := range emails {
+		w.deliver(ctx, e)
+	}
+	return len(emails), nil
+}
+
+func (w *Worker) deliver(ctx context.Context, e *QueuedEmail) {
+	logger := w.logger.WithFields(logrus.Fields{
+		"emailID":  e.ID,
+		"tenantID": e.TenantID,
+		"template": e.Template,
+		"attempt":  e.Attempts + 1,
+	})
+
+	var t *tenant.Tenant
+	if e.TenantID != "" {
+		var err error
+		if t, err = w.tenants.FindByIDIncludingInactive(ctx, e.TenantID); err != nil {
+			w.fail(ctx, logger, e, err)
+			return
+		}
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, constants.EmailSendTimeout)
+	defer cancel()
+	messageID, err := w.mailer.transmit(sendCtx, w.mailer.SettingsFor(t), e.Template, EmailRequest{
+		To:      e.Recipient,
+		Subject: e.Subject,
+		HTML:    e.HTMLBody,
+		Text:    e.TextBody,
+	})
+	if err != nil {
+		w.fail(ctx, logger, e, err)
+		return
+	}
+	metrics.EmailSends.WithLabelValues(e.Template, metrics.EmailSent).Inc()
+
+	if err := w.queue.MarkSent(ctx, e.ID, messageID, w.now()); err != nil {
+		// The email went out; failing to record it only risks a duplicate
+		// once the lease expires.
+		logger.WithError(err).Error("failed to mark email as sent")
+		return
+	}
+	logger.WithField("providerMessageID", messageID).Info("email sent")
+}
+
+func (w *Worker) fail(ctx context.Context, logger *logrus.Entry, e *QueuedEmail, sendErr error) {
+	attempts := e.Attempts + 1
+	status, next := StatusPending, w.now().Add(retryDelay(attempts))
+
+	var permanent *PermanentError
+	if stdErrors.As(sendErr, &permanent) || attempts >= constants.EmailMaxAttempts {
+		status = StatusDead
+	}
+
+	if err := w.queue.MarkFailed(ctx, e.ID, attempts, status, next, sendErr.Error()); err != nil {
+		logger.WithError(err).Error("failed to record email failure")
+	}
+	if status == StatusDead {
+		metrics.EmailSends.WithLabelValues(e.Template, metrics.EmailDead).Inc()
+		logger.WithError(sendErr).Error("email dead-lettered")
+	} else {
+		metrics.EmailSends.WithLabelValues(e.Template, metrics.EmailRetry).Inc()
+		logger.WithError(sendErr).WithField("nextAttemptAt", next).Warn("email send failed, will retry")
+	}
+}
+
+// retryDelay is the wait before the next attempt after attempts failures:
+// exponential from EmailRetryBaseDelay, capped, with up to 10% jitter.
+func retryDelay(attempts int) time.Duration {
+	delay := constants.EmailRetryBaseDelay
+	for i := 1; i < attempts && delay < constants.EmailRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > constants.EmailRetryMaxDelay {
+		delay = constants.EmailRetryMaxDelay
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay/10)+1))
+}
@@ -0,0 +1,197 @@
+package email
+
+import (
+	"context"
+	stdErrors "errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+)
+
+// memoryQueue is an in-memory QueueRepository; Claim ignores leases since
+// the tests run a single worker.
+type memoryQueue struct {
+	QueueRepository
+	emails map[string]*QueuedEmail
+}
+
+func (q *memoryQueue) Enqueue(ctx context.Context, email *QueuedEmail) error {
+	q.emails[email.ID] = email
+	return nil
+}
+
+func (q *memoryQueue) Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*QueuedEmail, error) {
+	var due []*QueuedEmail
+	for _, e := range q.emails {
+		if e.Status == StatusPending && !e.NextAttemptAt.After(now) && len(due) < limit {
+			copied := *e
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (q *memoryQueue) MarkSent(ctx context.Context, id, providerMessageID string, sentAt time.Time) error {
+	e := q.emails[id]
+	e.Status, e.ProviderMessageID, e.SentAt = StatusSent, providerMessageID, &sentAt
+	e.Attempts++
+	return nil
+}
+
+func (q *memoryQueue) MarkFailed(ctx context.Context, id string, attempts int, status string, next time.Time, lastError string) error {
+	e := q.emails[id]
+	e.Attempts, e.Status, e.NextAttemptAt, e.LastError = attempts, status, next, lastError
+	return nil
+}
+
+// scriptedSender fails with the queued errors before succeeding.
+type scriptedSender struct {
+	errs []error
+	sent []EmailRequest
+}
+
+func (s *scriptedSender) Send(ctx context.Context, settings Settings, req EmailRequest) (string, error) {
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return "", err
+	}
+	s.sent = append(s.sent, req)
+	return "<msg-1@mg.werk.co.id>", nil
+}
+
+func newTestWorker(t *testing.T, sender Sender) (*Worker, *Mailer, *memoryQueue, *time.Time) {
+	t.Helper()
+	registry, err := NewRegistry(nil)
+	if err != nil {
+		t.Fatalf("NewRegistry: %v", err)
+	}
+	queue := &memoryQueue{emails: map[string]*QueuedEmail{}}
+	mailer := NewMailer(sender, registry, Settings{Sender: "Werk <no-reply@mg.werk.co.id>"}, stubLogos{}, "", queue)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	worker := NewWorker(queue, mailer, nil, logger)
+	now := time.Now()
+	worker.now = func() time.Time { return now }
+	return worker, mailer, queue, &now
+}
+
+func onlyEmail(t *testing.T, q *memoryQueue) *QueuedEmail {
+	t.Helper()
+	if len(q.emails) != 1 {
+		t.Fatalf("queue has %d emails, want 1", len(q.emails))
+	}
+	for _, e := range q.emails {
+		return e
+	}
+	return nil
+}
+
+// sendTicketUpdate queues a ticket update email to user@example.com.
+func sendTicketUpdate(t *testing.T, mailer *Mailer) error {
+	t.Helper()
+	return mailer.Send(context.Background(), nil, "user@example.com", TemplateTicketUpdate, "", templateSamples[TemplateTicketUpdate])
+}
+
+func TestSendEnqueuesAndWorkerDelivers(t *testing.T) {
+	sender := &scriptedSender{errs: []error{stdErrors.New("mailgun returned status 503")}}
+	worker, mailer, queue, now := newTestWorker(t, sender)
+
+	if err := sendTicketUpdate(t, mailer); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	queued := onlyEmail(t, queue)
+	if queued.Status != StatusPending || queued.Recipient != "user@example.com" || len(sender.sent) != 0 {
+		t.Fatalf("queued = %+v, sent = %d", queued, len(sender.sent))
+	}
+
+	// The first attempt fails and is scheduled for a retry.
+	*now = queued.NextAttemptAt
+	if _, err := worker.ProcessDue(context.Background()); err != nil {
+		t.Fatalf("ProcessDue: %v", err)
+	}
+	if queued.Status != StatusPending || queued.Attempts != 1 || !queued.NextAttemptAt.After(*now) || queued.LastError == "" {
+		t.Fatalf("after failure = %+v", queued)
+	}
+
+	// Nothing is due before the backoff has passed.
+	if n, _ := worker.ProcessDue(context.Background()); n != 0 {
+		t.Fatalf("claimed %d emails before the retry was due", n)
+	}
+
+	*now = queued.NextAttemptAt
+	worker.ProcessDue(context.Background())
+	if queued.Status != StatusSent || queued.ProviderMessageID != "<msg-1@mg.werk.co.id>" || queued.Attempts != 2 {
+		t.Fatalf("after retry = %+v", queued)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].Text == "" {
+		t.Errorf("sent = %+v", sender.sent)
+	}
+}
+
+func TestWorkerDeadLetters(t *testing.T) {
+	var errs []error
+	for i := 0; i < constants.EmailMaxAttempts; i++ {
+		errs = append(errs, stdErrors.New("connection refused"))
+	}
+	worker, mailer, queue, now := newTestWorker(t, &scriptedSender{errs: errs})
+	sendTicketUpdate(t, mailer)
+	queued := onlyEmail(t, queue)
+
+	for i := 0; i < constants.EmailMaxAttempts; i++ {
+		*now = queued.NextAttemptAt
+		worker.ProcessDue(context.Background())
+	}
+	if queued.Status != StatusDead || queued.Attempts != constants.EmailMaxAttempts {
+		t.Errorf("after %d failures = %+v", constants.EmailMaxAttempts, queued)
+	}
+
+	// Permanent errors are not retried.
+	worker, mailer, queue, now = newTestWorker(t, &scriptedSender{errs: []error{&PermanentError{Err: stdErrors.New("mailgun returned status 400")}}})
+	sendTicketUpdate(t, mailer)
+	*now = onlyEmail(t, queue).NextAttemptAt
+	worker.ProcessDue(context.Background())
+	if queued := onlyEmail(t, queue); queued.Status != StatusDead || queued.Attempts != 1 {
+		t.Errorf("after permanent error = %+v", queued)
+	}
+}
+
+func TestPasswordResetIsNotQueued(t *testing.T) {
+	sender := &scriptedSender{}
+	_, mailer, queue, _ := newTestWorker(t, sender)
+
+	if err := mailer.SendPasswordResetEmail(context.Background(), nil, "user@example.com", "https://app/reset?token=x"); err != nil {
+		t.Fatalf("SendPasswordResetEmail: %v", err)
+	}
+	if len(queue.emails) != 0 || len(sender.sent) != 1 {
+		t.Errorf("queued %d emails and sent %d, want the reset link sent right away", len(queue.emails), len(sender.sent))
+	}
+
+	// A failed send is reported to the caller instead of being retried.
+	sender.errs = []error{stdErrors.New("connection refused")}
+	if err := mailer.SendPasswordResetEmail(context.Background(), nil, "user@example.com", "https://app/reset?token=y"); err == nil {
+		t.Error("SendPasswordResetEmail succeeded, want the send error")
+	}
+	if len(queue.emails) != 0 {
+		t.Errorf("queued %d emails after a failed reset email", len(queue.emails))
+	}
+}
+
+func TestRetryDelayBacksOff(t *testing.T) {
+	prev := time.Duration(0)
+	for attempts := 1; attempts <= 4; attempts++ {
+		d := retryDelay(attempts)
+		if d <= prev {
+			t.Errorf("retryDelay(%d) = %v, want more than %v", attempts, d, prev)
+		}
+		prev = d
+	}
+	if d := retryDelay(50); d < constants.EmailRetryMaxDelay || d > constants.EmailRetryMaxDelay*11/10 {
+		t.Errorf("retryDelay(50) = %v, want about %v", d, constants.EmailRetryMaxDelay)
+	}
+}
@@ -16,6 +16,8 @@ const (
 	ErrCodeEmailAlreadyExist  = "EMAIL_ALREADY_EXIST"
 	ErrCodeInvalidCredentials = "INVALID_CREDENTIALS"
 	ErrCodeConflict           = "CONFLICT"
+	ErrCodeTooManyRequests    = "TOO_MANY_REQUESTS"
+	ErrCodeAttachmentRejected = "ATTACHMENT_REJECTED"
 )
 
 // Predefined errors
@@ -29,6 +31,7 @@ var (
 	ErrEmailAlreadyExist  = errors.New("email already registered")
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrConflict           = errors.New("resource already exists")
+	ErrTooManyRequests    = errors.New("too many requests")
 )
 
 // AppError represents an application error with context
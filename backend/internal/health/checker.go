@@ -0,0 +1,181 @@
+// Package health implements the liveness and readiness endpoints.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Component statuses
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded" // a non-critical component is down
+	StatusDown     = "down"
+)
+
+// Component is one dependency checked for readiness.
+type Component struct {
+	Name string
+	// Critical components make the service unready when they fail; others
+	// only degrade the report.
+	Critical bool
+	// Timeout and TTL override the checker's defaults when set.
+	Timeout time.Duration
+	TTL     time.Duration
+	Check   func(ctx context.Context) error
+}
+
+// Result is the outcome of a component's latest check.
+type Result struct {
+	Status    string  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latency_ms"`
+	// Error can name hosts and credentials, so it is logged rather than
+	// served on the unauthenticated readiness endpoint.
+	Error     string    `json:"-"`
+	CheckedAt time.Time `json:"checked_at"`
+	Cached    bool      `json:"cached"`
+}
+
+// Report is the readiness response body.
+type Report struct {
+	Status     string            `json:"status"`
+	Components map[string]Result `json:"components"`
+}
+
+// Checker runs components concurrently, each bounded by a timeout, and
+// reuses results for a TTL so frequent probes do not load dependencies.
+type Checker struct {
+	timeout time.Duration
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu       sync.Mutex
+	cache    map[string]Result
+	inflight map[string]chan struct{}
+}
+
+// NewChecker creates a checker with default per-component timeout and TTL.
+func NewChecker(timeout, ttl time.Duration) *Checker {
+	return &Checker{
+		timeout:  timeout,
+		ttl:      ttl,
+		now:      time.Now,
+		cache:    make(map[string]Result),
+		inflight: make(map[string]chan struct{}),
+	}
+}
+
+// Run checks every component and summarises them: down when a critical
+// component fails, degraded when another one does.
+func (c *Checker) Run(ctx context.Context, components []Component) Report {
+	results := make([]Result, len(components))
+	var wg sync.WaitGroup
+	for i, comp := range components {
+		wg.Add(1)
+		go func(i int, comp Component) {
+			defer wg.Done()
+			results[i] = c.result(ctx, comp)
+		}(i, comp)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Components: make(map[string]Result, len(components))}
+	for i, comp := range components {
+		r := results[i]
+		report.Components[comp.Name] = r
+		if r.Status == StatusOK {
+			continue
+		}
+		if r.Critical {
+			report.Status = StatusDown
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// Forget drops cached results of components not in keep, such as probes of
+// deleted tenants.
+func (c *Checker) Forget(keep []Component) {
+	names := make(map[string]bool, len(keep))
+	for _, comp := range keep {
+		names[comp.Name] = true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name := range c.cache {
+		if !names[name] {
+			delete(c.cache, name)
+		}
+	}
+}
+
+// result returns the cached result while it is fresh. Otherwise one caller
+// runs the check and concurrent callers wait for its result.
+func (c *Checker) result(ctx context.Context, comp Component) Result {
+	ttl := comp.TTL
+	if ttl == 0 {
+		ttl = c.ttl
+	}
+
+	for {
+		c.mu.Lock()
+		if r, ok := c.cache[comp.Name]; ok && c.now().Sub(r.CheckedAt) < ttl {
+			c.mu.Unlock()
+			r.Cached = true
+			return r
+		}
+		wait, running := c.inflight[comp.Name]
+		if !running {
+			done := make(chan struct{})
+			c.inflight[comp.Name] = done
+			c.mu.Unlock()
+
+			r := c.check(ctx, comp)
+			c.mu.Lock()
+			c.cache[comp.Name] = r
+			delete(c.inflight, comp.Name)
+			c.mu.Unlock()
+			close(done)
+			return r
+		}
+		c.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return Result{Status: StatusDown, Critical: comp.Critical, Error: ctx.Err().Error(), CheckedAt: c.now()}
+		}
+	}
+}
+
+func (c *Checker) check(ctx context.Context, comp Component) Result {
+	timeout := comp.Timeout
+	if timeout == 0 {
+		timeout = c.timeout
+	}
+	// The result is shared with other callers, so it must not depend on
+	// this caller going away.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	start := c.now()
+	err := comp.Check(ctx)
+	r := Result{
+		Status:    StatusOK,
+		Critical:  comp.Critical,
+		LatencyMs: float64(c.now().Sub(start).Microseconds()) / 1000,
+		CheckedAt: start,
+	}
+	if err != nil {
+		r.Status = StatusDown
+		r.Error = err.Error()
+		if ctx.Err() == context.DeadlineExceeded {
+			r.Error = "timed out after " + timeout.String()
+		}
+	}
+	return r
+}
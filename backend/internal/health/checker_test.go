@@ -0,0 +1,141 @@
+package health
+
+import (
+	"context"
+	stdErrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"werk-ticketing/internal/tenant"
+)
+
+func TestRunSummarisesComponents(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return stdErrors.New("boom") }
+
+	tests := []struct {
+		name       string
+		components []Component
+		want       string
+	}{
+		{"all ok", []Component{{Name: "db", Critical: true, Check: ok}, {Name: "email", Check: ok}}, StatusOK},
+		{"optional down", []Component{{Name: "db", Critical: true, Check: ok}, {Name: "email", Check: fail}}, StatusDegraded},
+		{"critical down", []Component{{Name: "db", Critical: true, Check: fail}, {Name: "email", Check: fail}}, StatusDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := NewChecker(time.Second, time.Minute).Run(context.Background(), tt.components)
+			if report.Status != tt.want {
+				t.Errorf("status = %s, want %s", report.Status, tt.want)
+			}
+			if len(report.Components) != len(tt.components) {
+				t.Errorf("got %d components, want %d", len(report.Components), len(tt.components))
+			}
+		})
+	}
+}
+
+func TestRunCachesResults(t *testing.T) {
+	var calls atomic.Int32
+	comp := Component{Name: "db", Check: func(context.Context) error {
+		calls.Add(1)
+		return nil
+	}}
+	now := time.Now()
+	checker := NewChecker(time.Second, 10*time.Second)
+	checker.now = func() time.Time { return now }
+
+	first := checker.Run(context.Background(), []Component{comp})
+	second := checker.Run(context.Background(), []Component{comp})
+	if calls.Load() != 1 {
+		t.Fatalf("check ran %d times, want 1", calls.Load())
+	}
+	if first.Components["db"].Cached || !second.Components["db"].Cached {
+		t.Errorf("cached = %v then %v, want false then true", first.Components["db"].Cached, second.Components["db"].Cached)
+	}
+
+	now = now.Add(11 * time.Second)
+	checker.Run(context.Background(), []Component{comp})
+	if calls.Load() != 2 {
+		t.Errorf("check ran %d times after the TTL, want 2", calls.Load())
+	}
+}
+
+func TestRunTimesOutSlowChecks(t *testing.T) {
+	comp := Component{Name: "invgate", Critical: true, Timeout: 10 * time.Millisecond, Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}}
+
+	report := NewChecker(time.Second, time.Minute).Run(context.Background(), []Component{comp})
+	r := report.Components["invgate"]
+	if report.Status != StatusDown || r.Error != "timed out after 10ms" {
+		t.Errorf("report = %+v, want invgate timed out", report)
+	}
+}
+
+type widget struct {
+	ID   string
+	Name string
+}
+
+func TestMissingColumns(t *testing.T) {
+	db := &gorm.DB{Config: &gorm.Config{NamingStrategy: schema.NamingStrategy{}}}
+	existing := map[string]bool{"widgets.id": true}
+
+	missing, err := missingColumns(db, existing, []interface{}{&widget{}})
+	if err != nil {
+		t.Fatalf("missingColumns: %v", err)
+	}
+	if len(missing) != 1 || missing[0] != "widgets.name" {
+		t.Errorf("missing = %v, want [widgets.name]", missing)
+	}
+}
+
+func TestReadyzHidesErrorDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	fail := Component{Name: "db", Critical: true, Check: func(context.Context) error {
+		return stdErrors.New("dial tcp 10.0.0.5:3306: connection refused")
+	}}
+	h := NewHandler(NewChecker(time.Second, time.Minute), []Component{fail}, nil, logger)
+
+	router := gin.New()
+	router.GET("/readyz", h.Readyz)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable || strings.Contains(rec.Body.String(), "10.0.0.5") {
+		t.Errorf("readyz = %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestTenantClientIsReused(t *testing.T) {
+	h := NewHandler(NewChecker(time.Second, time.Minute), nil, nil, logrus.New())
+	acme := &tenant.Tenant{ID: "t1", InvGateBaseURL: "https://acme.invgate.net", InvGateUsername: "api", InvGatePassword: "one"}
+
+	first := h.tenantClient(acme)
+	if h.tenantClient(acme) != first {
+		t.Error("client rebuilt for unchanged credentials")
+	}
+	acme.InvGatePassword = "two"
+	if h.tenantClient(acme) == first {
+		t.Error("client kept after the credentials changed")
+	}
+
+	h.forgetClients(nil)
+	if len(h.clients) != 0 {
+		t.Errorf("clients of removed tenants kept: %v", h.clients)
+	}
+}
@@ -0,0 +1,113 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/tenant"
+)
+
+// Database pings the connection pool.
+func Database(db *sql.DB) Component {
+	return Component{
+		Name:     "database",
+		Critical: true,
+		Check:    db.PingContext,
+	}
+}
+
+// Schema verifies that every table and column the models map to exists,
+// i.e. that the database migrations are current with the code.
+func Schema(db *gorm.DB, models ...interface{}) Component {
+	return Component{
+		Name:     "migrations",
+		Critical: true,
+		Check: func(ctx context.Context) error {
+			rows, err := db.WithContext(ctx).Raw(
+				"SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = DATABASE()",
+			).Rows()
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			existing := make(map[string]bool)
+			for rows.Next() {
+				var table, column string
+				if err := rows.Scan(&table, &column); err != nil {
+					return err
+				}
+				existing[table+"."+column] = true
+			}
+			if err := rows.Err(); err != nil {
+				return err
+			}
+
+			missing, err := missingColumns(db, existing, models)
+			if err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("missing columns: %s", strings.Join(missing, ", "))
+			}
+			return nil
+		},
+	}
+}
+
+// missingColumns lists the model columns absent from existing, whose keys
+// are "table.column".
+func missingColumns(db *gorm.DB, existing map[string]bool, models []interface{}) ([]string, error) {
+	var missing []string
+	cache := &sync.Map{}
+	for _, model := range models {
+		s, err := schema.Parse(model, cache, db.NamingStrategy)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range s.DBNames {
+			if !existing[s.Table+"."+name] {
+				missing = append(missing, s.Table+"."+name)
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
+
+// Email checks the email transport configuration without sending mail.
+func Email(mailer interface{ CheckConfig() error }) Component {
+	return Component{
+		Name: "email",
+		Check: func(context.Context) error {
+			return mailer.CheckConfig()
+		},
+	}
+}
+
+// InvGate probes an InvGate account under the given component name. Probes
+// are slower and cached longer than the other checks so readiness polling
+// does not add noticeable load on InvGate.
+func InvGate(name string, client invgate.Service) Component {
+	return Component{
+		Name:    name,
+		Timeout: constants.InvGateProbeTimeout,
+		TTL:     constants.InvGateProbeCacheTTL,
+		Check:   client.Ping,
+	}
+}
+
+// TenantInvGate probes the InvGate account configured on a tenant through
+// client, which must use the tenant's credentials.
+func TenantInvGate(t *tenant.Tenant, client invgate.Service) Component {
+	return InvGate("invgate:"+t.Slug, client)
+}
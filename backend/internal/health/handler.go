@@ -0,0 +1,135 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/config"
+	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/tenant"
+)
+
+// Handler serves the liveness and readiness probes.
+type Handler struct {
+	checker    *Checker
+	components []Component
+	// tenants is set when each tenant's InvGate account is probed.
+	tenants tenant.Repository
+	logger  *logrus.Logger
+
+	// clients caches the InvGate client of each probed tenant by tenant ID,
+	// so a client and its session are not rebuilt on every probe.
+	mu      sync.Mutex
+	clients map[string]*tenantClient
+}
+
+// tenantClient is an InvGate client and the credentials it was built with.
+type tenantClient struct {
+	baseURL  string
+	username string
+	password string
+	service  invgate.Service
+}
+
+// NewHandler creates a health handler checking components. When tenants is
+// not nil, readiness also probes the InvGate account of every active tenant.
+func NewHandler(checker *Checker, components []Component, tenants tenant.Repository, logger *logrus.Logger) *Handler {
+	return &Handler{checker: checker, components: components, tenants: tenants, logger: logger}
+}
+
+// Livez handles GET /livez. It only tells that the process serves requests;
+// dependencies are left to Readyz so an outage does not restart the server.
+func (h *Handler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusOK})
+}
+
+// Readyz handles GET /readyz. It responds 503 when a critical component is
+// down and 200 otherwise, with each component's status in the body. Failure
+// details are only logged.
+func (h *Handler) Readyz(c *gin.Context) {
+	report := h.checker.Run(c.Request.Context(), h.readinessComponents(c.Request.Context()))
+	for name, r := range report.Components {
+		// Cached failures were logged when they were checked.
+		if r.Status != StatusOK && !r.Cached {
+			h.logger.WithFields(logrus.Fields{"component": name, "critical": r.Critical, "error": r.Error}).
+				Warn("readiness check failed")
+		}
+	}
+
+	status := http.StatusOK
+	if report.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// readinessComponents adds a probe per active tenant to the configured
+// components. The tenant list comes from the database, so failing to load
+// it is reported as its own component.
+func (h *Handler) readinessComponents(ctx context.Context) []Component {
+	if h.tenants == nil {
+		return h.components
+	}
+
+	components := append([]Component(nil), h.components...)
+	tenants, err := h.tenants.FindAll(ctx)
+	if err != nil {
+		return append(components, Component{
+			Name:  "invgate:tenants",
+			Check: func(context.Context) error { return err },
+			TTL:   -1, // re-list the tenants on the next probe
+		})
+	}
+	for _, t := range tenants {
+		components = append(components, TenantInvGate(t, h.tenantClient(t)))
+	}
+	h.forgetClients(tenants)
+	h.checker.Forget(components)
+	return components
+}
+
+// tenantClient returns the cached InvGate client of a tenant, building a
+// new one when there is none or the tenant's credentials changed.
+func (h *Handler) tenantClient(t *tenant.Tenant) invgate.Service {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if c := h.clients[t.ID]; c != nil && c.baseURL == t.InvGateBaseURL &&
+		c.username == t.InvGateUsername && c.password == t.InvGatePassword {
+		return c.service
+	}
+	if h.clients == nil {
+		h.clients = make(map[string]*tenantClient)
+	}
+	c := &tenantClient{
+		baseURL:  t.InvGateBaseURL,
+		username: t.InvGateUsername,
+		password: t.InvGatePassword,
+		service: invgate.NewService(&config.Config{
+			ArmMadaBaseURL:  t.InvGateBaseURL,
+			ArmMadaUsername: t.InvGateUsername,
+			ArmMadaPassword: t.InvGatePassword,
+		}),
+	}
+	h.clients[t.ID] = c
+	return c.service
+}
+
+// forgetClients drops the clients of tenants no longer listed.
+func (h *Handler) forgetClients(keep []*tenant.Tenant) {
+	ids := make(map[string]bool, len(keep))
+	for _, t := range keep {
+		ids[t.ID] = true
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id := range h.clients {
+		if !ids[id] {
+			delete(h.clients, id)
+		}
+	}
+}
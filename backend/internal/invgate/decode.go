@@ -0,0 +1,236 @@
+package invgate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fields is a loosely typed InvGate object. InvGate is inconsistent about
+// JSON types (ids arrive as numbers or strings, booleans as 0/1, timestamps
+// as numbers or {"value": n} objects), so models decode through these
+// accessors instead of relying on encoding/json struct tags.
+type fields map[string]interface{}
+
+// Int returns the first key that holds something convertible to an int.
+func (f fields) Int(keys ...string) int {
+	for _, key := range keys {
+		if v, ok := toInt(f[key]); ok {
+			return v
+		}
+	}
+	return 0
+}
+
+// Int64 returns the first key that holds something convertible to an int64.
+func (f fields) Int64(keys ...string) int64 {
+	for _, key := range keys {
+		if v, ok := toInt64(f[key]); ok {
+			return v
+		}
+	}
+	return 0
+}
+
+// Float returns the first key that holds a numeric value.
+func (f fields) Float(keys ...string) float64 {
+	for _, key := range keys {
+		switch v := f[key].(type) {
+		case float64:
+			return v
+		case string:
+			if parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return parsed
+			}
+		}
+	}
+	return 0
+}
+
+// String returns the first non-empty string (numbers are formatted).
+func (f fields) String(keys ...string) string {
+	for _, key := range keys {
+		switch v := f[key].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+// Bool accepts true/false, 0/1 and "0"/"1"/"true"/"false".
+func (f fields) Bool(keys ...string) bool {
+	if v := f.OptionalBool(keys...); v != nil {
+		return *v
+	}
+	return false
+}
+
+// OptionalBool is like Bool but returns nil when no key holds a boolean.
+func (f fields) OptionalBool(keys ...string) *bool {
+	for _, key := range keys {
+		switch v := f[key].(type) {
+		case bool:
+			return &v
+		case float64:
+			b := v != 0
+			return &b
+		case string:
+			if parsed, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return &parsed
+			}
+		}
+	}
+	return nil
+}
+
+// Object returns a nested object, or nil when the key is absent or not an object.
+func (f fields) Object(key string) fields {
+	if m, ok := f[key].(map[string]interface{}); ok {
+		return fields(m)
+	}
+	return nil
+}
+
+// List returns a nested array, or nil when the key is absent or not an array.
+func (f fields) List(key string) []interface{} {
+	if arr, ok := f[key].([]interface{}); ok {
+		return arr
+	}
+	return nil
+}
+
+// Timestamp reads a UNIX timestamp that may be a plain number, a numeric
+// string or an object of the form {"value": n} (incidents.details.by.view).
+func (f fields) Timestamp(keys ...string) int64 {
+	for _, key := range keys {
+		if nested := f.Object(key); nested != nil {
+			if v, ok := toInt64(nested["value"]); ok {
+				return v
+			}
+			continue
+		}
+		if v, ok := toInt64(f[key]); ok {
+			return v
+		}
+	}
+	return 0
+}
+
+// OptionalTimestamp is like Timestamp but returns nil when no key is set.
+func (f fields) OptionalTimestamp(keys ...string) *int64 {
+	if ts := f.Timestamp(keys...); ts > 0 {
+		return &ts
+	}
+	return nil
+}
+
+// NestedID reads the "id" of a nested object, e.g. {"status": {"id": 2}}.
+func (f fields) NestedID(key string) int {
+	if nested := f.Object(key); nested != nil {
+		return nested.Int("id")
+	}
+	return 0
+}
+
+// IDs reads an array of ids that InvGate may send either as numbers or as
+// objects carrying an "id" key.
+func (f fields) IDs(key string) []int {
+	var ids []int
+	for _, item := range f.List(key) {
+		if m, ok := item.(map[string]interface{}); ok {
+			if id := fields(m).Int("id"); id > 0 {
+				ids = append(ids, id)
+			}
+			continue
+		}
+		if id, ok := toInt(item); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func toInt(v interface{}) (int, bool) {
+	i, ok := toInt64(v)
+	return int(i), ok
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return int64(val), true
+	case json.Number:
+		i, err := val.Int64()
+		return i, err == nil
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		return i, err == nil
+	}
+	return 0, false
+}
+
+// decodeObject decodes an InvGate object response into out, unwrapping a
+// {"data": {...}} envelope when present.
+func decodeObject(data []byte, out interface{}) error {
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &envelope); err == nil && len(envelope.Data) > 0 && envelope.Data[0] == '{' {
+		data = envelope.Data
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode ArmMada response: %w", err)
+	}
+	return nil
+}
+
+// decodeList decodes an InvGate collection into out (a pointer to a slice).
+// Depending on the endpoint the collection is a bare array, an array under
+// "data", or an array under some endpoint specific key.
+func decodeList(data []byte, out interface{}) error {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return nil
+	}
+
+	if trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, out); err != nil {
+			return fmt.Errorf("failed to decode ArmMada response: %w", err)
+		}
+		return nil
+	}
+
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(trimmed, &obj); err != nil {
+		return fmt.Errorf("failed to decode ArmMada response: %w", err)
+	}
+
+	if raw, ok := obj["data"]; ok {
+		return decodeList(raw, out)
+	}
+	for _, raw := range obj {
+		raw = bytes.TrimSpace(raw)
+		if len(raw) > 0 && raw[0] == '[' {
+			return decodeList(raw, out)
+		}
+	}
+
+	// An object without any array (e.g. {"status": "OK"}) is an empty result.
+	return nil
+}
+
+// decodeResult decodes the acknowledgement returned by InvGate write endpoints.
+func decodeResult(data []byte) (*Result, error) {
+	var result Result
+	if err := decodeObject(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,231 @@
+package invgate
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"werk-ticketing/internal/errors"
+)
+
+// APIError is returned when InvGate answers with a non-2xx status code.
+// It keeps the raw error body so callers can log it and decide whether the
+// request is worth retrying.
+type APIError struct {
+	StatusCode int
+	Endpoint   string
+	Message    string        // Error message extracted from the InvGate body, if any
+	Body       string        // Raw response body as returned by InvGate
+	RetryAfter time.Duration // Parsed Retry-After header (0 when absent)
+
+	// retryAfterSent is set when InvGate sent a Retry-After header, even one
+	// asking for no delay.
+	retryAfterSent bool
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Body
+	}
+	return fmt.Sprintf("armmada error (status %d, %s): %s", e.StatusCode, e.Endpoint, msg)
+}
+
+// Retryable reports whether the failed request may succeed when sent again.
+// Only throttling and transient gateway/server failures qualify; validation
+// and authorization errors are returned to the caller immediately.
+func (e *APIError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// newAPIError builds an APIError from an InvGate error response.
+func newAPIError(endpoint string, resp *http.Response, body []byte) *APIError {
+	return &APIError{
+		StatusCode:     resp.StatusCode,
+		Endpoint:       endpoint,
+		Message:        extractErrorMessage(body),
+		Body:           string(body),
+		RetryAfter:     parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		retryAfterSent: resp.Header.Get("Retry-After") != "",
+	}
+}
+
+// extractErrorMessage pulls a human readable message out of an InvGate error body.
+// InvGate is not consistent here: depending on the endpoint it uses "error",
+// "message" or "errors" (either a string or a list).
+func extractErrorMessage(body []byte) string {
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+
+	for _, key := range []string{"error", "message", "errors"} {
+		switch v := decoded[key].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case []interface{}:
+			parts := make([]string, 0, len(v))
+			for _, item := range v {
+				parts = append(parts, fmt.Sprintf("%v", item))
+			}
+			if len(parts) > 0 {
+				return strings.Join(parts, "; ")
+			}
+		case map[string]interface{}:
+			parts := make([]string, 0, len(v))
+			for field, detail := range v {
+				parts = append(parts, fmt.Sprintf("%s: %v", field, detail))
+			}
+			if len(parts) > 0 {
+				return strings.Join(parts, "; ")
+			}
+		}
+	}
+
+	return strings.TrimSpace(string(body))
+}
+
+// parseRetryAfter parses a Retry-After header value, which is either a number
+// of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// isRetryableError decides whether a failed InvGate call should be attempted again.
+func isRetryableError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if stdErrors.Is(err, context.Canceled) || stdErrors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr *APIError
+	if stdErrors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	// Transport level failures (connection refused/reset, timeouts, DNS) are
+	// wrapped in *url.Error by http.Client and are worth retrying.
+	var urlErr *url.Error
+	if stdErrors.As(err, &urlErr) {
+		return true
+	}
+	var netErr net.Error
+	return stdErrors.As(err, &netErr)
+}
+
+// unsentError marks a transport failure that happened before a connection
+// to InvGate was obtained, so no part of the request reached it.
+type unsentError struct {
+	err error
+}
+
+func (e *unsentError) Error() string { return e.err.Error() }
+func (e *unsentError) Unwrap() error { return e.err }
+
+// isResendableError decides whether a failed non-idempotent call may be sent
+// again without risking a duplicate in InvGate: either the request never
+// left the portal, or InvGate refused it with 429/503 and a Retry-After,
+// which says it was not processed.
+func isResendableError(err error) bool {
+	if !isRetryableError(err) {
+		return false
+	}
+
+	var unsent *unsentError
+	if stdErrors.As(err, &unsent) {
+		return true
+	}
+
+	var apiErr *APIError
+	if stdErrors.As(err, &apiErr) {
+		return apiErr.retryAfterSent && (apiErr.StatusCode == http.StatusTooManyRequests ||
+			apiErr.StatusCode == http.StatusServiceUnavailable)
+	}
+	return false
+}
+
+// IsNotFound reports whether err is an InvGate 404 response.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return stdErrors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// AsAppError converts an InvGate client error into an application error.
+// InvGate 4xx responses keep their meaning (404 becomes NOT_FOUND, 400/422
+// become INVALID_INPUT, ...) while everything else is reported as an
+// external service failure.
+func AsAppError(err error, message string) *errors.AppError {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *errors.AppError
+	if stdErrors.As(err, &appErr) {
+		return appErr
+	}
+
+	var apiErr *APIError
+	if !stdErrors.As(err, &apiErr) {
+		return errors.NewAppError(errors.ErrCodeExternalService, message, err)
+	}
+
+	detailed := message
+	if apiErr.Message != "" {
+		detailed = fmt.Sprintf("%s: %s", message, apiErr.Message)
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusNotFound:
+		return errors.NewAppError(errors.ErrCodeNotFound, detailed, err)
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return errors.NewAppError(errors.ErrCodeInvalidInput, detailed, err)
+	case http.StatusConflict:
+		return errors.NewAppError(errors.ErrCodeConflict, detailed, err)
+	case http.StatusTooManyRequests:
+		return errors.NewAppError(errors.ErrCodeTooManyRequests, message, err)
+	default:
+		// 401/403 mean our credentials for InvGate are wrong, which is not
+		// something the portal user can fix: report it as an upstream failure.
+		return errors.NewAppError(errors.ErrCodeExternalService, message, err)
+	}
+}
@@ -0,0 +1,600 @@
+package invgatetest
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]interface{}{"error": message})
+}
+
+// form reads request parameters from a JSON or multipart body. Files sent as
+// "attachments[]" are returned separately.
+func form(r *http.Request) (map[string]string, []Attachment, error) {
+	values := make(map[string]string)
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch contentType {
+	case "multipart/form-data":
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			return nil, nil, err
+		}
+		for key, v := range r.MultipartForm.Value {
+			if len(v) > 0 {
+				values[key] = v[0]
+			}
+		}
+		var files []Attachment
+		for _, fh := range r.MultipartForm.File["attachments[]"] {
+			f, err := fh.Open()
+			if err != nil {
+				return nil, nil, err
+			}
+			data, err := io.ReadAll(f)
+			f.Close()
+			if err != nil {
+				return nil, nil, err
+			}
+			files = append(files, Attachment{
+				Name:        fh.Filename,
+				ContentType: fh.Header.Get("Content-Type"),
+				Data:        data,
+			})
+		}
+		return values, files, nil
+	default:
+		var decoded map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&decoded); err != nil && err != io.EOF {
+			return nil, nil, err
+		}
+		for key, v := range decoded {
+			switch val := v.(type) {
+			case string:
+				values[key] = val
+			case float64:
+				values[key] = strconv.FormatFloat(val, 'f', -1, 64)
+			case bool:
+				values[key] = strconv.FormatBool(val)
+			default:
+				encoded, _ := json.Marshal(val)
+				values[key] = string(encoded)
+			}
+		}
+		return values, nil, nil
+	}
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func (s *Server) storeFiles(files []Attachment) []int {
+	ids := make([]int, 0, len(files))
+	for _, f := range files {
+		f.ID = s.id()
+		f.ModTime = s.now().UTC().Truncate(time.Second)
+		stored := f
+		s.attachments[f.ID] = &stored
+		ids = append(ids, f.ID)
+	}
+	return ids
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) {
+	values, _, err := form(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, field := range []string{"name", "lastname", "email", "pass"} {
+		if values[field] == "" {
+			writeError(w, http.StatusBadRequest, field+" is required")
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, u := range s.users {
+		if strings.EqualFold(u.Email, values["email"]) {
+			writeError(w, http.StatusConflict, "email already in use")
+			return
+		}
+	}
+	u := &User{
+		ID:       s.id(),
+		Name:     values["name"],
+		LastName: values["lastname"],
+		Email:    values["email"],
+		Pass:     values["pass"],
+		Type:     1,
+	}
+	s.users[u.ID] = u
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "OK", "id": u.ID})
+}
+
+func userJSON(u *User) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          u.ID,
+		"name":        u.Name,
+		"lastname":    u.LastName,
+		"email":       u.Email,
+		"username":    u.Email,
+		"type":        u.Type,
+		"is_disabled": false,
+	}
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[atoi(r.URL.Query().Get("id"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, userJSON(u))
+}
+
+func (s *Server) deleteUser(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := atoi(r.URL.Query().Get("id"))
+	if _, ok := s.users[id]; !ok {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	delete(s.users, id)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "OK"})
+}
+
+func (s *Server) getUserByEmail(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	email := r.URL.Query().Get("email")
+	for _, u := range s.users {
+		if strings.EqualFold(u.Email, email) {
+			writeJSON(w, http.StatusOK, userJSON(u))
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "user not found")
+}
+
+func (s *Server) assign(endpoint string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			ID    int   `json:"id"`
+			Users []int `json:"users"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil || payload.ID == 0 {
+			writeError(w, http.StatusBadRequest, "id and users are required")
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, id := range payload.Users {
+			if _, ok := s.users[id]; !ok {
+				writeError(w, http.StatusNotFound, fmt.Sprintf("user %d not found", id))
+				return
+			}
+		}
+		if s.assignments[endpoint] == nil {
+			s.assignments[endpoint] = make(map[int][]int)
+		}
+		s.assignments[endpoint][payload.ID] = append(s.assignments[endpoint][payload.ID], payload.Users...)
+		writeJSON(w, http.StatusOK, map[string]interface{}{"status": "OK"})
+	}
+}
+
+func (s *Server) createIncident(w http.ResponseWriter, r *http.Request) {
+	values, files, err := form(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if values["title"] == "" || values["creator_id"] == "" {
+		writeError(w, http.StatusBadRequest, "title and creator_id are required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[atoi(values["creator_id"])]; !ok {
+		writeError(w, http.StatusUnprocessableEntity, "creator_id does not exist")
+		return
+	}
+	now := s.now().Unix()
+	inc := &Incident{
+		ID:          s.id(),
+		Title:       values["title"],
+		Description: values["description"],
+		StatusID:    StatusNew,
+		PriorityID:  atoi(values["priority_id"]),
+		CategoryID:  atoi(values["category_id"]),
+		TypeID:      atoi(values["type_id"]),
+		SourceID:    atoi(values["source_id"]),
+		CreatorID:   atoi(values["creator_id"]),
+		CustomerID:  atoi(values["customer_id"]),
+		Date:        int64(atoi(values["date"])),
+		CreatedAt:   now,
+		LastUpdate:  now,
+		Attachments: s.storeFiles(files),
+	}
+	s.incidents[inc.ID] = inc
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "OK", "request_id": inc.ID})
+}
+
+func (s *Server) updateIncident(w http.ResponseWriter, r *http.Request) {
+	values, _, err := form(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inc, ok := s.incidents[atoi(values["id"])]
+	if !ok {
+		writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+	if v, ok := values["title"]; ok {
+		inc.Title = v
+	}
+	if v, ok := values["description"]; ok {
+		inc.Description = v
+	}
+	for key, field := range map[string]*int{
+		"priority_id": &inc.PriorityID,
+		"category_id": &inc.CategoryID,
+		"type_id":     &inc.TypeID,
+		"source_id":   &inc.SourceID,
+		"creator_id":  &inc.CreatorID,
+		"customer_id": &inc.CustomerID,
+	} {
+		if v, ok := values[key]; ok {
+			*field = atoi(v)
+		}
+	}
+	inc.LastUpdate = s.now().Unix()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "OK", "info": "incident updated"})
+}
+
+func incidentJSON(inc *Incident) map[string]interface{} {
+	var closedAt interface{}
+	if inc.ClosedAt > 0 {
+		closedAt = inc.ClosedAt
+	}
+	return map[string]interface{}{
+		"id":           inc.ID,
+		"title":        inc.Title,
+		"description":  inc.Description,
+		"status_id":    inc.StatusID,
+		"priority_id":  inc.PriorityID,
+		"category_id":  inc.CategoryID,
+		"type_id":      inc.TypeID,
+		"source_id":    inc.SourceID,
+		"creator_id":   inc.CreatorID,
+		"user_id":      inc.CustomerID,
+		"date_ocurred": inc.Date,
+		"created_at":   inc.CreatedAt,
+		"last_update":  inc.LastUpdate,
+		"closed_at":    closedAt,
+		"solved_at":    closedAt,
+		"rating":       inc.Rating,
+		"attachments":  inc.Attachments,
+	}
+}
+
+// viewJSON renders the nested shape returned by incidents.details.by.view.
+func viewJSON(inc *Incident) map[string]interface{} {
+	var closingDate interface{}
+	if inc.ClosedAt > 0 {
+		closingDate = map[string]interface{}{"value": inc.ClosedAt}
+	}
+	return map[string]interface{}{
+		"id": inc.ID,
+		"request": map[string]interface{}{
+			"subject":  inc.Title,
+			"category": map[string]interface{}{"id": inc.CategoryID},
+			"type":     map[string]interface{}{"id": inc.TypeID},
+		},
+		"description":   inc.Description,
+		"status":        map[string]interface{}{"id": inc.StatusID, "label": statusLabel(inc.StatusID)},
+		"priority":      map[string]interface{}{"id": inc.PriorityID},
+		"source":        map[string]interface{}{"id": inc.SourceID},
+		"creator":       inc.CreatorID,
+		"customer":      inc.CustomerID,
+		"creation_date": map[string]interface{}{"value": inc.CreatedAt},
+		"last_update":   map[string]interface{}{"value": inc.LastUpdate},
+		"closing_date":  closingDate,
+		"rating":        inc.Rating,
+	}
+}
+
+func statusLabel(id int) string {
+	switch id {
+	case StatusNew:
+		return "New"
+	case StatusOpen:
+		return "Open"
+	case StatusResolved:
+		return "Resolved"
+	case StatusClosed:
+		return "Closed"
+	default:
+		return ""
+	}
+}
+
+func (s *Server) getIncident(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inc, ok := s.incidents[atoi(r.URL.Query().Get("id"))]
+	if !ok {
+		writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, incidentJSON(inc))
+}
+
+// sortedIncidents returns incidents ordered by id. Must be called with s.mu held.
+func (s *Server) sortedIncidents() []*Incident {
+	out := make([]*Incident, 0, len(s.incidents))
+	for _, inc := range s.incidents {
+		out = append(out, inc)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+func (s *Server) listIncidents(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := make([]interface{}, 0, len(s.incidents))
+	for _, inc := range s.sortedIncidents() {
+		data = append(data, incidentJSON(inc))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
+
+func (s *Server) listIncidentsByView(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("view_id") == "" {
+		writeError(w, http.StatusBadRequest, "view_id is required")
+		return
+	}
+	creatorID := atoi(r.URL.Query().Get("creator_id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := make([]interface{}, 0, len(s.incidents))
+	for _, inc := range s.sortedIncidents() {
+		if creatorID > 0 && inc.CreatorID != creatorID {
+			continue
+		}
+		data = append(data, viewJSON(inc))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data, "next_page_key": nil})
+}
+
+func (s *Server) acceptSolution(w http.ResponseWriter, r *http.Request) {
+	values, _, err := form(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// The rating is optional, but must be valid when sent.
+	rating, rated := values["rating"]
+	if rated && (atoi(rating) < 1 || atoi(rating) > 5) {
+		writeError(w, http.StatusBadRequest, "rating must be between 1 and 5")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inc, ok := s.incidents[atoi(values["id"])]
+	if !ok {
+		writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+	inc.StatusID = StatusClosed
+	inc.Rating = atoi(rating)
+	inc.ClosedAt = s.now().Unix()
+	inc.LastUpdate = inc.ClosedAt
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "OK"})
+}
+
+func (s *Server) rejectSolution(w http.ResponseWriter, r *http.Request) {
+	values, _, err := form(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if values["comment"] == "" {
+		writeError(w, http.StatusBadRequest, "comment is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inc, ok := s.incidents[atoi(values["id"])]
+	if !ok {
+		writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+	inc.StatusID = StatusOpen
+	inc.LastUpdate = s.now().Unix()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "OK"})
+}
+
+func (s *Server) createComment(w http.ResponseWriter, r *http.Request) {
+	values, files, err := form(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if values["comment"] == "" {
+		writeError(w, http.StatusBadRequest, "comment is required")
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	incidentID := atoi(values["request_id"])
+	if _, ok := s.incidents[incidentID]; !ok {
+		writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+	c := &Comment{
+		ID:          s.id(),
+		IncidentID:  incidentID,
+		AuthorID:    atoi(values["author_id"]),
+		Comment:     values["comment"],
+		CreatedAt:   s.now().Unix(),
+		Attachments: s.storeFiles(files),
+	}
+	s.comments[incidentID] = append(s.comments[incidentID], c)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "OK", "comment_id": c.ID})
+}
+
+func (s *Server) listComments(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	incidentID := atoi(r.URL.Query().Get("request_id"))
+	if _, ok := s.incidents[incidentID]; !ok {
+		writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+	data := make([]interface{}, 0, len(s.comments[incidentID]))
+	for i, c := range s.comments[incidentID] {
+		attachments := c.Attachments
+		if attachments == nil {
+			attachments = []int{}
+		}
+		data = append(data, map[string]interface{}{
+			"id":               c.ID,
+			"incident_id":      c.IncidentID,
+			"author_id":        c.AuthorID,
+			"comment":          c.Comment,
+			"created_at":       c.CreatedAt,
+			"customer_visible": 1,
+			"is_solution":      c.IsSolution,
+			"msg_num":          i + 1,
+			"reference":        nil,
+			"attached_files":   attachments,
+		})
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (s *Server) getAttachment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	a, ok := s.attachments[atoi(r.URL.Query().Get("id"))]
+	var stored Attachment
+	if ok {
+		stored = *a
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "attachment not found")
+		return
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), "application/json") {
+		sum := sha1.Sum(stored.Data)
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":        stored.ID,
+			"name":      stored.Name,
+			"url":       fmt.Sprintf("%sincident.attachment?id=%d", s.URL(), stored.ID),
+			"hash":      hex.EncodeToString(sum[:]),
+			"extension": strings.TrimPrefix(filepath.Ext(stored.Name), "."),
+		})
+		return
+	}
+
+	contentType := stored.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	sum := sha1.Sum(stored.Data)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": stored.Name}))
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:])+`"`)
+
+	s.mu.Lock()
+	noRanges := s.noRanges
+	s.mu.Unlock()
+	if noRanges {
+		r.Header.Del("Range")
+		w.Header().Set("Content-Length", strconv.Itoa(len(stored.Data)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(stored.Data)
+		return
+	}
+	http.ServeContent(w, r, stored.Name, stored.ModTime, bytes.NewReader(stored.Data))
+}
+
+func (s *Server) listCategories(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := make([]interface{}, 0, len(s.categories))
+	for _, c := range s.categories {
+		data = append(data, map[string]interface{}{
+			"id":                 c.ID,
+			"name":               c.Name,
+			"parent_category_id": c.ParentID,
+		})
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (s *Server) listArticles(w http.ResponseWriter, r *http.Request) {
+	categoryID := atoi(r.URL.Query().Get("category_id"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data := make([]interface{}, 0)
+	for _, a := range s.articles {
+		if a.CategoryID != categoryID {
+			continue
+		}
+		data = append(data, map[string]interface{}{
+			"id":               a.ID,
+			"title":            a.Title,
+			"content":          a.Content,
+			"category_id":      a.CategoryID,
+			"author_id":        a.AuthorID,
+			"responsible_id":   a.AuthorID,
+			"creation_date":    s.now().Unix(),
+			"last_update_date": s.now().Unix(),
+			"solved_requests":  "0",
+			"views":            a.Views,
+			"is_private":       false,
+			"rating":           "4.5",
+			"attachments":      []interface{}{},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
@@ -0,0 +1,245 @@
+// Package invgatetest provides an in-process fake of the InvGate (Armmada)
+// API for tests. It implements the endpoints used by invgate.Service on top
+// of an in-memory store and lets tests script failures per endpoint.
+package invgatetest
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"werk-ticketing/internal/config"
+)
+
+// Default credentials accepted by the fake server.
+const (
+	Username = "invgate-test"
+	Password = "invgate-secret"
+)
+
+// Server is a fake InvGate API backed by an in-memory store.
+type Server struct {
+	srv *httptest.Server
+
+	mu          sync.Mutex
+	nextID      int
+	now         func() time.Time
+	users       map[int]*User
+	incidents   map[int]*Incident
+	comments    map[int][]*Comment
+	attachments map[int]*Attachment
+	categories  []Category
+	articles    []Article
+	assignments map[string]map[int][]int
+	failures    []*Failure
+	requests    []Request
+	noRanges    bool
+}
+
+// Request records a call received by the fake server.
+type Request struct {
+	Method   string
+	Endpoint string
+	Query    string
+	Header   http.Header
+	// ContentLength is -1 when the body was sent chunked.
+	ContentLength int64
+	// Body is kept for JSON requests only.
+	Body []byte
+}
+
+// Failure scripts an error response. Method and Endpoint select the calls it
+// applies to (an empty Method matches any method). Times limits how many
+// calls fail; zero means every matching call fails until Reset is called.
+type Failure struct {
+	Method     string
+	Endpoint   string
+	Status     int
+	Body       string
+	RetryAfter string
+	Times      int
+
+	hits int
+}
+
+// NewServer starts a fake InvGate server. Call Close when done.
+func NewServer() *Server {
+	s := &Server{
+		nextID:      1000,
+		now:         time.Now,
+		users:       make(map[int]*User),
+		incidents:   make(map[int]*Incident),
+		comments:    make(map[int][]*Comment),
+		attachments: make(map[int]*Attachment),
+		assignments: make(map[string]map[int][]int),
+	}
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close shuts the server down.
+func (s *Server) Close() {
+	s.srv.Close()
+}
+
+// URL returns the API base URL in the form expected by ArmMadaBaseURL.
+func (s *Server) URL() string {
+	return s.srv.URL + "/"
+}
+
+// Config returns an application config pointing at the fake server.
+func (s *Server) Config() *config.Config {
+	return &config.Config{
+		ArmMadaBaseURL:  s.URL(),
+		ArmMadaUsername: Username,
+		ArmMadaPassword: Password,
+	}
+}
+
+// Fail registers a scripted failure.
+func (s *Server) Fail(f Failure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f.Status == 0 {
+		f.Status = http.StatusInternalServerError
+	}
+	s.failures = append(s.failures, &f)
+}
+
+// DisableRanges makes attachment downloads ignore Range and conditional
+// headers, like InvGate deployments that always send the full file.
+func (s *Server) DisableRanges() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.noRanges = true
+}
+
+// Reset removes all scripted failures and clears the request log.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = nil
+	s.requests = nil
+}
+
+// Requests returns a copy of the request log.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Calls counts the requests received for method and endpoint.
+func (s *Server) Calls(method, endpoint string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, r := range s.requests {
+		if r.Method == method && r.Endpoint == endpoint {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	endpoint := strings.TrimPrefix(r.URL.Path, "/")
+
+	var body []byte
+	if contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); contentType == "application/json" {
+		body, _ = io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Method:   r.Method,
+		Endpoint: endpoint,
+		Query:    r.URL.RawQuery,
+		Header:   r.Header.Clone(),
+		Body:     body,
+
+		ContentLength: r.ContentLength,
+	})
+	failure := s.matchFailure(r.Method, endpoint)
+	s.mu.Unlock()
+
+	if failure != nil {
+		if failure.RetryAfter != "" {
+			w.Header().Set("Retry-After", failure.RetryAfter)
+		}
+		body := failure.Body
+		if body == "" {
+			body = `{"error":"` + http.StatusText(failure.Status) + `"}`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(failure.Status)
+		_, _ = w.Write([]byte(body))
+		return
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok || username != Username || password != Password {
+		writeError(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+
+	handler, ok := s.route(r.Method, endpoint)
+	if !ok {
+		writeError(w, http.StatusNotFound, "unknown endpoint "+r.Method+" "+endpoint)
+		return
+	}
+	handler(w, r)
+}
+
+// matchFailure must be called with s.mu held.
+func (s *Server) matchFailure(method, endpoint string) *Failure {
+	for _, f := range s.failures {
+		if f.Endpoint != endpoint || (f.Method != "" && f.Method != method) {
+			continue
+		}
+		if f.Times > 0 && f.hits >= f.Times {
+			continue
+		}
+		f.hits++
+		return f
+	}
+	return nil
+}
+
+func (s *Server) route(method, endpoint string) (http.HandlerFunc, bool) {
+	routes := map[string]http.HandlerFunc{
+		"POST user":                     s.createUser,
+		"GET user":                      s.getUser,
+		"DELETE user":                   s.deleteUser,
+		"GET user.by":                   s.getUserByEmail,
+		"POST incident":                 s.createIncident,
+		"PUT incident":                  s.updateIncident,
+		"GET incident":                  s.getIncident,
+		"GET incidents":                 s.listIncidents,
+		"GET incidents.details.by.view": s.listIncidentsByView,
+		"PUT incident.solution.accept":  s.acceptSolution,
+		"PUT incident.solution.reject":  s.rejectSolution,
+		"POST incident.comment":         s.createComment,
+		"GET incident.comment":          s.listComments,
+		"GET incident.attachment":       s.getAttachment,
+		"POST companies.users":          s.assign("companies.users"),
+		"POST groups.users":             s.assign("groups.users"),
+		"POST locations.users":          s.assign("locations.users"),
+		"GET categories":                s.listCategories,
+		"GET kb.articles.by.category":   s.listArticles,
+	}
+	handler, ok := routes[method+" "+endpoint]
+	return handler, ok
+}
+
+// id allocates a new identifier. Must be called with s.mu held.
+func (s *Server) id() int {
+	s.nextID++
+	return s.nextID
+}
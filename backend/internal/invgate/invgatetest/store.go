@@ -0,0 +1,227 @@
+package invgatetest
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// User is a user stored by the fake server.
+type User struct {
+	ID       int
+	Name     string
+	LastName string
+	Email    string
+	Pass     string
+	Type     int
+}
+
+// Incident is a ticket stored by the fake server.
+type Incident struct {
+	ID          int
+	Title       string
+	Description string
+	StatusID    int
+	PriorityID  int
+	CategoryID  int
+	TypeID      int
+	SourceID    int
+	CreatorID   int
+	CustomerID  int
+	Date        int64
+	CreatedAt   int64
+	LastUpdate  int64
+	ClosedAt    int64
+	Rating      int
+	Attachments []int
+}
+
+// Comment is a comment stored by the fake server.
+type Comment struct {
+	ID          int
+	IncidentID  int
+	AuthorID    int
+	Comment     string
+	CreatedAt   int64
+	IsSolution  bool
+	Attachments []int
+}
+
+// Attachment is a file stored by the fake server.
+type Attachment struct {
+	ID          int
+	Name        string
+	ContentType string
+	Data        []byte
+	ModTime     time.Time
+}
+
+// Category is a request category.
+type Category struct {
+	ID       int
+	Name     string
+	ParentID int
+}
+
+// Article is a knowledge base article.
+type Article struct {
+	ID         int
+	CategoryID int
+	Title      string
+	Content    string
+	AuthorID   int
+	Views      int
+}
+
+// Status ids used by the fake server.
+const (
+	StatusNew      = 1
+	StatusOpen     = 2
+	StatusResolved = 5
+	StatusClosed   = 6
+)
+
+// AddUser stores u and returns its id (allocated when u.ID is zero).
+func (s *Server) AddUser(u User) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if u.ID == 0 {
+		u.ID = s.id()
+	}
+	s.users[u.ID] = &u
+	return u.ID
+}
+
+// User returns a copy of the stored user.
+func (s *Server) User(id int) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[id]
+	if !ok {
+		return User{}, false
+	}
+	return *u, true
+}
+
+// AddIncident stores inc and returns its id (allocated when inc.ID is zero).
+func (s *Server) AddIncident(inc Incident) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if inc.ID == 0 {
+		inc.ID = s.id()
+	}
+	if inc.StatusID == 0 {
+		inc.StatusID = StatusNew
+	}
+	if inc.CreatedAt == 0 {
+		inc.CreatedAt = s.now().Unix()
+	}
+	if inc.LastUpdate == 0 {
+		inc.LastUpdate = inc.CreatedAt
+	}
+	s.incidents[inc.ID] = &inc
+	return inc.ID
+}
+
+// Incident returns a copy of the stored incident.
+func (s *Server) Incident(id int) (Incident, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	inc, ok := s.incidents[id]
+	if !ok {
+		return Incident{}, false
+	}
+	out := *inc
+	out.Attachments = append([]int(nil), inc.Attachments...)
+	return out, true
+}
+
+// Comments returns copies of the comments stored for an incident.
+func (s *Server) Comments(incidentID int) []Comment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Comment, 0, len(s.comments[incidentID]))
+	for _, c := range s.comments[incidentID] {
+		out = append(out, *c)
+	}
+	return out
+}
+
+// AddAttachment stores a file and returns its id.
+func (s *Server) AddAttachment(a Attachment) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.ID == 0 {
+		a.ID = s.id()
+	}
+	if a.ModTime.IsZero() {
+		a.ModTime = s.now().UTC().Truncate(time.Second)
+	}
+	s.attachments[a.ID] = &a
+	return a.ID
+}
+
+// Attachment returns a copy of the stored file.
+func (s *Server) Attachment(id int) (Attachment, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.attachments[id]
+	if !ok {
+		return Attachment{}, false
+	}
+	return *a, true
+}
+
+// SetCategories replaces the category catalogue.
+func (s *Server) SetCategories(categories ...Category) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.categories = append([]Category(nil), categories...)
+}
+
+// AddArticle stores a knowledge base article and returns its id.
+func (s *Server) AddArticle(a Article) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a.ID == 0 {
+		a.ID = s.id()
+	}
+	s.articles = append(s.articles, a)
+	return a.ID
+}
+
+// Assigned returns the users assigned to an entity through endpoint
+// ("companies.users", "groups.users" or "locations.users").
+func (s *Server) Assigned(endpoint string, entityID int) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int(nil), s.assignments[endpoint][entityID]...)
+}
+
+// FileHeader builds a multipart.FileHeader holding content, as produced by
+// gin when parsing an upload.
+func FileHeader(tb testing.TB, name string, content []byte) *multipart.FileHeader {
+	tb.Helper()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", name)
+	if err != nil {
+		tb.Fatalf("create form file: %v", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		tb.Fatalf("write form file: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		tb.Fatalf("close multipart writer: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	if err := req.ParseMultipartForm(int64(len(content)) + 1024); err != nil {
+		tb.Fatalf("parse multipart form: %v", err)
+	}
+	return req.MultipartForm.File["file"][0]
+}
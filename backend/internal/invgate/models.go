@@ -0,0 +1,326 @@
+package invgate
+
+import "encoding/json"
+
+// Incident is an InvGate request/ticket. It decodes both the flat shape
+// returned by the "incident" endpoint and the nested shape returned by
+// "incidents.details.by.view".
+type Incident struct {
+	ID              int
+	PrettyID        string
+	Title           string
+	Description     string
+	StatusID        int
+	StatusLabel     string // Only present in view responses
+	PriorityID      int
+	CategoryID      int
+	TypeID          int
+	SourceID        int
+	CustomerID      int
+	CreatorID       int
+	AgentID         int
+	AssignedGroupID int
+	LocationID      int
+	Rating          int
+	CreatedAt       int64
+	LastUpdate      int64
+	DateOcurred     int64
+	ClosedAt        *int64
+	SolvedAt        *int64
+	ClosedReason    int
+	AttachmentIDs   []int
+	Collaborators   []int
+}
+
+// UnmarshalJSON implements tolerant decoding for Incident.
+func (i *Incident) UnmarshalJSON(data []byte) error {
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	request := f.Object("request")
+	if request == nil {
+		request = fields{}
+	}
+
+	*i = Incident{
+		ID:              f.Int("id", "request_id", "incident_id"),
+		PrettyID:        f.String("pretty_id"),
+		Title:           firstNonEmpty(f.String("title"), request.String("subject"), f.String("subject")),
+		Description:     f.String("description"),
+		StatusID:        firstNonZero(f.Int("status_id"), f.NestedID("status")),
+		PriorityID:      firstNonZero(f.Int("priority_id"), f.NestedID("priority")),
+		CategoryID:      firstNonZero(f.Int("category_id"), request.NestedID("category"), f.Int("category")),
+		TypeID:          firstNonZero(f.Int("type_id"), request.NestedID("type"), f.NestedID("type")),
+		SourceID:        firstNonZero(f.Int("source_id"), f.NestedID("source")),
+		CustomerID:      f.Int("user_id", "customer_id", "customer"),
+		CreatorID:       f.Int("creator_id", "creator"),
+		AgentID:         f.Int("assigned_id", "agent"),
+		AssignedGroupID: f.Int("assigned_group_id", "helpdesk"),
+		LocationID:      f.Int("location_id", "location"),
+		Rating:          f.Int("rating"),
+		CreatedAt:       f.Timestamp("created_at", "creation_date"),
+		LastUpdate:      f.Timestamp("last_update"),
+		DateOcurred:     f.Timestamp("date_ocurred", "date", "creation_date"),
+		ClosedAt:        f.OptionalTimestamp("closed_at", "closing_date"),
+		SolvedAt:        f.OptionalTimestamp("solved_at"), // views only carry the closing date
+		ClosedReason:    f.Int("closed_reason"),
+		AttachmentIDs:   f.IDs("attachments"),
+		Collaborators:   f.IDs("collaborators"),
+	}
+	if status := f.Object("status"); status != nil {
+		i.StatusLabel = status.String("label", "name")
+	}
+	return nil
+}
+
+// IncidentPage is one page of incidents from a list endpoint.
+type IncidentPage struct {
+	Incidents   []Incident
+	NextPageKey string
+}
+
+// UnmarshalJSON implements tolerant decoding for IncidentPage.
+func (p *IncidentPage) UnmarshalJSON(data []byte) error {
+	*p = IncidentPage{}
+
+	var f fields
+	if err := json.Unmarshal(data, &f); err == nil {
+		p.NextPageKey = f.String("next_page_key", "page_key")
+	}
+	return decodeList(data, &p.Incidents)
+}
+
+// Attachment is a file attached to an incident, comment or article.
+type Attachment struct {
+	ID        int
+	Name      string
+	URL       string
+	Hash      string
+	Extension string
+}
+
+// UnmarshalJSON implements tolerant decoding for Attachment. Some endpoints
+// reference attachments by bare id, which decodes into an Attachment with
+// only ID set.
+func (a *Attachment) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if id, ok := toInt(raw); ok {
+		*a = Attachment{ID: id}
+		return nil
+	}
+
+	m, _ := raw.(map[string]interface{})
+	f := fields(m)
+	*a = Attachment{
+		ID:        f.Int("id"),
+		Name:      f.String("name", "filename"),
+		URL:       f.String("url"),
+		Hash:      f.String("hash"),
+		Extension: f.String("extension"),
+	}
+	return nil
+}
+
+// Comment is a message on an incident.
+type Comment struct {
+	ID              int
+	IncidentID      int
+	AuthorID        int
+	Comment         string
+	CreatedAt       int64
+	CustomerVisible *bool // nil when InvGate omits the flag
+	IsSolution      bool
+	MsgNum          int
+	Reference       string
+	Attachments     []Attachment
+}
+
+// Internal reports whether InvGate marked the comment as hidden from the
+// requester. Comments without the flag are not treated as internal.
+func (c Comment) Internal() bool {
+	return c.CustomerVisible != nil && !*c.CustomerVisible
+}
+
+// UnmarshalJSON implements tolerant decoding for Comment.
+func (c *Comment) UnmarshalJSON(data []byte) error {
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	*c = Comment{
+		ID:              f.Int("id"),
+		IncidentID:      f.Int("incident_id", "request_id"),
+		AuthorID:        f.Int("author_id"),
+		Comment:         f.String("comment", "message"),
+		CreatedAt:       f.Timestamp("created_at"),
+		CustomerVisible: f.OptionalBool("customer_visible"),
+		IsSolution:      f.Bool("is_solution"),
+		MsgNum:          f.Int("msg_num"),
+		Reference:       f.String("reference"),
+	}
+
+	for _, key := range []string{"attached_files", "attachments"} {
+		if raw, ok := f[key]; ok && raw != nil {
+			encoded, err := json.Marshal(raw)
+			if err != nil {
+				return err
+			}
+			if err := decodeList(encoded, &c.Attachments); err != nil {
+				return err
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// User is an InvGate user.
+type User struct {
+	ID       int
+	Name     string
+	LastName string
+	Email    string
+	Username string
+	Type     int
+	Disabled bool
+}
+
+// UnmarshalJSON implements tolerant decoding for User.
+func (u *User) UnmarshalJSON(data []byte) error {
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	*u = User{
+		ID:       f.Int("id", "user_id"),
+		Name:     f.String("name"),
+		LastName: f.String("lastname", "last_name"),
+		Email:    f.String("email"),
+		Username: f.String("username"),
+		Type:     f.Int("type"),
+		Disabled: f.Bool("is_disabled", "disabled"),
+	}
+	return nil
+}
+
+// Category is an InvGate request category.
+type Category struct {
+	ID       int
+	Name     string
+	ParentID int
+}
+
+// UnmarshalJSON implements tolerant decoding for Category.
+func (c *Category) UnmarshalJSON(data []byte) error {
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	*c = Category{
+		ID:       f.Int("id"),
+		Name:     f.String("name"),
+		ParentID: f.Int("parent_category_id", "parent_id"),
+	}
+	return nil
+}
+
+// Article is a knowledge base article.
+type Article struct {
+	ID             int
+	Title          string
+	Content        string
+	CategoryID     int
+	AuthorID       int
+	ResponsibleID  int
+	CreatedAt      int64
+	UpdatedAt      int64
+	SolvedRequests int
+	Views          int
+	IsPrivate      bool
+	Rating         float64
+	Attachments    []Attachment
+}
+
+// UnmarshalJSON implements tolerant decoding for Article.
+func (a *Article) UnmarshalJSON(data []byte) error {
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	*a = Article{
+		ID:             f.Int("id"),
+		Title:          f.String("title"),
+		Content:        f.String("content"),
+		CategoryID:     f.Int("category_id"),
+		AuthorID:       f.Int("author_id"),
+		ResponsibleID:  f.Int("responsible_id"),
+		CreatedAt:      f.Timestamp("creation_date", "created_at"),
+		UpdatedAt:      f.Timestamp("last_update_date", "updated_at"),
+		SolvedRequests: f.Int("solved_requests"),
+		Views:          f.Int("views"),
+		IsPrivate:      f.Bool("is_private"),
+		Rating:         f.Float("rating"),
+	}
+
+	if raw, ok := f["attachments"]; ok && raw != nil {
+		encoded, err := json.Marshal(raw)
+		if err != nil {
+			return err
+		}
+		if err := decodeList(encoded, &a.Attachments); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Result is the acknowledgement InvGate sends for write operations, e.g.
+// {"status": "OK", "info": "...", "request_id": 123}. ID holds whatever
+// identifier the endpoint returned for the created or modified entity.
+type Result struct {
+	ID     int
+	Status string
+	Info   string
+}
+
+// UnmarshalJSON implements tolerant decoding for Result.
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var f fields
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+
+	*r = Result{
+		ID:     f.Int("id", "request_id", "incident_id", "comment_id", "user_id"),
+		Status: f.String("status"),
+		Info:   f.String("info", "message"),
+	}
+	return nil
+}
+
+func firstNonZero(values ...int) int {
+	for _, v := range values {
+		if v != 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,78 @@
+package invgate
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+)
+
+// requestBody is a request payload of known size that can be replayed on
+// every retry attempt.
+type requestBody struct {
+	data io.ReaderAt
+	size int64
+}
+
+func bytesBody(b []byte) *requestBody {
+	return &requestBody{data: bytes.NewReader(b), size: int64(len(b))}
+}
+
+// open returns a fresh reader positioned at the start of the body.
+func (b *requestBody) open() (io.ReadCloser, error) {
+	if b.size == 0 {
+		return http.NoBody, nil
+	}
+	return io.NopCloser(io.NewSectionReader(b.data, 0, b.size)), nil
+}
+
+// multipartUpload is a multipart/form-data body spooled to a temporary file,
+// so attachments are copied once and never held in memory. Close removes
+// the file.
+type multipartUpload struct {
+	file        *os.File
+	body        *requestBody
+	contentType string
+}
+
+// newMultipartUpload runs write against a multipart writer connected to the
+// spool file through a pipe.
+func newMultipartUpload(write func(*multipart.Writer) error) (*multipartUpload, error) {
+	file, err := os.CreateTemp("", "invgate-upload-*")
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		err := write(writer)
+		if err == nil {
+			err = writer.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	size, err := io.Copy(file, pr)
+	// Unblocks the writer goroutine if the copy stopped early.
+	pr.CloseWithError(err)
+	upload := &multipartUpload{
+		file:        file,
+		body:        &requestBody{data: file, size: size},
+		contentType: writer.FormDataContentType(),
+	}
+	if err != nil {
+		upload.Close()
+		return nil, err
+	}
+	return upload, nil
+}
+
+func (u *multipartUpload) Close() error {
+	err := u.file.Close()
+	if removeErr := os.Remove(u.file.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
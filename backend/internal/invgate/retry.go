@@ -0,0 +1,92 @@
+package invgate
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"werk-ticketing/internal/constants"
+)
+
+// withRetry runs fn until it succeeds, returns a non-retryable error or the
+// attempt budget is exhausted. Delays grow exponentially with jitter, and a
+// Retry-After sent by InvGate takes precedence over the computed delay.
+// Requests that are not idempotent are only retried when isResendableError
+// shows InvGate cannot have acted on them.
+func withRetry(ctx context.Context, idempotent bool, fn func() ([]byte, error)) ([]byte, error) {
+	var lastErr error
+	delay := time.Duration(constants.RetryInitialDelayMs) * time.Millisecond
+
+	for attempt := 0; attempt < constants.RetryMaxAttempts; attempt++ {
+		if attempt > 0 {
+			wait, ok := retryDelay(lastErr, delay)
+			if !ok {
+				return nil, lastErr
+			}
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+
+			delay = time.Duration(math.Min(
+				float64(delay)*constants.RetryBackoffMultiplier,
+				float64(time.Duration(constants.RetryMaxDelayMs)*time.Millisecond),
+			))
+		}
+
+		result, err := fn()
+		if err == nil {
+			return result, nil
+		}
+
+		lastErr = err
+		if !isRetryableError(err) || (!idempotent && !isResendableError(err)) {
+			return nil, err
+		}
+	}
+
+	return nil, fmt.Errorf("request failed after %d attempts: %w", constants.RetryMaxAttempts, lastErr)
+}
+
+// retryDelay returns how long to wait before the next attempt. The second
+// return value is false when InvGate asked us to back off for longer than we
+// are willing to hold the portal request open.
+func retryDelay(lastErr error, base time.Duration) (time.Duration, bool) {
+	var apiErr *APIError
+	if stdErrors.As(lastErr, &apiErr) && apiErr.RetryAfter > 0 {
+		if apiErr.RetryAfter > time.Duration(constants.RetryAfterMaxMs)*time.Millisecond {
+			return 0, false
+		}
+		return apiErr.RetryAfter, true
+	}
+	return jitter(base), true
+}
+
+// isIdempotent reports whether sending a request with method twice has the
+// same effect as sending it once.
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// jitter randomizes the trailing RetryJitterFraction of d so that concurrent
+// clients do not retry in lockstep.
+func jitter(d time.Duration) time.Duration {
+	spread := time.Duration(float64(d) * constants.RetryJitterFraction)
+	if spread <= 0 {
+		return d
+	}
+	return d - spread + time.Duration(rand.Int63n(int64(spread)+1))
+}
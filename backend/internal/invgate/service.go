@@ -9,36 +9,44 @@ import (
 
 	"werk-ticketing/internal/config"
 	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/logging"
 )
 
 // Service defines InvGate Armmada HTTP client contract.
 type Service interface {
-	CreateUser(ctx context.Context, payload CreateUserPayload) (map[string]interface{}, error)
+	CreateUser(ctx context.Context, payload CreateUserPayload) (*Result, error)
 	DeleteUser(ctx context.Context, userID int) error
-	GetUser(ctx context.Context, userID int) (map[string]interface{}, error)
-	GetUserByEmail(ctx context.Context, email string) (map[string]interface{}, error)
-	CreateTicket(ctx context.Context, payload CreateTicketPayload) (map[string]interface{}, error)
-	CreateTicketWithAttachments(ctx context.Context, payload CreateTicketPayload, files []*multipart.FileHeader) (map[string]interface{}, error)
-	UpdateTicket(ctx context.Context, payload UpdateTicketPayload) (map[string]interface{}, error)
-	SolutionAccept(ctx context.Context, payload SolutionAcceptPayload) (map[string]interface{}, error)
-	SolutionReject(ctx context.Context, payload SolutionRejectPayload) (map[string]interface{}, error)
-	GetTicketList(ctx context.Context, filters url.Values) (map[string]interface{}, error)
-	GetTicketDetail(ctx context.Context, ticketID string) (map[string]interface{}, error)
-	GetCategories(ctx context.Context) (map[string]interface{}, error)
-	AddTicketComment(ctx context.Context, requestID, authorID int, comment string, files []*multipart.FileHeader) (map[string]interface{}, error)
-	GetTicketComments(ctx context.Context, requestID int) (map[string]interface{}, error)
-	GetTicketAttachment(ctx context.Context, attachmentID string) ([]byte, string, string, error)
-	GetTicketAttachmentInfo(ctx context.Context, attachmentID string) (map[string]interface{}, error)
+	GetUser(ctx context.Context, userID int) (*User, error)
+	// GetUserByEmail returns nil (and no error) when InvGate has no user with that email.
+	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	CreateTicket(ctx context.Context, payload CreateTicketPayload) (*Result, error)
+	CreateTicketWithAttachments(ctx context.Context, payload CreateTicketPayload, files []*multipart.FileHeader) (*Result, error)
+	UpdateTicket(ctx context.Context, payload UpdateTicketPayload) (*Result, error)
+	SolutionAccept(ctx context.Context, payload SolutionAcceptPayload) (*Result, error)
+	SolutionReject(ctx context.Context, payload SolutionRejectPayload) (*Result, error)
+	GetTicketList(ctx context.Context, filters url.Values) (*IncidentPage, error)
+	GetTicketDetail(ctx context.Context, ticketID string) (*Incident, error)
+	GetCategories(ctx context.Context) ([]Category, error)
+	AddTicketComment(ctx context.Context, requestID, authorID int, comment string, files []*multipart.FileHeader) (*Result, error)
+	GetTicketComments(ctx context.Context, requestID int) ([]Comment, error)
+	// GetTicketAttachment streams an attachment. The caller must close the returned Body.
+	GetTicketAttachment(ctx context.Context, attachmentID string, opts DownloadOptions) (*Download, error)
+	GetTicketAttachmentInfo(ctx context.Context, attachmentID string) (*Attachment, error)
 	AssignUserToCompany(ctx context.Context, companyID int, userIDs []int) error
 	AssignUserToGroup(ctx context.Context, groupID int, userIDs []int) error
 	AssignUserToLocation(ctx context.Context, locationID int, userIDs []int) error
-	GetTicketsByView(ctx context.Context, viewID int, pageKey string, creatorID int) (map[string]interface{}, error)
-	GetArticlesByCategory(ctx context.Context, categoryID int) (map[string]interface{}, error)
+	GetTicketsByView(ctx context.Context, viewID int, pageKey string, creatorID int) (*IncidentPage, error)
+	GetArticlesByCategory(ctx context.Context, categoryID int) ([]Article, error)
+	// Ping makes one cheap authenticated call, for health checks.
+	Ping(ctx context.Context) error
 }
 
 type service struct {
 	cfg    *config.Config
 	client *http.Client
+	// stream has no overall timeout because attachment bodies are streamed
+	// in both directions; see doStreamRequest and doUpload.
+	stream *http.Client
 }
 
 // NewService builds InvGate API client.
@@ -46,7 +54,14 @@ func NewService(cfg *config.Config) Service {
 	return &service{
 		cfg: cfg,
 		client: &http.Client{
-			Timeout: time.Duration(constants.HTTPClientTimeoutSeconds) * time.Second,
+			Transport: &logging.Transport{},
+			Timeout:   time.Duration(constants.HTTPClientTimeoutSeconds) * time.Second,
+		},
+		stream: &http.Client{
+			Transport: &logging.Transport{Base: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: time.Duration(constants.HTTPClientTimeoutSeconds) * time.Second,
+			}},
 		},
 	}
 }
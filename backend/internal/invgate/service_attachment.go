@@ -2,26 +2,64 @@ package invgate
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"mime"
 	"net/http"
 	"net/url"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"werk-ticketing/internal/tracing"
 )
 
-func (s *service) GetTicketAttachment(ctx context.Context, attachmentID string) ([]byte, string, string, error) {
+func (s *service) GetTicketAttachment(ctx context.Context, attachmentID string, opts DownloadOptions) (*Download, error) {
 	params := url.Values{}
 	params.Set("id", attachmentID)
 
-	data, filename, contentType, err := s.doRawRequestBytes(ctx, http.MethodGet, "incident.attachment", params)
+	header := http.Header{}
+	for key, value := range map[string]string{
+		"Range":             opts.Range,
+		"If-Range":          opts.IfRange,
+		"If-None-Match":     opts.IfNoneMatch,
+		"If-Modified-Since": opts.IfModifiedSince,
+	} {
+		if value != "" {
+			header.Set(key, value)
+		}
+	}
+
+	resp, err := s.doStreamRequest(ctx, "incident.attachment", params, header)
 	if err != nil {
-		return nil, "", "", err
+		return nil, err
 	}
-	return data, filename, contentType, nil
+
+	return &Download{
+		Body:          resp.Body,
+		StatusCode:    resp.StatusCode,
+		Filename:      parseFilename(resp.Header.Get("Content-Disposition")),
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+		ContentRange:  resp.Header.Get("Content-Range"),
+		AcceptRanges:  resp.Header.Get("Accept-Ranges"),
+		ETag:          resp.Header.Get("ETag"),
+		LastModified:  resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+// GetTicketAttachmentInfo is traced as a single client span: metadata
+// requests are not retried.
+func (s *service) GetTicketAttachmentInfo(ctx context.Context, attachmentID string) (*Attachment, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "invgate GET incident.attachment",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("invgate.endpoint", "incident.attachment")))
+	attachment, err := s.getTicketAttachmentInfo(ctx, attachmentID)
+	tracing.End(span, err)
+	return attachment, err
 }
 
-func (s *service) GetTicketAttachmentInfo(ctx context.Context, attachmentID string) (map[string]interface{}, error) {
+func (s *service) getTicketAttachmentInfo(ctx context.Context, attachmentID string) (*Attachment, error) {
 	params := url.Values{}
 	params.Set("id", attachmentID)
 
@@ -38,7 +76,7 @@ func (s *service) GetTicketAttachmentInfo(ctx context.Context, attachmentID stri
 	req.SetBasicAuth(s.cfg.ArmMadaUsername, s.cfg.ArmMadaPassword)
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(s.client, req, "incident.attachment")
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +84,7 @@ func (s *service) GetTicketAttachmentInfo(ctx context.Context, attachmentID stri
 
 	if resp.StatusCode >= 400 {
 		data, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("armmada error (status %d): %s", resp.StatusCode, string(data))
+		return nil, newAPIError("incident.attachment", resp, data)
 	}
 
 	data, err := io.ReadAll(resp.Body)
@@ -54,15 +92,11 @@ func (s *service) GetTicketAttachmentInfo(ctx context.Context, attachmentID stri
 		return nil, err
 	}
 
-	var decoded map[string]interface{}
-	if err := json.Unmarshal(data, &decoded); err == nil {
-		if dataField, ok := decoded["data"].(map[string]interface{}); ok {
-			return dataField, nil
-		}
-		return decoded, nil
+	var attachment Attachment
+	if err := decodeObject(data, &attachment); err != nil {
+		return nil, err
 	}
-
-	return nil, fmt.Errorf("failed to decode ArmMada response: %w", err)
+	return &attachment, nil
 }
 
 func parseFilename(contentDisposition string) string {
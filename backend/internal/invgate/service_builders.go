@@ -1,258 +1,147 @@
 package invgate
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"math"
 	"mime/multipart"
 	"net/http"
 	"strconv"
-	"time"
-
-	"werk-ticketing/internal/constants"
 )
 
-func (s *service) CreateTicketWithAttachments(ctx context.Context, payload CreateTicketPayload, files []*multipart.FileHeader) (map[string]interface{}, error) {
-	var lastErr error
-	delay := time.Duration(constants.RetryInitialDelayMs) * time.Millisecond
-
-	for attempt := 0; attempt < constants.RetryMaxAttempts; attempt++ {
-		if attempt > 0 {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
-			}
-			delay = time.Duration(math.Min(
-				float64(delay.Milliseconds())*constants.RetryBackoffMultiplier,
-				float64(constants.RetryMaxDelayMs),
-			)) * time.Millisecond
-		}
-
-		body, contentType, err := s.buildTicketMultipartBody(payload, files)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build multipart body: %w", err)
-		}
-
-		result, err, statusCode := s.doRawRequestSingle(ctx, http.MethodPost, "incident", nil, body, contentType)
-		if err == nil {
-			return result, nil
-		}
-
-		lastErr = err
-		if !isRetryableError(err, statusCode) {
-			return nil, err
-		}
+func (s *service) CreateTicketWithAttachments(ctx context.Context, payload CreateTicketPayload, files []*multipart.FileHeader) (*Result, error) {
+	upload, err := newMultipartUpload(func(writer *multipart.Writer) error {
+		return writeTicketForm(writer, payload, files)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build multipart body: %w", err)
 	}
+	defer upload.Close()
 
-	return nil, fmt.Errorf("request failed after %d attempts: %w", constants.RetryMaxAttempts, lastErr)
+	data, err := s.doUpload(ctx, http.MethodPost, "incident", upload)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult(data)
 }
 
-func (s *service) buildTicketMultipartBody(payload CreateTicketPayload, files []*multipart.FileHeader) (*bytes.Buffer, string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
+// writeTicketForm writes the incident creation fields and attachments.
+func writeTicketForm(writer *multipart.Writer, payload CreateTicketPayload, files []*multipart.FileHeader) error {
 	writeField := func(name, value string) error {
 		return writer.WriteField(name, value)
 	}
 
 	if err := writeField("source_id", strconv.Itoa(payload.SourceID)); err != nil {
-		return nil, "", err
+		return err
 	}
 	if err := writeField("creator_id", strconv.Itoa(payload.CreatorID)); err != nil {
-		return nil, "", err
+		return err
 	}
 	if err := writeField("customer_id", strconv.Itoa(payload.CustomerID)); err != nil {
-		return nil, "", err
+		return err
 	}
 	if err := writeField("category_id", strconv.Itoa(payload.CategoryID)); err != nil {
-		return nil, "", err
+		return err
 	}
 	if err := writeField("type_id", strconv.Itoa(payload.TypeID)); err != nil {
-		return nil, "", err
+		return err
 	}
 	if err := writeField("priority_id", strconv.Itoa(payload.PriorityID)); err != nil {
-		return nil, "", err
+		return err
 	}
 	if err := writeField("title", payload.Title); err != nil {
-		return nil, "", err
+		return err
 	}
 	if err := writeField("description", payload.Description); err != nil {
-		return nil, "", err
+		return err
 	}
 	if err := writeField("date", strconv.Itoa(payload.DateOcurred)); err != nil {
-		return nil, "", err
+		return err
 	}
 
-	for _, fileHeader := range files {
-		if fileHeader == nil {
-			continue
-		}
-
-		part, err := writer.CreateFormFile("attachments[]", fileHeader.Filename)
-		if err != nil {
-			return nil, "", err
-		}
-
-		file, err := fileHeader.Open()
-		if err != nil {
-			return nil, "", err
-		}
-
-		if _, err := io.Copy(part, file); err != nil {
-			file.Close()
-			return nil, "", err
-		}
-
-		file.Close()
-	}
-
-	contentType := writer.FormDataContentType()
-	if err := writer.Close(); err != nil {
-		return nil, "", err
-	}
-
-	return body, contentType, nil
+	return writeFormFiles(writer, files)
 }
 
-func (s *service) buildUpdateTicketMultipartBody(payload UpdateTicketPayload) (*bytes.Buffer, string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
+// writeUpdateTicketForm writes the fields set in payload.
+func writeUpdateTicketForm(writer *multipart.Writer, payload UpdateTicketPayload) error {
 	writeField := func(name, value string) error {
 		return writer.WriteField(name, value)
 	}
 
 	if err := writeField("id", strconv.Itoa(payload.ID)); err != nil {
-		return nil, "", err
+		return err
 	}
 
 	if payload.SourceID > 0 {
 		if err := writeField("source_id", strconv.Itoa(payload.SourceID)); err != nil {
-			return nil, "", err
+			return err
 		}
 	}
 	if payload.CreatorID > 0 {
 		if err := writeField("creator_id", strconv.Itoa(payload.CreatorID)); err != nil {
-			return nil, "", err
+			return err
 		}
 	}
 	if payload.CustomerID > 0 {
 		if err := writeField("customer_id", strconv.Itoa(payload.CustomerID)); err != nil {
-			return nil, "", err
+			return err
 		}
 	}
 	if payload.CategoryID > 0 {
 		if err := writeField("category_id", strconv.Itoa(payload.CategoryID)); err != nil {
-			return nil, "", err
+			return err
 		}
 	}
 	if payload.TypeID > 0 {
 		if err := writeField("type_id", strconv.Itoa(payload.TypeID)); err != nil {
-			return nil, "", err
+			return err
 		}
 	}
 	if payload.PriorityID > 0 {
 		if err := writeField("priority_id", strconv.Itoa(payload.PriorityID)); err != nil {
-			return nil, "", err
+			return err
 		}
 	}
 	if payload.Title != "" {
 		if err := writeField("title", payload.Title); err != nil {
-			return nil, "", err
+			return err
 		}
 	}
 	if payload.Description != "" {
 		if err := writeField("description", payload.Description); err != nil {
-			return nil, "", err
+			return err
 		}
 	}
 	if payload.DateOcurred > 0 {
 		if err := writeField("date", strconv.Itoa(payload.DateOcurred)); err != nil {
-			return nil, "", err
+			return err
 		}
 	}
 
-	contentType := writer.FormDataContentType()
-	if err := writer.Close(); err != nil {
-		return nil, "", err
-	}
-
-	return body, contentType, nil
-}
-
-func (s *service) buildSolutionAcceptMultipartBody(payload SolutionAcceptPayload) (*bytes.Buffer, string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	writeField := func(name, value string) error {
-		return writer.WriteField(name, value)
-	}
-
-	if err := writeField("id", strconv.Itoa(payload.ID)); err != nil {
-		return nil, "", err
-	}
-	if err := writeField("rating", strconv.Itoa(payload.Rating)); err != nil {
-		return nil, "", err
-	}
-	// Comment is optional, only send if provided (required when rating < 4, validated in service layer)
-	if payload.Comment != "" {
-		if err := writeField("comment", payload.Comment); err != nil {
-			return nil, "", err
-		}
-	}
-
-	contentType := writer.FormDataContentType()
-	if err := writer.Close(); err != nil {
-		return nil, "", err
-	}
-
-	return body, contentType, nil
+	return nil
 }
 
-func (s *service) buildSolutionRejectMultipartBody(payload SolutionRejectPayload) (*bytes.Buffer, string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	writeField := func(name, value string) error {
-		return writer.WriteField(name, value)
-	}
-
-	if err := writeField("id", strconv.Itoa(payload.ID)); err != nil {
-		return nil, "", err
-	}
-	if err := writeField("comment", payload.Comment); err != nil {
-		return nil, "", err
-	}
-
-	contentType := writer.FormDataContentType()
-	if err := writer.Close(); err != nil {
-		return nil, "", err
-	}
-
-	return body, contentType, nil
-}
-
-func (s *service) buildCommentMultipartBody(requestID, authorID int, comment string, files []*multipart.FileHeader) (*bytes.Buffer, string, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
+// writeCommentForm writes the comment fields and attachments.
+func writeCommentForm(writer *multipart.Writer, requestID, authorID int, comment string, files []*multipart.FileHeader) error {
 	writeField := func(name, value string) error {
 		return writer.WriteField(name, value)
 	}
 
 	if err := writeField("request_id", strconv.Itoa(requestID)); err != nil {
-		return nil, "", err
+		return err
 	}
 	if err := writeField("author_id", strconv.Itoa(authorID)); err != nil {
-		return nil, "", err
+		return err
 	}
 	if err := writeField("comment", comment); err != nil {
-		return nil, "", err
+		return err
 	}
 
+	return writeFormFiles(writer, files)
+}
+
+// writeFormFiles copies each attachment into an "attachments[]" part.
+func writeFormFiles(writer *multipart.Writer, files []*multipart.FileHeader) error {
 	for _, fileHeader := range files {
 		if fileHeader == nil {
 			continue
@@ -260,26 +149,21 @@ func (s *service) buildCommentMultipartBody(requestID, authorID int, comment str
 
 		part, err := writer.CreateFormFile("attachments[]", fileHeader.Filename)
 		if err != nil {
-			return nil, "", err
+			return err
 		}
 
 		file, err := fileHeader.Open()
 		if err != nil {
-			return nil, "", err
+			return err
 		}
 
 		if _, err := io.Copy(part, file); err != nil {
 			file.Close()
-			return nil, "", err
+			return err
 		}
 
 		file.Close()
 	}
 
-	contentType := writer.FormDataContentType()
-	if err := writer.Close(); err != nil {
-		return nil, "", err
-	}
-
-	return body, contentType, nil
+	return nil
 }
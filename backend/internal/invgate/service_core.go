@@ -1,75 +1,92 @@
 package invgate
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
+	"strconv"
+	"sync/atomic"
 	"time"
 
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
 	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/metrics"
+	"werk-ticketing/internal/tracing"
 )
 
-func (s *service) doRequest(ctx context.Context, method, path string, body interface{}, params url.Values) (map[string]interface{}, error) {
-	var buf io.Reader
+// doRequest sends body as JSON and returns the raw response body. Callers
+// decode it into the typed models with decodeObject or decodeList.
+func (s *service) doRequest(ctx context.Context, method, path string, body interface{}, params url.Values) ([]byte, error) {
+	var payload *requestBody
 	if body != nil {
-		payload, err := json.Marshal(body)
+		encoded, err := json.Marshal(body)
 		if err != nil {
 			return nil, err
 		}
-		buf = bytes.NewBuffer(payload)
+		payload = bytesBody(encoded)
 	}
-	return s.doRawRequest(ctx, method, path, params, buf, "application/json")
+	return s.doRawRequest(ctx, s.client, method, path, params, payload, "application/json")
 }
 
-func (s *service) doRawRequest(ctx context.Context, method, path string, params url.Values, body io.Reader, contentType string) (map[string]interface{}, error) {
-	return s.doRawRequestWithRetry(ctx, method, path, params, body, contentType)
+// doUpload sends a spooled multipart body. It uses the stream client because
+// large attachments cannot be expected to upload within the API timeout.
+func (s *service) doUpload(ctx context.Context, method, path string, upload *multipartUpload) ([]byte, error) {
+	return s.doRawRequest(ctx, s.stream, method, path, nil, upload.body, upload.contentType)
 }
 
-func (s *service) doRawRequestWithRetry(ctx context.Context, method, path string, params url.Values, body io.Reader, contentType string) (map[string]interface{}, error) {
-	var lastErr error
-	delay := time.Duration(constants.RetryInitialDelayMs) * time.Millisecond
-
-	for attempt := 0; attempt < constants.RetryMaxAttempts; attempt++ {
-		if attempt > 0 {
-			select {
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			case <-time.After(delay):
-			}
-			delay = time.Duration(math.Min(
-				float64(delay.Milliseconds())*constants.RetryBackoffMultiplier,
-				float64(constants.RetryMaxDelayMs),
-			)) * time.Millisecond
-		}
-
-		result, err, statusCode := s.doRawRequestSingle(ctx, method, path, params, body, contentType)
-		if err == nil {
-			return result, nil
-		}
-
-		lastErr = err
-		if !isRetryableError(err, statusCode) {
-			return nil, err
+// doRawRequest sends body with retries. Every attempt reads body from the
+// start, so it is never consumed by a failed attempt. The call is traced as
+// one span with a child span per attempt.
+func (s *service) doRawRequest(ctx context.Context, client *http.Client, method, path string, params url.Values, body *requestBody, contentType string) ([]byte, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "invgate "+method+" "+path, trace.WithAttributes(
+		attribute.String("invgate.endpoint", path),
+		attribute.String("http.request.method", method),
+	))
+
+	attempts := 0
+	data, err := withRetry(ctx, isIdempotent(method), func() ([]byte, error) {
+		if attempts > 0 {
+			metrics.InvGateRetries.WithLabelValues(path, method).Inc()
 		}
-	}
-
-	return nil, fmt.Errorf("request failed after %d attempts: %w", constants.RetryMaxAttempts, lastErr)
+		attempts++
+
+		attemptCtx, attempt := tracing.Tracer().Start(ctx, "invgate attempt",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.Int("invgate.attempt", attempts)))
+		data, err := s.doRawRequestSingle(attemptCtx, client, method, path, params, body, contentType)
+		tracing.End(attempt, err)
+		return data, err
+	})
+
+	span.SetAttributes(attribute.Int("invgate.attempts", attempts))
+	tracing.End(span, err)
+	return data, err
 }
 
-func (s *service) doRawRequestSingle(ctx context.Context, method, path string, params url.Values, body io.Reader, contentType string) (map[string]interface{}, error, int) {
+func (s *service) doRawRequestSingle(ctx context.Context, client *http.Client, method, path string, params url.Values, body *requestBody, contentType string) ([]byte, error) {
 	fullURL := s.cfg.ArmMadaBaseURL + path
 	if len(params) > 0 {
 		fullURL = fmt.Sprintf("%s?%s", fullURL, params.Encode())
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, nil)
 	if err != nil {
-		return nil, err, 0
+		return nil, err
+	}
+	if body != nil {
+		if req.Body, err = body.open(); err != nil {
+			return nil, err
+		}
+		req.GetBody = body.open
+		req.ContentLength = body.size
 	}
 
 	req.SetBasicAuth(s.cfg.ArmMadaUsername, s.cfg.ArmMadaPassword)
@@ -77,81 +94,130 @@ func (s *service) doRawRequestSingle(ctx context.Context, method, path string, p
 		req.Header.Set("Content-Type", contentType)
 	}
 
-	resp, err := s.client.Do(req)
+	// A request is only known to be unsent when the transport failed before
+	// it got a connection; after that InvGate may have received some of it.
+	var connected atomic.Bool
+	req = req.WithContext(httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(httptrace.GotConnInfo) { connected.Store(true) },
+	}))
+
+	resp, err := s.do(client, req, path)
 	if err != nil {
-		return nil, err, 0
+		if !connected.Load() {
+			return nil, &unsentError{err: err}
+		}
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		data, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("armmada error (status %d): %s", resp.StatusCode, string(data)), resp.StatusCode
-	}
-
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err, resp.StatusCode
-	}
-
-	var decoded map[string]interface{}
-	if err := json.Unmarshal(data, &decoded); err == nil {
-		return decoded, nil, resp.StatusCode
+		return nil, newAPIError(path, resp, data)
 	}
 
-	var arrayResp []interface{}
-	if err := json.Unmarshal(data, &arrayResp); err == nil {
-		return map[string]interface{}{
-			"data": arrayResp,
-		}, nil, resp.StatusCode
-	}
-
-	return nil, fmt.Errorf("failed to decode ArmMada response: %w", err), resp.StatusCode
+	return io.ReadAll(resp.Body)
 }
 
-func (s *service) doRawRequestBytes(ctx context.Context, method, path string, params url.Values) ([]byte, string, string, error) {
+// doStreamRequest sends a GET request and hands the response body to the
+// caller without buffering it. The request is bounded by an idle timeout
+// instead of a total one so large files can take as long as they need.
+// Download requests are not retried: once bytes reach the portal client the
+// request cannot be replayed.
+func (s *service) doStreamRequest(ctx context.Context, path string, params url.Values, header http.Header) (*http.Response, error) {
 	fullURL := s.cfg.ArmMadaBaseURL + path
 	if len(params) > 0 {
 		fullURL = fmt.Sprintf("%s?%s", fullURL, params.Encode())
 	}
 
-	req, err := http.NewRequestWithContext(ctx, method, fullURL, nil)
+	// The span covers the request until the response headers arrive; the
+	// body is streamed to the portal client afterwards.
+	ctx, span := tracing.Tracer().Start(ctx, "invgate GET "+path,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("invgate.endpoint", path)))
+	ctx, cancel := context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURL, nil)
 	if err != nil {
-		return nil, "", "", err
+		cancel()
+		tracing.End(span, err)
+		return nil, err
 	}
 
+	for key, values := range header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
 	req.SetBasicAuth(s.cfg.ArmMadaUsername, s.cfg.ArmMadaPassword)
 
-	resp, err := s.client.Do(req)
+	resp, err := s.do(s.stream, req, path)
 	if err != nil {
-		return nil, "", "", err
+		cancel()
+		tracing.End(span, err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		data, _ := io.ReadAll(resp.Body)
-		return nil, "", "", fmt.Errorf("armmada error (status %d): %s", resp.StatusCode, string(data))
+		resp.Body.Close()
+		cancel()
+		err := newAPIError(path, resp, data)
+		tracing.End(span, err)
+		return nil, err
 	}
+	span.End()
 
-	data, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, "", "", err
-	}
+	resp.Body = newIdleTimeoutBody(resp.Body, time.Duration(constants.DownloadIdleTimeoutSeconds)*time.Second, cancel)
+	return resp, nil
+}
 
-	contentType := resp.Header.Get("Content-Type")
-	filename := parseFilename(resp.Header.Get("Content-Disposition"))
+// do sends a single attempt and records it in the InvGate metrics under
+// the endpoint path. The trace context of req is propagated to InvGate and
+// the response status is added to its span.
+func (s *service) do(client *http.Client, req *http.Request, path string) (*http.Response, error) {
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
 
-	return data, filename, contentType, nil
-}
+	start := time.Now()
+	resp, err := client.Do(req)
+	metrics.InvGateRequestDuration.WithLabelValues(path, req.Method).Observe(time.Since(start).Seconds())
 
-func isRetryableError(err error, statusCode int) bool {
-	if err != nil {
-		return true
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+		trace.SpanFromContext(req.Context()).SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
 	}
-	if statusCode >= 500 {
-		return true
+	metrics.InvGateRequests.WithLabelValues(path, req.Method, status).Inc()
+	return resp, err
+}
+
+// idleTimeoutBody cancels the underlying request when no data has been read
+// for the configured duration.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	idle   time.Duration
+	timer  *time.Timer
+	cancel context.CancelFunc
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, idle time.Duration, cancel context.CancelFunc) *idleTimeoutBody {
+	return &idleTimeoutBody{
+		ReadCloser: body,
+		idle:       idle,
+		timer:      time.AfterFunc(idle, cancel),
+		cancel:     cancel,
 	}
-	if statusCode == 429 {
-		return true
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		b.timer.Reset(b.idle)
 	}
-	return false
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
 }
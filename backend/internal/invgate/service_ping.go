@@ -0,0 +1,14 @@
+package invgate
+
+import (
+	"context"
+	"net/http"
+)
+
+// Ping checks that InvGate is reachable and accepts the credentials. It
+// makes a single attempt, without retries, so a health check gets a prompt
+// answer.
+func (s *service) Ping(ctx context.Context) error {
+	_, err := s.doRawRequestSingle(ctx, s.client, http.MethodGet, "categories", nil, nil, "")
+	return err
+}
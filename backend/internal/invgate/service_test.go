@@ -0,0 +1,657 @@
+package invgate_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"werk-ticketing/internal/config"
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/invgate/invgatetest"
+	"werk-ticketing/internal/logging"
+	"werk-ticketing/internal/metrics"
+)
+
+func newClient(t *testing.T) (invgate.Service, *invgatetest.Server) {
+	t.Helper()
+	fake := invgatetest.NewServer()
+	t.Cleanup(fake.Close)
+	return invgate.NewService(fake.Config()), fake
+}
+
+func TestUserLifecycle(t *testing.T) {
+	client, fake := newClient(t)
+	ctx := context.Background()
+
+	created, err := client.CreateUser(ctx, invgate.CreateUserPayload{
+		Name:     "Ada",
+		LastName: "Lovelace",
+		Email:    "ada@example.com",
+		Pass:     "Secret123",
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if created.ID == 0 {
+		t.Fatalf("CreateUser returned no id: %+v", created)
+	}
+
+	got, err := client.GetUser(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if got.Email != "ada@example.com" || got.LastName != "Lovelace" {
+		t.Errorf("GetUser = %+v", got)
+	}
+
+	byEmail, err := client.GetUserByEmail(ctx, "ada@example.com")
+	if err != nil {
+		t.Fatalf("GetUserByEmail: %v", err)
+	}
+	if byEmail == nil || byEmail.ID != created.ID {
+		t.Errorf("GetUserByEmail = %+v, want id %d", byEmail, created.ID)
+	}
+
+	if err := client.DeleteUser(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, ok := fake.User(created.ID); ok {
+		t.Error("user still present after DeleteUser")
+	}
+
+	missing, err := client.GetUserByEmail(ctx, "ada@example.com")
+	if err != nil || missing != nil {
+		t.Errorf("GetUserByEmail after delete = %+v, %v; want nil, nil", missing, err)
+	}
+}
+
+func TestCreateUserConflict(t *testing.T) {
+	client, fake := newClient(t)
+	fake.AddUser(invgatetest.User{Email: "taken@example.com"})
+
+	_, err := client.CreateUser(context.Background(), invgate.CreateUserPayload{
+		Name: "A", LastName: "B", Email: "taken@example.com", Pass: "Secret123",
+	})
+	appErr := invgate.AsAppError(err, "failed to create user")
+	if appErr == nil || appErr.Code != errors.ErrCodeConflict {
+		t.Fatalf("AsAppError = %+v, want CONFLICT", appErr)
+	}
+}
+
+func TestAssignUsers(t *testing.T) {
+	client, fake := newClient(t)
+	ctx := context.Background()
+	userID := fake.AddUser(invgatetest.User{Email: "user@example.com"})
+
+	if err := client.AssignUserToCompany(ctx, 135, []int{userID}); err != nil {
+		t.Fatalf("AssignUserToCompany: %v", err)
+	}
+	if err := client.AssignUserToGroup(ctx, 134, []int{userID}); err != nil {
+		t.Fatalf("AssignUserToGroup: %v", err)
+	}
+	if err := client.AssignUserToLocation(ctx, 136, []int{userID}); err != nil {
+		t.Fatalf("AssignUserToLocation: %v", err)
+	}
+
+	for endpoint, entityID := range map[string]int{
+		"companies.users": 135,
+		"groups.users":    134,
+		"locations.users": 136,
+	} {
+		if got := fake.Assigned(endpoint, entityID); len(got) != 1 || got[0] != userID {
+			t.Errorf("%s[%d] = %v, want [%d]", endpoint, entityID, got, userID)
+		}
+	}
+}
+
+func TestTicketLifecycle(t *testing.T) {
+	client, fake := newClient(t)
+	ctx := context.Background()
+	userID := fake.AddUser(invgatetest.User{Email: "user@example.com"})
+
+	created, err := client.CreateTicket(ctx, invgate.CreateTicketPayload{
+		SourceID:    2,
+		CreatorID:   userID,
+		CustomerID:  userID,
+		CategoryID:  115,
+		TypeID:      1,
+		PriorityID:  3,
+		Title:       "Printer on fire",
+		Description: "Smoke everywhere",
+		DateOcurred: 1700000000,
+	})
+	if err != nil {
+		t.Fatalf("CreateTicket: %v", err)
+	}
+	if created.ID == 0 {
+		t.Fatalf("CreateTicket returned no id: %+v", created)
+	}
+
+	detail, err := client.GetTicketDetail(ctx, strconv.Itoa(created.ID))
+	if err != nil {
+		t.Fatalf("GetTicketDetail: %v", err)
+	}
+	if detail.Title != "Printer on fire" || detail.CreatorID != userID || detail.CategoryID != 115 {
+		t.Errorf("GetTicketDetail = %+v", detail)
+	}
+	if detail.DateOcurred != 1700000000 || detail.ClosedAt != nil {
+		t.Errorf("GetTicketDetail timestamps = %d, %v", detail.DateOcurred, detail.ClosedAt)
+	}
+
+	if _, err := client.UpdateTicket(ctx, invgate.UpdateTicketPayload{ID: created.ID, Title: "Printer fixed?"}); err != nil {
+		t.Fatalf("UpdateTicket: %v", err)
+	}
+	if inc, _ := fake.Incident(created.ID); inc.Title != "Printer fixed?" {
+		t.Errorf("title after UpdateTicket = %q", inc.Title)
+	}
+
+	if _, err := client.SolutionReject(ctx, invgate.SolutionRejectPayload{ID: created.ID, Comment: "still broken"}); err != nil {
+		t.Fatalf("SolutionReject: %v", err)
+	}
+	if inc, _ := fake.Incident(created.ID); inc.StatusID != invgatetest.StatusOpen {
+		t.Errorf("status after SolutionReject = %d", inc.StatusID)
+	}
+
+	if _, err := client.SolutionAccept(ctx, invgate.SolutionAcceptPayload{ID: created.ID, Rating: 5}); err != nil {
+		t.Fatalf("SolutionAccept: %v", err)
+	}
+	closed, err := client.GetTicketDetail(ctx, strconv.Itoa(created.ID))
+	if err != nil {
+		t.Fatalf("GetTicketDetail: %v", err)
+	}
+	if closed.StatusID != invgatetest.StatusClosed || closed.ClosedAt == nil || closed.Rating != 5 {
+		t.Errorf("ticket after SolutionAccept = %+v", closed)
+	}
+}
+
+func TestSolutionAcceptOmitsUnsetRating(t *testing.T) {
+	client, fake := newClient(t)
+	ctx := context.Background()
+	ticketID := fake.AddIncident(invgatetest.Incident{Title: "Printer on fire"})
+
+	if _, err := client.SolutionAccept(ctx, invgate.SolutionAcceptPayload{ID: ticketID}); err != nil {
+		t.Fatalf("SolutionAccept: %v", err)
+	}
+
+	requests := fake.Requests()
+	body := requests[len(requests)-1].Body
+	var sent map[string]any
+	if err := json.Unmarshal(body, &sent); err != nil {
+		t.Fatalf("request body %q: %v", body, err)
+	}
+	if _, ok := sent["rating"]; ok || sent["id"] != float64(ticketID) {
+		t.Errorf("request body = %s, want the id without a rating", body)
+	}
+	if inc, _ := fake.Incident(ticketID); inc.StatusID != invgatetest.StatusClosed {
+		t.Errorf("status after SolutionAccept = %d", inc.StatusID)
+	}
+}
+
+func TestIncidentSolvedAtComesFromSolutionDate(t *testing.T) {
+	var detail, view invgate.Incident
+	if err := json.Unmarshal([]byte(`{"id":1,"closed_at":1700000200,"solved_at":1700000100}`), &detail); err != nil {
+		t.Fatalf("Unmarshal detail: %v", err)
+	}
+	if detail.ClosedAt == nil || *detail.ClosedAt != 1700000200 || detail.SolvedAt == nil || *detail.SolvedAt != 1700000100 {
+		t.Errorf("detail closed/solved = %v/%v", detail.ClosedAt, detail.SolvedAt)
+	}
+
+	if err := json.Unmarshal([]byte(`{"id":1,"closing_date":{"value":1700000200}}`), &view); err != nil {
+		t.Fatalf("Unmarshal view: %v", err)
+	}
+	if view.ClosedAt == nil || *view.ClosedAt != 1700000200 || view.SolvedAt != nil {
+		t.Errorf("view closed/solved = %v/%v, want no solution date", view.ClosedAt, view.SolvedAt)
+	}
+}
+
+func TestCreateTicketWithAttachments(t *testing.T) {
+	client, fake := newClient(t)
+	ctx := context.Background()
+	userID := fake.AddUser(invgatetest.User{Email: "user@example.com"})
+
+	files := []*multipart.FileHeader{
+		invgatetest.FileHeader(t, "log.txt", []byte("hello world")),
+	}
+	created, err := client.CreateTicketWithAttachments(ctx, invgate.CreateTicketPayload{
+		CreatorID: userID, CustomerID: userID, Title: "With file", Description: "see attachment",
+	}, files)
+	if err != nil {
+		t.Fatalf("CreateTicketWithAttachments: %v", err)
+	}
+
+	detail, err := client.GetTicketDetail(ctx, strconv.Itoa(created.ID))
+	if err != nil {
+		t.Fatalf("GetTicketDetail: %v", err)
+	}
+	if len(detail.AttachmentIDs) != 1 {
+		t.Fatalf("attachments = %v, want one", detail.AttachmentIDs)
+	}
+	attachmentID := strconv.Itoa(detail.AttachmentIDs[0])
+
+	info, err := client.GetTicketAttachmentInfo(ctx, attachmentID)
+	if err != nil {
+		t.Fatalf("GetTicketAttachmentInfo: %v", err)
+	}
+	if info.Name != "log.txt" || info.Extension != "txt" || info.URL == "" {
+		t.Errorf("GetTicketAttachmentInfo = %+v", info)
+	}
+
+	download, err := client.GetTicketAttachment(ctx, attachmentID, invgate.DownloadOptions{})
+	if err != nil {
+		t.Fatalf("GetTicketAttachment: %v", err)
+	}
+	data, err := io.ReadAll(download.Body)
+	download.Body.Close()
+	if err != nil {
+		t.Fatalf("read attachment: %v", err)
+	}
+	if string(data) != "hello world" || download.Filename != "log.txt" || download.ContentLength != 11 {
+		t.Errorf("GetTicketAttachment = %q, %+v", data, download)
+	}
+	if download.ETag == "" || download.LastModified == "" {
+		t.Errorf("validators not passed through: %+v", download)
+	}
+
+	partial, err := client.GetTicketAttachment(ctx, attachmentID, invgate.DownloadOptions{Range: "bytes=6-"})
+	if err != nil {
+		t.Fatalf("GetTicketAttachment (range): %v", err)
+	}
+	data, _ = io.ReadAll(partial.Body)
+	partial.Body.Close()
+	if partial.StatusCode != http.StatusPartialContent || string(data) != "world" || partial.ContentRange != "bytes 6-10/11" {
+		t.Errorf("range download = %d %q %q", partial.StatusCode, data, partial.ContentRange)
+	}
+
+	cached, err := client.GetTicketAttachment(ctx, attachmentID, invgate.DownloadOptions{IfNoneMatch: download.ETag})
+	if err != nil {
+		t.Fatalf("GetTicketAttachment (conditional): %v", err)
+	}
+	cached.Body.Close()
+	if cached.StatusCode != http.StatusNotModified {
+		t.Errorf("conditional download status = %d, want 304", cached.StatusCode)
+	}
+}
+
+func TestTicketLists(t *testing.T) {
+	client, fake := newClient(t)
+	ctx := context.Background()
+	alice := fake.AddUser(invgatetest.User{Email: "alice@example.com"})
+	bob := fake.AddUser(invgatetest.User{Email: "bob@example.com"})
+	fake.AddIncident(invgatetest.Incident{Title: "alice 1", CreatorID: alice, CategoryID: 115})
+	fake.AddIncident(invgatetest.Incident{Title: "bob 1", CreatorID: bob})
+	fake.AddIncident(invgatetest.Incident{Title: "alice 2", CreatorID: alice, StatusID: invgatetest.StatusOpen})
+
+	all, err := client.GetTicketList(ctx, url.Values{})
+	if err != nil {
+		t.Fatalf("GetTicketList: %v", err)
+	}
+	if len(all.Incidents) != 3 {
+		t.Errorf("GetTicketList returned %d incidents, want 3", len(all.Incidents))
+	}
+
+	view, err := client.GetTicketsByView(ctx, 7, "", alice)
+	if err != nil {
+		t.Fatalf("GetTicketsByView: %v", err)
+	}
+	if len(view.Incidents) != 2 {
+		t.Fatalf("GetTicketsByView returned %d incidents, want 2", len(view.Incidents))
+	}
+	first := view.Incidents[0]
+	if first.Title != "alice 1" || first.CreatorID != alice || first.CategoryID != 115 || first.CreatedAt == 0 {
+		t.Errorf("view incident = %+v", first)
+	}
+	if second := view.Incidents[1]; second.StatusID != invgatetest.StatusOpen || second.StatusLabel != "Open" {
+		t.Errorf("view status = %d %q", second.StatusID, second.StatusLabel)
+	}
+}
+
+func TestComments(t *testing.T) {
+	client, fake := newClient(t)
+	ctx := context.Background()
+	userID := fake.AddUser(invgatetest.User{Email: "user@example.com"})
+	ticketID := fake.AddIncident(invgatetest.Incident{Title: "t", CreatorID: userID})
+
+	if _, err := client.AddTicketComment(ctx, ticketID, userID, "plain comment", nil); err != nil {
+		t.Fatalf("AddTicketComment (json): %v", err)
+	}
+	files := []*multipart.FileHeader{invgatetest.FileHeader(t, "shot.png", []byte("png"))}
+	if _, err := client.AddTicketComment(ctx, ticketID, userID, "with file", files); err != nil {
+		t.Fatalf("AddTicketComment (multipart): %v", err)
+	}
+
+	comments, err := client.GetTicketComments(ctx, ticketID)
+	if err != nil {
+		t.Fatalf("GetTicketComments: %v", err)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("GetTicketComments returned %d comments, want 2", len(comments))
+	}
+	if comments[0].Comment != "plain comment" || comments[0].AuthorID != userID || comments[0].CustomerVisible == nil || !*comments[0].CustomerVisible {
+		t.Errorf("first comment = %+v", comments[0])
+	}
+	if len(comments[1].Attachments) != 1 || comments[1].Attachments[0].ID == 0 {
+		t.Errorf("second comment attachments = %+v", comments[1].Attachments)
+	}
+
+	if _, err := client.AddTicketComment(ctx, 999999, userID, "nowhere", nil); !invgate.IsNotFound(err) {
+		t.Errorf("AddTicketComment on missing ticket error = %v, want 404", err)
+	}
+}
+
+func TestCategoriesAndArticles(t *testing.T) {
+	client, fake := newClient(t)
+	ctx := context.Background()
+	fake.SetCategories(
+		invgatetest.Category{ID: 115, Name: "ESS"},
+		invgatetest.Category{ID: 200, Name: "Internal", ParentID: 115},
+	)
+	fake.AddArticle(invgatetest.Article{CategoryID: 16, Title: "How to reset a password", Views: 3})
+	fake.AddArticle(invgatetest.Article{CategoryID: 17, Title: "Other"})
+
+	categories, err := client.GetCategories(ctx)
+	if err != nil {
+		t.Fatalf("GetCategories: %v", err)
+	}
+	if len(categories) != 2 || categories[1].ParentID != 115 {
+		t.Errorf("GetCategories = %+v", categories)
+	}
+
+	articles, err := client.GetArticlesByCategory(ctx, 16)
+	if err != nil {
+		t.Fatalf("GetArticlesByCategory: %v", err)
+	}
+	if len(articles) != 1 || articles[0].Title != "How to reset a password" || articles[0].Rating != 4.5 {
+		t.Errorf("GetArticlesByCategory = %+v", articles)
+	}
+}
+
+func TestRetriedUploadReplaysAttachments(t *testing.T) {
+	client, fake := newClient(t)
+	ctx := context.Background()
+	userID := fake.AddUser(invgatetest.User{Email: "user@example.com"})
+	fake.Fail(invgatetest.Failure{Method: http.MethodPost, Endpoint: "incident", Status: http.StatusServiceUnavailable, RetryAfter: "0", Times: 1})
+
+	content := []byte(strings.Repeat("x", 3<<20))
+	created, err := client.CreateTicketWithAttachments(ctx, invgate.CreateTicketPayload{
+		CreatorID: userID, CustomerID: userID, Title: "Large file",
+	}, []*multipart.FileHeader{invgatetest.FileHeader(t, "big.bin", content)})
+	if err != nil {
+		t.Fatalf("CreateTicketWithAttachments: %v", err)
+	}
+
+	var lengths []int64
+	for _, r := range fake.Requests() {
+		if r.Method == http.MethodPost && r.Endpoint == "incident" {
+			lengths = append(lengths, r.ContentLength)
+		}
+	}
+	if len(lengths) != 2 || lengths[0] <= int64(len(content)) || lengths[0] != lengths[1] {
+		t.Errorf("upload content lengths = %v, want two equal lengths above %d", lengths, len(content))
+	}
+
+	incident, _ := fake.Incident(created.ID)
+	if len(incident.Attachments) != 1 {
+		t.Fatalf("attachments = %v, want one", incident.Attachments)
+	}
+	stored, _ := fake.Attachment(incident.Attachments[0])
+	if !bytes.Equal(stored.Data, content) {
+		t.Errorf("stored attachment has %d bytes, want %d", len(stored.Data), len(content))
+	}
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	client, fake := newClient(t)
+	fake.SetCategories(invgatetest.Category{ID: 1, Name: "Only"})
+	fake.Fail(invgatetest.Failure{Endpoint: "categories", Status: http.StatusServiceUnavailable, RetryAfter: "0", Times: 1})
+
+	categories, err := client.GetCategories(context.Background())
+	if err != nil {
+		t.Fatalf("GetCategories: %v", err)
+	}
+	if len(categories) != 1 {
+		t.Errorf("GetCategories = %+v", categories)
+	}
+	if calls := fake.Calls(http.MethodGet, "categories"); calls != 2 {
+		t.Errorf("categories called %d times, want 2", calls)
+	}
+}
+
+func TestDoesNotResendPostsInvGateMayHaveProcessed(t *testing.T) {
+	for _, failure := range []invgatetest.Failure{
+		{Method: http.MethodPost, Endpoint: "user", Status: http.StatusServiceUnavailable, Times: 1},
+		{Method: http.MethodPost, Endpoint: "user", Status: http.StatusBadGateway, RetryAfter: "0", Times: 1},
+	} {
+		client, fake := newClient(t)
+		fake.Fail(failure)
+
+		_, err := client.CreateUser(context.Background(), invgate.CreateUserPayload{Name: "Ada", Email: "ada@example.com"})
+		var apiErr *invgate.APIError
+		if !stdErrors.As(err, &apiErr) || apiErr.StatusCode != failure.Status {
+			t.Errorf("status %d: CreateUser error = %v, want the InvGate error", failure.Status, err)
+		}
+		if calls := fake.Calls(http.MethodPost, "user"); calls != 1 {
+			t.Errorf("status %d: user created %d times, want 1", failure.Status, calls)
+		}
+	}
+}
+
+func TestDoesNotResendPostAfterConnectionDrop(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	client := invgate.NewService(&config.Config{ArmMadaBaseURL: server.URL + "/"})
+	if _, err := client.CreateUser(context.Background(), invgate.CreateUserPayload{Name: "Ada"}); err == nil {
+		t.Fatal("CreateUser succeeded on a dropped connection")
+	}
+	if n := hits.Load(); n != 1 {
+		t.Errorf("request received %d times, want 1", n)
+	}
+}
+
+func TestResendsPostThatNeverConnected(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL + "/"
+	server.Close()
+
+	client := invgate.NewService(&config.Config{ArmMadaBaseURL: baseURL})
+	_, err := client.CreateUser(context.Background(), invgate.CreateUserPayload{Name: "Ada"})
+	if err == nil || !strings.Contains(err.Error(), "failed after 3 attempts") {
+		t.Errorf("CreateUser error = %v, want the attempts to be exhausted", err)
+	}
+}
+
+func TestForwardsRequestID(t *testing.T) {
+	client, fake := newClient(t)
+	fake.Fail(invgatetest.Failure{Endpoint: "categories", Status: http.StatusServiceUnavailable, RetryAfter: "0", Times: 1})
+
+	ctx := logging.WithRequestID(context.Background(), "req-123")
+	if _, err := client.GetCategories(ctx); err != nil {
+		t.Fatalf("GetCategories: %v", err)
+	}
+	requests := fake.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	for i, r := range requests {
+		if got := r.Header.Get(logging.RequestIDHeader); got != "req-123" {
+			t.Errorf("request %d %s = %q, want req-123", i, logging.RequestIDHeader, got)
+		}
+	}
+}
+
+func TestRecordsAttemptMetrics(t *testing.T) {
+	client, fake := newClient(t)
+	fake.Fail(invgatetest.Failure{Endpoint: "categories", Status: http.StatusServiceUnavailable, RetryAfter: "0", Times: 1})
+
+	retries := metrics.InvGateRetries.WithLabelValues("categories", http.MethodGet)
+	failed := metrics.InvGateRequests.WithLabelValues("categories", http.MethodGet, "503")
+	ok := metrics.InvGateRequests.WithLabelValues("categories", http.MethodGet, "200")
+	before := []float64{testutil.ToFloat64(retries), testutil.ToFloat64(failed), testutil.ToFloat64(ok)}
+
+	if _, err := client.GetCategories(context.Background()); err != nil {
+		t.Fatalf("GetCategories: %v", err)
+	}
+	for i, c := range []struct {
+		name string
+		got  float64
+	}{
+		{"retries", testutil.ToFloat64(retries)},
+		{"503 attempts", testutil.ToFloat64(failed)},
+		{"200 attempts", testutil.ToFloat64(ok)},
+	} {
+		if delta := c.got - before[i]; delta != 1 {
+			t.Errorf("%s grew by %v, want 1", c.name, delta)
+		}
+	}
+}
+
+func TestTracesCallAndAttempts(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	client, fake := newClient(t)
+	fake.Fail(invgatetest.Failure{Endpoint: "categories", Status: http.StatusServiceUnavailable, RetryAfter: "0", Times: 1})
+	if _, err := client.GetCategories(context.Background()); err != nil {
+		t.Fatalf("GetCategories: %v", err)
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("got %d spans, want a call span and 2 attempts", len(spans))
+	}
+	call := spans[len(spans)-1]
+	if call.Name != "invgate GET categories" {
+		t.Errorf("call span = %q", call.Name)
+	}
+	for _, attempt := range spans[:2] {
+		if attempt.Name != "invgate attempt" || attempt.Parent.SpanID() != call.SpanContext.SpanID() {
+			t.Errorf("attempt span %q has parent %s, want child of the call span", attempt.Name, attempt.Parent.SpanID())
+		}
+	}
+
+	for i, r := range fake.Requests() {
+		want := spans[i].SpanContext.SpanID().String()
+		if got := r.Header.Get("traceparent"); !strings.Contains(got, want) {
+			t.Errorf("request %d traceparent = %q, want attempt span %s", i, got, want)
+		}
+	}
+}
+
+func TestTracesAttachmentInfo(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	client, fake := newClient(t)
+	id := fake.AddAttachment(invgatetest.Attachment{Name: "report.pdf", ContentType: "application/pdf", Data: []byte("%PDF")})
+	if _, err := client.GetTicketAttachmentInfo(context.Background(), strconv.Itoa(id)); err != nil {
+		t.Fatalf("GetTicketAttachmentInfo: %v", err)
+	}
+	fake.Fail(invgatetest.Failure{Endpoint: "incident.attachment", Status: http.StatusInternalServerError})
+	if _, err := client.GetTicketAttachmentInfo(context.Background(), strconv.Itoa(id)); err == nil {
+		t.Fatal("GetTicketAttachmentInfo succeeded, want an error")
+	}
+
+	spans := exporter.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("got %d spans, want one per call", len(spans))
+	}
+	requests := fake.Requests()
+	for i, span := range spans {
+		if span.Name != "invgate GET incident.attachment" {
+			t.Errorf("span %d = %q", i, span.Name)
+		}
+		if got := requests[i].Header.Get("traceparent"); !strings.Contains(got, span.SpanContext.SpanID().String()) {
+			t.Errorf("request %d traceparent = %q, want span %s", i, got, span.SpanContext.SpanID())
+		}
+	}
+	if spans[0].Status.Code != codes.Unset || spans[1].Status.Code != codes.Error {
+		t.Errorf("span statuses = %v, %v, want unset then error", spans[0].Status.Code, spans[1].Status.Code)
+	}
+}
+
+func TestDoesNotRetryClientErrors(t *testing.T) {
+	client, fake := newClient(t)
+	fake.Fail(invgatetest.Failure{
+		Endpoint: "incident",
+		Method:   http.MethodGet,
+		Status:   http.StatusUnprocessableEntity,
+		Body:     `{"errors":["id is invalid"]}`,
+	})
+
+	_, err := client.GetTicketDetail(context.Background(), "abc")
+	var apiErr *invgate.APIError
+	if !stdErrors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusUnprocessableEntity || apiErr.Message != "id is invalid" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if calls := fake.Calls(http.MethodGet, "incident"); calls != 1 {
+		t.Errorf("incident called %d times, want 1", calls)
+	}
+	if appErr := invgate.AsAppError(err, "failed"); appErr.Code != errors.ErrCodeInvalidInput {
+		t.Errorf("AsAppError code = %s, want INVALID_INPUT", appErr.Code)
+	}
+}
+
+func TestRetryAfterAboveLimitGivesUp(t *testing.T) {
+	client, fake := newClient(t)
+	fake.Fail(invgatetest.Failure{Endpoint: "categories", Status: http.StatusTooManyRequests, RetryAfter: "3600"})
+
+	_, err := client.GetCategories(context.Background())
+	if appErr := invgate.AsAppError(err, "failed"); appErr == nil || appErr.Code != errors.ErrCodeTooManyRequests {
+		t.Fatalf("AsAppError = %+v, want TOO_MANY_REQUESTS", appErr)
+	}
+	if calls := fake.Calls(http.MethodGet, "categories"); calls != 1 {
+		t.Errorf("categories called %d times, want 1", calls)
+	}
+}
+
+func TestRejectsWrongCredentials(t *testing.T) {
+	fake := invgatetest.NewServer()
+	defer fake.Close()
+	cfg := fake.Config()
+	cfg.ArmMadaPassword = "wrong"
+
+	_, err := invgate.NewService(cfg).GetUser(context.Background(), 1)
+	if appErr := invgate.AsAppError(err, "failed"); appErr == nil || appErr.Code != errors.ErrCodeExternalService {
+		t.Fatalf("AsAppError = %+v, want EXTERNAL_SERVICE", appErr)
+	}
+}
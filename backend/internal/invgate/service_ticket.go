@@ -2,69 +2,121 @@ package invgate
 
 import (
 	"context"
+	"mime/multipart"
 	"net/http"
 	"net/url"
 	"strconv"
 )
 
-func (s *service) CreateTicket(ctx context.Context, payload CreateTicketPayload) (map[string]interface{}, error) {
-	body, contentType, err := s.buildTicketMultipartBody(payload, nil)
+func (s *service) CreateTicket(ctx context.Context, payload CreateTicketPayload) (*Result, error) {
+	upload, err := newMultipartUpload(func(writer *multipart.Writer) error {
+		return writeTicketForm(writer, payload, nil)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return s.doRawRequest(ctx, http.MethodPost, "incident", nil, body, contentType)
+	defer upload.Close()
+
+	data, err := s.doUpload(ctx, http.MethodPost, "incident", upload)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult(data)
 }
 
-func (s *service) UpdateTicket(ctx context.Context, payload UpdateTicketPayload) (map[string]interface{}, error) {
-	body, contentType, err := s.buildUpdateTicketMultipartBody(payload)
+func (s *service) UpdateTicket(ctx context.Context, payload UpdateTicketPayload) (*Result, error) {
+	upload, err := newMultipartUpload(func(writer *multipart.Writer) error {
+		return writeUpdateTicketForm(writer, payload)
+	})
 	if err != nil {
 		return nil, err
 	}
-	return s.doRawRequest(ctx, http.MethodPut, "incident", nil, body, contentType)
+	defer upload.Close()
+
+	data, err := s.doUpload(ctx, http.MethodPut, "incident", upload)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult(data)
 }
 
-func (s *service) SolutionAccept(ctx context.Context, payload SolutionAcceptPayload) (map[string]interface{}, error) {
-	return s.doRequest(ctx, http.MethodPut, "incident.solution.accept", payload, nil)
+func (s *service) SolutionAccept(ctx context.Context, payload SolutionAcceptPayload) (*Result, error) {
+	data, err := s.doRequest(ctx, http.MethodPut, "incident.solution.accept", payload, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult(data)
 }
 
-func (s *service) SolutionReject(ctx context.Context, payload SolutionRejectPayload) (map[string]interface{}, error) {
-	return s.doRequest(ctx, http.MethodPut, "incident.solution.reject", payload, nil)
+func (s *service) SolutionReject(ctx context.Context, payload SolutionRejectPayload) (*Result, error) {
+	data, err := s.doRequest(ctx, http.MethodPut, "incident.solution.reject", payload, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult(data)
 }
 
-func (s *service) GetTicketList(ctx context.Context, filters url.Values) (map[string]interface{}, error) {
+func (s *service) GetTicketList(ctx context.Context, filters url.Values) (*IncidentPage, error) {
 	if filters == nil {
 		filters = url.Values{}
 	}
 	if s.cfg.ArmMadaPageKey != "" && filters.Get("page_key") == "" {
 		filters.Set("page_key", s.cfg.ArmMadaPageKey)
 	}
-	return s.doRequest(ctx, http.MethodGet, "incidents", nil, filters)
+
+	data, err := s.doRequest(ctx, http.MethodGet, "incidents", nil, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	var page IncidentPage
+	if err := page.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return &page, nil
 }
 
-func (s *service) GetTicketDetail(ctx context.Context, ticketID string) (map[string]interface{}, error) {
+func (s *service) GetTicketDetail(ctx context.Context, ticketID string) (*Incident, error) {
 	params := url.Values{}
 	params.Set("id", ticketID)
-	return s.doRequest(ctx, http.MethodGet, "incident", nil, params)
+
+	data, err := s.doRequest(ctx, http.MethodGet, "incident", nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var incident Incident
+	if err := decodeObject(data, &incident); err != nil {
+		return nil, err
+	}
+	return &incident, nil
 }
 
-func (s *service) GetCategories(ctx context.Context) (map[string]interface{}, error) {
-	return s.doRequest(ctx, http.MethodGet, "categories", nil, nil)
+func (s *service) GetCategories(ctx context.Context) ([]Category, error) {
+	data, err := s.doRequest(ctx, http.MethodGet, "categories", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []Category
+	if err := decodeList(data, &categories); err != nil {
+		return nil, err
+	}
+	return categories, nil
 }
 
-func (s *service) GetArticlesByCategory(ctx context.Context, categoryID int) (map[string]interface{}, error) {
+func (s *service) GetArticlesByCategory(ctx context.Context, categoryID int) ([]Article, error) {
 	params := url.Values{}
 	params.Set("category_id", strconv.Itoa(categoryID))
 
-	resp, err := s.doRequest(ctx, http.MethodGet, "kb.articles.by.category", nil, params)
+	data, err := s.doRequest(ctx, http.MethodGet, "kb.articles.by.category", nil, params)
 	if err != nil {
 		return nil, err
 	}
 
-	if data, ok := resp["data"].([]interface{}); ok {
-		return map[string]interface{}{
-			"data": data,
-		}, nil
+	var articles []Article
+	if err := decodeList(data, &articles); err != nil {
+		return nil, err
 	}
-
-	return resp, nil
+	return articles, nil
 }
@@ -10,7 +10,7 @@ import (
 // GetTicketsByView retrieves tickets using InvGate's incidents.details.by.view endpoint
 // This endpoint returns detailed ticket information with metadata for a specific view
 // creatorID filters tickets to only show those created by the specified InvGate user ID
-func (s *service) GetTicketsByView(ctx context.Context, viewID int, pageKey string, creatorID int) (map[string]interface{}, error) {
+func (s *service) GetTicketsByView(ctx context.Context, viewID int, pageKey string, creatorID int) (*IncidentPage, error) {
 	params := url.Values{}
 	params.Set("view_id", strconv.Itoa(viewID))
 
@@ -23,5 +23,14 @@ func (s *service) GetTicketsByView(ctx context.Context, viewID int, pageKey stri
 		params.Set("creator_id", strconv.Itoa(creatorID))
 	}
 
-	return s.doRequest(ctx, http.MethodGet, "incidents.details.by.view", nil, params)
+	data, err := s.doRequest(ctx, http.MethodGet, "incidents.details.by.view", nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var page IncidentPage
+	if err := page.UnmarshalJSON(data); err != nil {
+		return nil, err
+	}
+	return &page, nil
 }
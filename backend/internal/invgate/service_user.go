@@ -7,8 +7,12 @@ import (
 	"strconv"
 )
 
-func (s *service) CreateUser(ctx context.Context, payload CreateUserPayload) (map[string]interface{}, error) {
-	return s.doRequest(ctx, http.MethodPost, "user", payload, nil)
+func (s *service) CreateUser(ctx context.Context, payload CreateUserPayload) (*Result, error) {
+	data, err := s.doRequest(ctx, http.MethodPost, "user", payload, nil)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResult(data)
 }
 
 func (s *service) DeleteUser(ctx context.Context, userID int) error {
@@ -18,14 +22,40 @@ func (s *service) DeleteUser(ctx context.Context, userID int) error {
 	return err
 }
 
-func (s *service) GetUser(ctx context.Context, userID int) (map[string]interface{}, error) {
+func (s *service) GetUser(ctx context.Context, userID int) (*User, error) {
 	params := url.Values{}
 	params.Set("id", strconv.Itoa(userID))
-	return s.doRequest(ctx, http.MethodGet, "user", nil, params)
+
+	data, err := s.doRequest(ctx, http.MethodGet, "user", nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var user User
+	if err := decodeObject(data, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
-func (s *service) GetUserByEmail(ctx context.Context, email string) (map[string]interface{}, error) {
+func (s *service) GetUserByEmail(ctx context.Context, email string) (*User, error) {
 	params := url.Values{}
 	params.Set("email", email)
-	return s.doRequest(ctx, http.MethodGet, "user.by", nil, params)
+
+	data, err := s.doRequest(ctx, http.MethodGet, "user.by", nil, params)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var user User
+	if err := decodeObject(data, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, nil
+	}
+	return &user, nil
 }
@@ -1,5 +1,7 @@
 package invgate
 
+import "io"
+
 // CreateTicketPayload matches InvGate create incident schema.
 type CreateTicketPayload struct {
 	SourceID    int                 `json:"source_id"`
@@ -39,11 +41,11 @@ type AttachmentPayload struct {
 // SolutionAcceptPayload represents the payload to accept/close a ticket solution in InvGate.
 // According to InvGate API, it expects:
 // - id: request/ticket ID (required)
-// - rating: 1-5 rating for the solution (required)
+// - rating: 1-5 rating for the solution (omitted when 0, for tenants that do not require one)
 // - comment: solution comment (optional, but required if rating < 4)
 type SolutionAcceptPayload struct {
 	ID      int    `json:"id"`
-	Rating  int    `json:"rating"`
+	Rating  int    `json:"rating,omitempty"`
 	Comment string `json:"comment,omitempty"`
 }
 
@@ -70,3 +72,26 @@ type UpdateTicketPayload struct {
 	Description string `json:"description,omitempty"`
 	DateOcurred int    `json:"date_ocurred,omitempty"` // UNIX timestamp - InvGate API uses "date"
 }
+
+// DownloadOptions carries the range and conditional request headers that are
+// forwarded to InvGate when downloading an attachment.
+type DownloadOptions struct {
+	Range           string
+	IfRange         string
+	IfNoneMatch     string
+	IfModifiedSince string
+}
+
+// Download is a streamed InvGate file. Body must be closed by the caller.
+// StatusCode is 200, 206 (partial content) or 304 (not modified, empty Body).
+type Download struct {
+	Body          io.ReadCloser
+	StatusCode    int
+	Filename      string
+	ContentType   string
+	ContentLength int64 // -1 when unknown
+	ContentRange  string
+	AcceptRanges  string
+	ETag          string
+	LastModified  string
+}
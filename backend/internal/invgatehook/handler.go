@@ -0,0 +1,197 @@
+package invgatehook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	stdErrors "errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/notification"
+	"werk-ticketing/internal/response"
+	"werk-ticketing/internal/tenant"
+)
+
+// Request headers InvGate can be configured to send.
+const (
+	// SignatureHeader carries "sha256=<hex HMAC-SHA256>" of
+	// "<timestamp>.<delivery ID>.<body>", so a captured request cannot be
+	// replayed as a new delivery or after the allowed clock skew.
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader carries the UNIX time a signed delivery was sent at.
+	TimestampHeader = "X-Webhook-Timestamp"
+	// TokenHeader carries the shared secret itself.
+	TokenHeader = "X-Webhook-Token"
+)
+
+var deliveryHeaders = []string{"X-Webhook-Delivery", "X-Delivery-ID"}
+
+// TicketSyncer refreshes a tenant's ticket from InvGate and notifies its
+// changes. It returns notification.ErrTicketNotInTenant for tickets of
+// other tenants.
+type TicketSyncer interface {
+	SyncTicket(ctx context.Context, tenantID string, ticketID int) error
+}
+
+// Handler receives webhooks InvGate sends when a ticket changes
+type Handler struct {
+	tenants    tenant.Repository
+	deliveries Repository
+	syncer     TicketSyncer
+	logger     *logrus.Logger
+}
+
+// NewHandler creates a new InvGate webhook handler
+func NewHandler(tenants tenant.Repository, deliveries Repository, syncer TicketSyncer, logger *logrus.Logger) *Handler {
+	return &Handler{tenants: tenants, deliveries: deliveries, syncer: syncer, logger: logger}
+}
+
+// Receive handles POST /webhooks/invgate/:tenant. The payload only says
+// which ticket changed; the ticket itself is read back from InvGate.
+func (h *Handler) Receive(c *gin.Context) {
+	ctx := c.Request.Context()
+	t, err := h.tenants.FindBySlug(ctx, c.Param("tenant"))
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to load tenant")
+		return
+	}
+	if t == nil || t.WebhookSecret == "" {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "webhook not configured")
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, constants.InvGateWebhookMaxBody))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if stdErrors.As(err, &tooLarge) {
+			response.ErrorWithCode(c, http.StatusRequestEntityTooLarge, errors.ErrCodeInvalidInput, "payload too large")
+			return
+		}
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "failed to read body")
+		return
+	}
+	if !Verify(t.WebhookSecret, c.Request.Header, body, time.Now()) {
+		response.ErrorWithCode(c, http.StatusUnauthorized, errors.ErrCodeUnauthorized, "invalid webhook signature")
+		return
+	}
+
+	ev, err := ParseEvent(body)
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid webhook payload")
+		return
+	}
+	logger := h.logger.WithFields(logrus.Fields{"tenantID": t.ID, "kind": ev.Kind, "ticketID": ev.TicketID})
+	if ev.Kind == "" || ev.TicketID == 0 {
+		// Acknowledge events we do not handle so InvGate stops resending them.
+		response.Success(c, http.StatusAccepted, gin.H{"ignored": true})
+		return
+	}
+
+	delivery := &Delivery{
+		ID:         uuid.New().String(),
+		TenantID:   t.ID,
+		DeliveryID: deliveryID(c.Request.Header, ev, body),
+		Kind:       ev.Kind,
+		TicketID:   ev.TicketID,
+	}
+	fresh, err := h.deliveries.Record(ctx, delivery)
+	if err != nil {
+		logger.WithError(err).Error("failed to record webhook delivery")
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to record delivery")
+		return
+	}
+	if !fresh {
+		response.Success(c, http.StatusOK, gin.H{"delivery_id": delivery.DeliveryID, "duplicate": true})
+		return
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, constants.InvGateWebhookProcessTimeout)
+	defer cancel()
+	if err := h.syncer.SyncTicket(syncCtx, t.ID, ev.TicketID); err != nil {
+		// Forget the delivery so InvGate's retry is processed.
+		if delErr := h.deliveries.Delete(ctx, delivery.ID); delErr != nil {
+			logger.WithError(delErr).Error("failed to release webhook delivery")
+		}
+		if stdErrors.Is(err, notification.ErrTicketNotInTenant) {
+			logger.Warn("webhook for a ticket of another tenant rejected")
+			response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "ticket not found")
+			return
+		}
+		logger.WithError(err).Warn("failed to sync ticket from webhook")
+		response.ErrorWithCode(c, http.StatusBadGateway, errors.ErrCodeExternalService, "failed to sync ticket")
+		return
+	}
+
+	logger.WithField("deliveryID", delivery.DeliveryID).Info("webhook processed")
+	response.Success(c, http.StatusOK, gin.H{"delivery_id": delivery.DeliveryID, "duplicate": false})
+}
+
+// Verify reports whether a request carries the tenant's secret, either as
+// an HMAC-SHA256 signature or as a plain token. A signature must cover a
+// delivery ID and a timestamp within InvGateWebhookMaxSkew of now.
+func Verify(secret string, header http.Header, body []byte, now time.Time) bool {
+	if secret == "" {
+		return false
+	}
+	if sig := header.Get(SignatureHeader); sig != "" {
+		got, err := hex.DecodeString(strings.TrimPrefix(sig, "sha256="))
+		if err != nil {
+			return false
+		}
+		timestamp := header.Get(TimestampHeader)
+		sent, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return false
+		}
+		if skew := now.Sub(time.Unix(sent, 0)); skew > constants.InvGateWebhookMaxSkew || skew < -constants.InvGateWebhookMaxSkew {
+			return false
+		}
+		id := headerDeliveryID(header)
+		if id == "" {
+			return false
+		}
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(timestamp + "." + id + "."))
+		mac.Write(body)
+		return hmac.Equal(got, mac.Sum(nil))
+	}
+	if token := header.Get(TokenHeader); token != "" {
+		return subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+	}
+	return false
+}
+
+// deliveryID identifies a delivery by the sender's ID when there is one,
+// and by the body otherwise, since a redelivery resends the same body.
+func deliveryID(header http.Header, ev Event, body []byte) string {
+	if id := headerDeliveryID(header); id != "" {
+		return id
+	}
+	if ev.DeliveryID != "" && len(ev.DeliveryID) <= 128 {
+		return ev.DeliveryID
+	}
+	sum := sha256.Sum256(body)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// headerDeliveryID returns the delivery ID sent in a request header.
+func headerDeliveryID(header http.Header) string {
+	for _, name := range deliveryHeaders {
+		if id := strings.TrimSpace(header.Get(name)); id != "" && len(id) <= 128 {
+			return id
+		}
+	}
+	return ""
+}
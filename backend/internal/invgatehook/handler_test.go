@@ -0,0 +1,241 @@
+package invgatehook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	stdErrors "errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/notification"
+	"werk-ticketing/internal/tenant"
+)
+
+const testSecret = "s3cret"
+
+type memoryTenants struct {
+	tenant.Repository
+	tenant *tenant.Tenant
+}
+
+func (r *memoryTenants) FindBySlug(ctx context.Context, slug string) (*tenant.Tenant, error) {
+	if r.tenant.Slug != slug {
+		return nil, nil
+	}
+	return r.tenant, nil
+}
+
+type memoryDeliveries struct {
+	byKey map[string]*Delivery
+}
+
+func (r *memoryDeliveries) Record(ctx context.Context, d *Delivery) (bool, error) {
+	key := d.TenantID + "/" + d.DeliveryID
+	if _, ok := r.byKey[key]; ok {
+		return false, nil
+	}
+	r.byKey[key] = d
+	return true, nil
+}
+
+func (r *memoryDeliveries) Delete(ctx context.Context, id string) error {
+	for key, d := range r.byKey {
+		if d.ID == id {
+			delete(r.byKey, key)
+		}
+	}
+	return nil
+}
+
+func (r *memoryDeliveries) PruneBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+type syncCall struct {
+	tenantID string
+	ticketID int
+}
+
+type recordingSyncer struct {
+	calls []syncCall
+	err   error
+}
+
+func (s *recordingSyncer) SyncTicket(ctx context.Context, tenantID string, ticketID int) error {
+	s.calls = append(s.calls, syncCall{tenantID, ticketID})
+	return s.err
+}
+
+type hookFixture struct {
+	router     *gin.Engine
+	deliveries *memoryDeliveries
+	syncer     *recordingSyncer
+}
+
+func newHookFixture() *hookFixture {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	f := &hookFixture{deliveries: &memoryDeliveries{byKey: map[string]*Delivery{}}, syncer: &recordingSyncer{}}
+	tenants := &memoryTenants{tenant: &tenant.Tenant{ID: "t1", Slug: "acme", WebhookSecret: testSecret}}
+	f.router = gin.New()
+	f.router.POST("/webhooks/invgate/:tenant", NewHandler(tenants, f.deliveries, f.syncer, logger).Receive)
+	return f
+}
+
+func (f *hookFixture) post(slug, body string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/invgate/"+slug, strings.NewReader(body))
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	f.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// sign returns the headers of a delivery signed at the given time.
+func sign(secret, delivery, body string, at time.Time) http.Header {
+	timestamp := strconv.FormatInt(at.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + delivery + "." + body))
+	return http.Header{
+		SignatureHeader:      {"sha256=" + hex.EncodeToString(mac.Sum(nil))},
+		TimestampHeader:      {timestamp},
+		"X-Webhook-Delivery": {delivery},
+	}
+}
+
+func TestReceiveVerifiesSecret(t *testing.T) {
+	f := newHookFixture()
+	body := `{"event":"incident.updated","incident_id":42}`
+	now := time.Now()
+	replayed := sign(testSecret, "d1", body, now)
+	replayed.Set("X-Webhook-Delivery", "d3")
+
+	cases := []struct {
+		name   string
+		slug   string
+		header http.Header
+		want   int
+	}{
+		{"valid signature", "acme", sign(testSecret, "d1", body, now), http.StatusOK},
+		{"valid token", "acme", http.Header{TokenHeader: {testSecret}, "X-Webhook-Delivery": {"d2"}}, http.StatusOK},
+		{"wrong signature", "acme", sign("other", "d1", body, now), http.StatusUnauthorized},
+		{"other delivery ID", "acme", replayed, http.StatusUnauthorized},
+		{"stale timestamp", "acme", sign(testSecret, "d4", body, now.Add(-time.Hour)), http.StatusUnauthorized},
+		{"wrong token", "acme", http.Header{TokenHeader: {"nope"}}, http.StatusUnauthorized},
+		{"no credentials", "acme", nil, http.StatusUnauthorized},
+		{"unknown tenant", "globex", sign(testSecret, "d1", body, now), http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		if rec := f.post(tc.slug, body, tc.header); rec.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d (%s)", tc.name, rec.Code, tc.want, rec.Body.String())
+		}
+	}
+	if len(f.syncer.calls) != 2 {
+		t.Errorf("synced %d times, want 2", len(f.syncer.calls))
+	}
+}
+
+func TestReceiveDropsRedeliveries(t *testing.T) {
+	f := newHookFixture()
+	body := `{"event":"comment_added","request":{"id":7},"comment":{"id":31}}`
+
+	for i := 0; i < 2; i++ {
+		rec := f.post("acme", body, sign(testSecret, "d1", body, time.Now()))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("delivery %d: status = %d", i, rec.Code)
+		}
+		if dup := strings.Contains(rec.Body.String(), `"duplicate":true`); dup != (i == 1) {
+			t.Errorf("delivery %d: duplicate = %v", i, dup)
+		}
+	}
+	if len(f.syncer.calls) != 1 {
+		t.Fatalf("synced %d times, want 1", len(f.syncer.calls))
+	}
+	if got := f.syncer.calls[0]; got.ticketID != 7 || got.tenantID != "t1" {
+		t.Errorf("sync call = %+v", got)
+	}
+}
+
+func TestReceiveRejectsTicketsOfOtherTenants(t *testing.T) {
+	f := newHookFixture()
+	f.syncer.err = notification.ErrTicketNotInTenant
+	body := `{"event":"incident.updated","incident_id":42}`
+
+	if rec := f.post("acme", body, sign(testSecret, "d1", body, time.Now())); rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+	if len(f.deliveries.byKey) != 0 {
+		t.Errorf("rejected delivery recorded: %+v", f.deliveries.byKey)
+	}
+}
+
+func TestReceiveReleasesFailedDelivery(t *testing.T) {
+	f := newHookFixture()
+	f.syncer.err = stdErrors.New("invgate down")
+	body := `{"event":"incident.updated","incident_id":42}`
+	header := sign(testSecret, "abc", body, time.Now())
+
+	if rec := f.post("acme", body, header); rec.Code != http.StatusBadGateway {
+		t.Fatalf("status = %d, want 502", rec.Code)
+	}
+	f.syncer.err = nil
+	if rec := f.post("acme", body, header); rec.Code != http.StatusOK || strings.Contains(rec.Body.String(), `"duplicate":true`) {
+		t.Fatalf("retry = %d %s", rec.Code, rec.Body.String())
+	}
+	if len(f.syncer.calls) != 2 {
+		t.Errorf("synced %d times, want 2", len(f.syncer.calls))
+	}
+}
+
+func TestReceiveIgnoresUnknownEvents(t *testing.T) {
+	f := newHookFixture()
+	body := `{"event":"user.created","id":3}`
+
+	if rec := f.post("acme", body, sign(testSecret, "d1", body, time.Now())); rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202", rec.Code)
+	}
+	if len(f.syncer.calls) != 0 {
+		t.Errorf("synced %d times, want 0", len(f.syncer.calls))
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	cases := []struct {
+		body string
+		want Event
+	}{
+		{`{"event":"incident.status_changed","incident_id":"12","previous_status_id":1}`,
+			Event{Kind: KindTicketUpdated, TicketID: 12}},
+		{`{"type":"NewComment","ticket":{"id":5},"comment_id":9,"delivery_id":"x1"}`,
+			Event{Kind: KindCommentAdded, TicketID: 5, DeliveryID: "x1"}},
+		{`{"event_type":"comment.added","request_id":8,"comment":{"id":4,"is_solution":true}}`,
+			Event{Kind: KindSolutionProposed, TicketID: 8}},
+		{`{"action":"solution_proposed","id":3}`,
+			Event{Kind: KindSolutionProposed, TicketID: 3}},
+		{`{"event":"INCIDENT_UPDATED","incident":{"id":"6"}}`,
+			Event{Kind: KindTicketUpdated, TicketID: 6}},
+		{`{"event":"user.deleted"}`, Event{}},
+	}
+	for _, tc := range cases {
+		got, err := ParseEvent([]byte(tc.body))
+		if err != nil || got != tc.want {
+			t.Errorf("ParseEvent(%s) = %+v, %v; want %+v", tc.body, got, err, tc.want)
+		}
+	}
+	if _, err := ParseEvent([]byte("not json")); err == nil {
+		t.Error("expected an error for a non-JSON body")
+	}
+}
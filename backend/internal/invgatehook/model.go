@@ -0,0 +1,147 @@
+package invgatehook
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Kinds of change a webhook can report.
+const (
+	KindTicketUpdated    = "ticket_updated"
+	KindCommentAdded     = "comment_added"
+	KindSolutionProposed = "solution_proposed"
+)
+
+// Delivery records a webhook that was accepted, so redeliveries of the
+// same event are ignored.
+type Delivery struct {
+	ID         string    `gorm:"type:char(36);primaryKey"`
+	TenantID   string    `gorm:"type:char(36);not null;uniqueIndex:idx_invgate_webhook_deliveries_tenant_delivery,priority:1"`
+	DeliveryID string    `gorm:"size:128;not null;uniqueIndex:idx_invgate_webhook_deliveries_tenant_delivery,priority:2"`
+	Kind       string    `gorm:"size:32;not null"`
+	TicketID   int       `gorm:"not null"`
+	CreatedAt  time.Time `gorm:"autoCreateTime;index"`
+}
+
+// TableName specifies the table name for GORM
+func (Delivery) TableName() string {
+	return "invgate_webhook_deliveries"
+}
+
+// Event is a webhook payload mapped to the change it reports. Only the
+// ticket ID is used to find the change: anything else the payload says
+// about the ticket is read back from InvGate instead of trusted.
+type Event struct {
+	Kind     string
+	TicketID int
+	// DeliveryID is the sender's ID for the event, when the payload has one.
+	DeliveryID string
+}
+
+// ParseEvent maps a webhook body to an Event. InvGate webhook bodies are
+// templates configured per trigger, so the common spellings of each field
+// are accepted. Kind is empty for events that are not handled.
+func ParseEvent(body []byte) (Event, error) {
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return Event{}, err
+	}
+
+	ev := Event{
+		Kind:       eventKind(stringField(raw, "event", "event_type", "type", "action")),
+		TicketID:   intField(raw, "incident_id", "request_id", "ticket_id"),
+		DeliveryID: stringField(raw, "delivery_id", "event_id"),
+	}
+	for _, key := range []string{"incident", "request", "ticket"} {
+		if obj, ok := raw[key].(map[string]any); ok && ev.TicketID == 0 {
+			ev.TicketID = intField(obj, "id")
+		}
+	}
+	if ev.TicketID == 0 {
+		ev.TicketID = intField(raw, "id")
+	}
+	if comment, ok := raw["comment"].(map[string]any); ok {
+		if solution, _ := comment["is_solution"].(bool); solution {
+			ev.Kind = KindSolutionProposed
+		}
+	}
+	return ev, nil
+}
+
+// eventKind maps event names such as "incident.comment.added" or
+// "TicketUpdated" to a kind. Changes to anything but tickets are not handled.
+func eventKind(name string) string {
+	words := strings.FieldsFunc(splitCamel(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	updated := false
+	for _, word := range words {
+		switch {
+		case strings.HasPrefix(word, "solution"):
+			return KindSolutionProposed
+		case strings.HasPrefix(word, "comment"):
+			return KindCommentAdded
+		case word == "created" || word == "updated" || word == "changed" || word == "status":
+			updated = true
+		case word == "incident" || word == "request" || word == "ticket" || word == "new":
+		default:
+			return ""
+		}
+	}
+	if updated {
+		return KindTicketUpdated
+	}
+	return ""
+}
+
+// splitCamel lowercases name, separating camel-case words with spaces.
+func splitCamel(name string) string {
+	var b strings.Builder
+	prevLower := false
+	for _, r := range name {
+		if unicode.IsUpper(r) && prevLower {
+			b.WriteByte(' ')
+		}
+		prevLower = unicode.IsLower(r)
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+func stringField(obj map[string]any, keys ...string) string {
+	for _, key := range keys {
+		switch v := obj[key].(type) {
+		case string:
+			if v != "" {
+				return v
+			}
+		case json.Number:
+			return v.String()
+		}
+	}
+	return ""
+}
+
+func intField(obj map[string]any, keys ...string) int {
+	for _, key := range keys {
+		var s string
+		switch v := obj[key].(type) {
+		case json.Number:
+			s = v.String()
+		case string:
+			s = strings.TrimSpace(v)
+		default:
+			continue
+		}
+		if n, err := strconv.Atoi(s); err == nil && n > 0 {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,64 @@
+package invgatehook
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"werk-ticketing/internal/constants"
+)
+
+// Repository stores accepted webhook deliveries
+type Repository interface {
+	// Record stores a delivery and reports false when the tenant already
+	// sent one with the same delivery ID.
+	Record(ctx context.Context, delivery *Delivery) (bool, error)
+	// Delete forgets a delivery so that a redelivery is processed again.
+	Delete(ctx context.Context, id string) error
+	PruneBefore(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new webhook delivery repository
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) Record(ctx context.Context, delivery *Delivery) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(delivery)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *gormRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&Delivery{}, "id = ?", id).Error
+}
+
+func (r *gormRepository) PruneBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Delete(&Delivery{}, "created_at < ?", cutoff)
+	return result.RowsAffected, result.Error
+}
+
+// PruneDeliveries drops delivery records past the dedupe window
+// periodically until ctx is cancelled.
+func PruneDeliveries(ctx context.Context, repo Repository, logger *logrus.Logger) {
+	ticker := time.NewTicker(constants.InvGateWebhookPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if removed, err := repo.PruneBefore(ctx, now.Add(-constants.InvGateWebhookDedupeWindow)); err != nil {
+				logger.WithError(err).Warn("webhook delivery prune failed")
+			} else if removed > 0 {
+				logger.WithField("removed", removed).Info("old webhook deliveries pruned")
+			}
+		}
+	}
+}
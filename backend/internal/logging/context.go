@@ -0,0 +1,77 @@
+// Package logging carries the request ID and a request-scoped log entry
+// through contexts, from the HTTP middleware down to outbound calls.
+package logging
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RequestIDHeader carries the request ID to and from clients and on calls
+// to InvGate.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+type entryKey struct{}
+
+// WithRequestID returns a context carrying the request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID stored in ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithEntry returns a context carrying a log entry for the request.
+func WithEntry(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, entryKey{}, entry)
+}
+
+// WithFields returns a context whose log entry has the given fields added.
+// It returns ctx unchanged when ctx has no entry.
+func WithFields(ctx context.Context, fields logrus.Fields) context.Context {
+	entry, ok := ctx.Value(entryKey{}).(*logrus.Entry)
+	if !ok {
+		return ctx
+	}
+	return WithEntry(ctx, entry.WithFields(fields))
+}
+
+// FromContext returns the request's log entry, which carries the request,
+// tenant and user fields known so far. Outside a request it returns an
+// entry of fallback without fields.
+func FromContext(ctx context.Context, fallback *logrus.Logger) *logrus.Entry {
+	if entry, ok := ctx.Value(entryKey{}).(*logrus.Entry); ok {
+		return entry
+	}
+	return logrus.NewEntry(fallback)
+}
+
+// Transport sets the X-Request-ID header on outgoing requests whose context
+// carries a request ID, so the receiver's logs can be correlated with ours.
+type Transport struct {
+	// Base sends the requests; nil means http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	id := RequestID(req.Context())
+	if id == "" || req.Header.Get(RequestIDHeader) != "" {
+		return base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the caller's request.
+	req = req.Clone(req.Context())
+	req.Header.Set(RequestIDHeader, id)
+	return base.RoundTrip(req)
+}
@@ -0,0 +1,117 @@
+// Package metrics defines the application's Prometheus collectors and the
+// handler serving them on /metrics.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "werk"
+
+// Registry holds every collector served by Handler. A dedicated registry
+// keeps collectors registered by libraries out of our endpoint.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequestDuration observes API requests by route template, method,
+	// status code and tenant ("none" outside tenant routes).
+	HTTPRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of HTTP requests by route, method, status and tenant.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status", "tenant"})
+
+	// InvGateRequests counts InvGate API attempts by endpoint, method and
+	// status code ("error" when no response was received).
+	InvGateRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "invgate",
+		Name:      "requests_total",
+		Help:      "InvGate API attempts by endpoint, method and status.",
+	}, []string{"endpoint", "method", "status"})
+
+	// InvGateRequestDuration observes InvGate API attempts until the
+	// response headers arrive.
+	InvGateRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "invgate",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of InvGate API attempts by endpoint and method.",
+		Buckets:   []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 15, 30},
+	}, []string{"endpoint", "method"})
+
+	// InvGateRetries counts attempts beyond the first.
+	InvGateRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "invgate",
+		Name:      "retries_total",
+		Help:      "InvGate API retries by endpoint and method.",
+	}, []string{"endpoint", "method"})
+
+	// RateLimitRejections counts requests refused by a rate limiter
+	// ("global", "tenant" or "article").
+	RateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "ratelimit",
+		Name:      "rejections_total",
+		Help:      "Requests rejected by the rate limiters.",
+	}, []string{"limiter"})
+
+	// TenantCacheLookups counts tenant lookups by result ("hit" or "miss");
+	// the hit ratio is hit / (hit + miss).
+	TenantCacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "tenant_cache",
+		Name:      "lookups_total",
+		Help:      "Tenant cache lookups by result.",
+	}, []string{"result"})
+
+	// EmailSends counts emails by template and outcome: "queued", "sent",
+	// "retry", "dead" or, for unqueued sends, "failed".
+	EmailSends = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "email",
+		Name:      "sends_total",
+		Help:      "Emails by template and outcome.",
+	}, []string{"template", "outcome"})
+)
+
+// Email send outcomes.
+const (
+	EmailQueued = "queued"
+	EmailSent   = "sent"
+	EmailRetry  = "retry"
+	EmailDead   = "dead"
+	EmailFailed = "failed"
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequestDuration,
+		InvGateRequests,
+		InvGateRequestDuration,
+		InvGateRetries,
+		RateLimitRejections,
+		TenantCacheLookups,
+		EmailSends,
+	)
+}
+
+// RegisterDB exposes the connection pool statistics of db.
+func RegisterDB(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, "mysql"))
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/audit"
+	"werk-ticketing/internal/logging"
+)
+
+// AuditContext stores the client's IP, user agent and request ID in the
+// request context so services can record them with audit events. It runs
+// after RequestID.
+func AuditContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := audit.WithRequest(c.Request.Context(), audit.Request{
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+			RequestID: logging.RequestID(c.Request.Context()),
+		})
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
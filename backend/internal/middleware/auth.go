@@ -5,9 +5,12 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/auth"
 	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/logging"
 	"werk-ticketing/internal/response"
 )
 
@@ -39,6 +42,9 @@ func WithAuth(authService auth.Service) gin.HandlerFunc {
 		}
 
 		c.Set(userEmailKey, claims.Subject)
+		ctx := audit.WithActor(c.Request.Context(), claims.Subject)
+		ctx = logging.WithFields(ctx, logrus.Fields{"userEmail": claims.Subject})
+		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 	}
 }
@@ -1,65 +1,75 @@
 package middleware
 
 import (
-	"fmt"
+	"math/rand/v2"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/response"
+	"werk-ticketing/internal/tracing"
 )
 
-// Logging logs incoming HTTP requests with latency.
-func Logging(logger *logrus.Logger) gin.HandlerFunc {
+// Logging writes a JSON access log line per request, whatever the format of
+// the application log. Failed and slow requests are always logged; others
+// are logged with probability sampleRate (1 logs everything).
+func Logging(logger *logrus.Logger, sampleRate float64) gin.HandlerFunc {
+	access := logrus.New()
+	access.SetOutput(logger.Out)
+	access.SetLevel(logger.GetLevel())
+	access.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+
 	return func(c *gin.Context) {
 		start := time.Now()
 		c.Next()
-		duration := time.Since(start)
+		latency := time.Since(start)
 		status := c.Writer.Status()
-		coloredStatus := fmt.Sprintf("%s%d%s", statusColor(status), status, colorReset)
-		coloredMethod := fmt.Sprintf("%s%-7s%s", methodColor(c.Request.Method), c.Request.Method, colorReset)
-
-		logger.Infof("%s %s %s (%s)",
-			coloredStatus,
-			coloredMethod,
-			c.Request.URL.Path,
-			duration.String(),
-		)
-	}
-}
 
-const (
-	colorReset   = "\033[0m"
-	colorRed     = "\033[31m"
-	colorGreen   = "\033[32m"
-	colorYellow  = "\033[33m"
-	colorCyan    = "\033[36m"
-	colorMagenta = "\033[35m"
-)
+		if status < 400 && latency < constants.AccessLogSlowRequest && rand.Float64() >= sampleRate {
+			return
+		}
 
-func statusColor(status int) string {
-	switch {
-	case status >= 500:
-		return colorRed
-	case status >= 400:
-		return colorYellow
-	case status >= 300:
-		return colorCyan
-	default:
-		return colorGreen
-	}
-}
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		fields := logrus.Fields{
+			"requestID": GetRequestID(c),
+			"method":    c.Request.Method,
+			"route":     route,
+			"path":      c.Request.URL.Path,
+			"status":    status,
+			"latencyMs": float64(latency.Microseconds()) / 1000,
+			"bytes":     c.Writer.Size(),
+			"clientIP":  c.ClientIP(),
+			"userAgent": c.Request.UserAgent(),
+		}
+		if tenantID := GetTenantID(c); tenantID != "" {
+			fields["tenantID"] = tenantID
+		}
+		if email := GetUserEmail(c); email != "" {
+			fields["userEmail"] = email
+		}
+		if traceID := tracing.TraceID(c.Request.Context()); traceID != "" {
+			fields["traceID"] = traceID
+		}
+		if code := response.GetErrorCode(c); code != "" {
+			fields["errorCode"] = code
+		}
+		if len(c.Errors) > 0 {
+			fields["errors"] = c.Errors.String()
+		}
 
-func methodColor(method string) string {
-	switch method {
-	case "GET":
-		return colorCyan
-	case "POST":
-		return colorGreen
-	case "PUT":
-		return colorYellow
-	case "DELETE":
-		return colorRed
-	default:
-		return colorMagenta
+		entry := access.WithFields(fields)
+		switch {
+		case status >= 500:
+			entry.Error("request")
+		case status >= 400:
+			entry.Warn("request")
+		default:
+			entry.Info("request")
+		}
 	}
 }
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/metrics"
+	"werk-ticketing/internal/response"
+)
+
+// Metrics records each request's duration labelled by route template,
+// status and tenant. Paths matching no route share one label so scans
+// cannot grow the series count.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		tenantID := GetTenantID(c)
+		if tenantID == "" {
+			tenantID = "none"
+		}
+		metrics.HTTPRequestDuration.
+			WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status()), tenantID).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+// MetricsAuth protects the metrics endpoint with a static bearer token.
+// An empty token leaves the endpoint open, for deployments that only
+// expose it on an internal network.
+func MetricsAuth(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.Next()
+			return
+		}
+		got := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			response.ErrorWithCode(c, http.StatusUnauthorized, errors.ErrCodeUnauthorized, "invalid metrics token")
+			return
+		}
+		c.Next()
+	}
+}
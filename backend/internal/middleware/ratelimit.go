@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/metrics"
 	"werk-ticketing/internal/response"
 
 	"github.com/gin-gonic/gin"
@@ -170,6 +171,7 @@ func RateLimit() gin.HandlerFunc {
 		ip := c.ClientIP()
 
 		if !globalRateLimiter.allow(ip) {
+			metrics.RateLimitRejections.WithLabelValues("global").Inc()
 			response.Error(c, 429, "too many requests")
 			c.Abort()
 			return
@@ -189,6 +191,7 @@ func TenantRateLimit() gin.HandlerFunc {
 			// No tenant, fall back to global rate limiting by IP
 			ip := c.ClientIP()
 			if !globalRateLimiter.allow(ip) {
+				metrics.RateLimitRejections.WithLabelValues("global").Inc()
 				response.Error(c, 429, "too many requests")
 				c.Abort()
 				return
@@ -203,6 +206,7 @@ func TenantRateLimit() gin.HandlerFunc {
 
 		rl := getTenantRateLimiter(tenantID)
 		if !rl.allow(key) {
+			metrics.RateLimitRejections.WithLabelValues("tenant").Inc()
 			response.Error(c, 429, "too many requests for this tenant")
 			c.Abort()
 			return
@@ -218,6 +222,7 @@ func ArticleRateLimit() gin.HandlerFunc {
 		ip := c.ClientIP()
 
 		if !articleRateLimiter.allow(ip) {
+			metrics.RateLimitRejections.WithLabelValues("article").Inc()
 			response.Error(c, 429, "too many requests")
 			c.Abort()
 			return
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/logging"
 	"werk-ticketing/internal/response"
 )
 
@@ -14,7 +15,7 @@ func Recover(logger *logrus.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				logger.WithField("panic", err).Error("panic recovered")
+				logging.FromContext(c.Request.Context(), logger).WithField("panic", err).Error("panic recovered")
 				// Use consistent error format
 				response.ErrorWithCode(
 					c,
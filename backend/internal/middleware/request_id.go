@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/logging"
+)
+
+const requestIDKey = "requestID"
+
+// RequestID tags each request with an ID, taken from the client's
+// X-Request-ID header when it is sensible and generated otherwise. The ID is
+// echoed in the response header and stored in the request context together
+// with a log entry carrying it; see the logging package.
+func RequestID(logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(logging.RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.New().String()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(logging.RequestIDHeader, id)
+
+		ctx := logging.WithRequestID(c.Request.Context(), id)
+		ctx = logging.WithEntry(ctx, logger.WithField("requestID", id))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the ID of the current request.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(requestIDKey)
+}
+
+// validRequestID accepts short IDs of URL-safe characters, so a client
+// cannot inject arbitrary text into logs and upstream headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > constants.RequestIDMaxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/logging"
+	"werk-ticketing/internal/response"
+	"werk-ticketing/internal/user"
+)
+
+// RequireRole allows the request only when the authenticated user's role
+// grants at least role. It must run after WithTenant and WithAuth; the role
+// is looked up on every request so a demotion takes effect immediately.
+func RequireRole(users user.Repository, role string, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		email := GetUserEmail(c)
+		if email == "" {
+			response.ErrorWithCode(c, http.StatusUnauthorized, errors.ErrCodeUnauthorized, "authentication required")
+			return
+		}
+
+		u, err := users.GetByEmail(c.Request.Context(), GetTenantID(c), email)
+		if err != nil {
+			logging.FromContext(c.Request.Context(), logger).WithError(err).Error("failed to load user role")
+			response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to check permissions")
+			return
+		}
+		if u == nil || !u.HasRole(role) {
+			response.ErrorWithCode(c, http.StatusForbidden, errors.ErrCodeForbidden, "insufficient permissions")
+			return
+		}
+		c.Next()
+	}
+}
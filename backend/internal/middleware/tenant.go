@@ -7,8 +7,11 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
 
 	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/logging"
+	"werk-ticketing/internal/metrics"
 	"werk-ticketing/internal/response"
 	"werk-ticketing/internal/tenant"
 )
@@ -96,8 +99,11 @@ func WithTenant(tenantRepo tenant.Repository, method TenantIdentificationMethod)
 			}
 		}
 
-		// Fetch from DB if not cached
-		if t == nil {
+		// Fetch from DB on a cache miss
+		if t != nil {
+			metrics.TenantCacheLookups.WithLabelValues("hit").Inc()
+		} else {
+			metrics.TenantCacheLookups.WithLabelValues("miss").Inc()
 			if useSlug {
 				t, err = tenantRepo.FindBySlug(c.Request.Context(), slug)
 			} else {
@@ -133,6 +139,8 @@ func WithTenant(tenantRepo tenant.Repository, method TenantIdentificationMethod)
 		// Set tenant ID and object in context for use by handlers
 		c.Set(tenantIDKey, t.ID)
 		c.Set(tenantObjKey, t)
+		ctx := tenant.WithID(c.Request.Context(), t.ID)
+		c.Request = c.Request.WithContext(logging.WithFields(ctx, logrus.Fields{"tenantID": t.ID}))
 		c.Next()
 	}
 }
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/attachment"
+	"werk-ticketing/internal/constants"
+)
+
+// UploadDeadlines lifts the server's ReadTimeout and WriteTimeout for routes
+// accepting large multipart bodies. The read deadline moves forward while
+// the client keeps sending, so a slow link is only cut off when it stalls,
+// and the response may be written up to UploadForwardTimeout after the last
+// byte arrived, which leaves time to pass the files on to InvGate. The body
+// is limited to what the tenant's attachment policy allows, and the upload
+// may not take longer than UploadMaxDuration in total.
+func UploadDeadlines() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body := &deadlineBody{
+			ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, uploadBodyLimit(c)),
+			controller: http.NewResponseController(c.Writer),
+			cutoff:     time.Now().Add(constants.UploadMaxDuration),
+		}
+		body.extend()
+		c.Request.Body = body
+		c.Next()
+	}
+}
+
+// uploadBodyLimit is the largest body an upload may send: as many files of
+// the maximum size as the tenant's attachment policy allows, plus
+// MaxRequestSize for the other form fields and the multipart framing.
+func uploadBodyLimit(c *gin.Context) int64 {
+	policy := (*attachment.Policy)(nil).Resolve(attachment.DefaultTicketTypes)
+	if t := GetTenant(c); t != nil {
+		policy = t.TicketAttachmentPolicy()
+	}
+	return policy.MaxFileSize*int64(policy.MaxFiles) + constants.MaxRequestSize
+}
+
+// deadlineBody extends the connection deadlines on every read, up to cutoff.
+type deadlineBody struct {
+	io.ReadCloser
+	controller *http.ResponseController
+	cutoff     time.Time
+}
+
+func (b *deadlineBody) Read(p []byte) (int, error) {
+	b.extend()
+	return b.ReadCloser.Read(p)
+}
+
+func (b *deadlineBody) extend() {
+	// Not every ResponseWriter supports deadlines (e.g. in tests); ignore the error.
+	deadline := time.Now().Add(constants.UploadIdleTimeout)
+	if deadline.After(b.cutoff) {
+		deadline = b.cutoff
+	}
+	_ = b.controller.SetReadDeadline(deadline)
+	_ = b.controller.SetWriteDeadline(deadline.Add(constants.UploadForwardTimeout))
+}
@@ -0,0 +1,137 @@
+package notification
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/response"
+)
+
+// Handler handles HTTP requests for notification preferences and the
+// in-portal notification center
+type Handler struct {
+	service Service
+	inbox   Inbox
+}
+
+// NewHandler creates a new notification handler
+func NewHandler(service Service, inbox Inbox) *Handler {
+	return &Handler{service: service, inbox: inbox}
+}
+
+// GetPreferences handles GET /notification-preferences
+func (h *Handler) GetPreferences(c *gin.Context) {
+	prefs, err := h.service.Preferences(c.Request.Context(), middleware.GetTenantID(c), middleware.GetUserEmail(c))
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, prefs)
+}
+
+// UpdatePreferences handles PUT /notification-preferences
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	var req UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "email_enabled is required")
+		return
+	}
+
+	tenantID, email := middleware.GetTenantID(c), middleware.GetUserEmail(c)
+	if err := h.service.SetEmailEnabled(c.Request.Context(), tenantID, email, *req.EmailEnabled); err != nil {
+		h.error(c, err)
+		return
+	}
+	h.GetPreferences(c)
+}
+
+// UpdateTicketSubscription handles PUT /tickets/:id/subscription
+func (h *Handler) UpdateTicketSubscription(c *gin.Context) {
+	ticketID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || ticketID <= 0 {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid ticket id")
+		return
+	}
+
+	var req TicketSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "subscribed is required")
+		return
+	}
+
+	tenantID, email := middleware.GetTenantID(c), middleware.GetUserEmail(c)
+	if err := h.service.SetTicketSubscription(c.Request.Context(), tenantID, email, ticketID, *req.Subscribed); err != nil {
+		h.error(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, gin.H{"ticket_id": ticketID, "subscribed": *req.Subscribed})
+}
+
+// Unsubscribe handles POST /email/unsubscribe. It needs no login: the
+// signed token from the email identifies the user.
+func (h *Handler) Unsubscribe(c *gin.Context) {
+	var req UnsubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "token is required")
+		return
+	}
+
+	if err := h.service.UnsubscribeByToken(c.Request.Context(), req.Token); err != nil {
+		h.error(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, gin.H{"message": "unsubscribed successfully"})
+}
+
+// ListNotifications handles GET /notifications
+func (h *Handler) ListNotifications(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+	unreadOnly := c.Query("unread") == "true"
+
+	page, err := h.inbox.List(c.Request.Context(), middleware.GetTenantID(c), middleware.GetUserEmail(c), c.Query("cursor"), limit, unreadOnly)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, page)
+}
+
+// MarkNotificationRead handles POST /notifications/:id/read
+func (h *Handler) MarkNotificationRead(c *gin.Context) {
+	n, err := h.inbox.MarkRead(c.Request.Context(), middleware.GetTenantID(c), middleware.GetUserEmail(c), c.Param("id"))
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, n)
+}
+
+// MarkAllNotificationsRead handles POST /notifications/read-all
+func (h *Handler) MarkAllNotificationsRead(c *gin.Context) {
+	marked, err := h.inbox.MarkAllRead(c.Request.Context(), middleware.GetTenantID(c), middleware.GetUserEmail(c))
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+	response.Success(c, http.StatusOK, gin.H{"marked": marked})
+}
+
+func (h *Handler) error(c *gin.Context, err error) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, err.Error())
+}
@@ -0,0 +1,243 @@
+package notification
+
+import (
+	"context"
+	"encoding/base64"
+	stdErrors "errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/tenant"
+	"werk-ticketing/internal/user"
+)
+
+// Inbox keeps the in-portal notification center. It is filled from the same
+// ticket events as the update emails, but regardless of email preferences.
+type Inbox interface {
+	// Dispatch adds an event to the notification center of each of the
+	// requester's accounts.
+	Dispatch(ctx context.Context, ev Event) error
+
+	// List returns a page of the user's notifications, newest first. cursor
+	// is the NextCursor of the previous page, or empty for the first page.
+	List(ctx context.Context, tenantID, email, cursor string, limit int, unreadOnly bool) (*NotificationPage, error)
+	MarkRead(ctx context.Context, tenantID, email, id string) (*Notification, error)
+	// MarkAllRead marks every unread notification read and returns how many
+	// there were.
+	MarkAllRead(ctx context.Context, tenantID, email string) (int64, error)
+
+	// Prune deletes notifications older than their tenant's retention.
+	Prune(ctx context.Context, now time.Time) error
+}
+
+// InboxCursor is the position of the last notification on a page.
+type InboxCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type inbox struct {
+	repo    InboxRepository
+	users   user.Repository
+	tenants tenant.Repository
+	logger  *logrus.Logger
+	now     func() time.Time
+}
+
+// NewInbox creates the notification center service
+func NewInbox(repo InboxRepository, users user.Repository, tenants tenant.Repository, logger *logrus.Logger) Inbox {
+	return &inbox{repo: repo, users: users, tenants: tenants, logger: logger, now: time.Now}
+}
+
+func (s *inbox) Dispatch(ctx context.Context, ev Event) error {
+	accounts, err := s.users.ListByInvGateUserID(ctx, ev.RequesterID)
+	if err != nil {
+		return fmt.Errorf("failed to find requester: %w", err)
+	}
+
+	// Rows are stored with millisecond precision, which the cursor relies on.
+	createdAt := s.now().UTC().Truncate(time.Millisecond)
+	var notifications []*Notification
+	for _, u := range accounts {
+		// Inactive tenants are not found, so their users get no notifications.
+		t, err := s.tenants.FindByID(ctx, u.TenantID)
+		if err != nil {
+			return fmt.Errorf("failed to find tenant %s: %w", u.TenantID, err)
+		}
+		if t == nil {
+			continue
+		}
+		notifications = append(notifications, &Notification{
+			ID:       uuid.New().String(),
+			TenantID: t.ID,
+			UserID:   u.ID,
+			Type:     ev.Type,
+			TicketID: ev.TicketID,
+			Payload: NotificationPayload{
+				TicketTitle: ev.TicketTitle,
+				StatusID:    ev.StatusID,
+				CommentID:   ev.CommentID,
+				Comment:     ev.Comment,
+			},
+			CreatedAt: createdAt,
+		})
+	}
+	return s.repo.Add(ctx, notifications...)
+}
+
+func (s *inbox) List(ctx context.Context, tenantID, email, cursor string, limit int, unreadOnly bool) (*NotificationPage, error) {
+	u, err := s.currentUser(ctx, tenantID, email)
+	if err != nil {
+		return nil, err
+	}
+
+	var after *InboxCursor
+	if cursor != "" {
+		if after, err = decodeInboxCursor(cursor); err != nil {
+			return nil, errors.NewAppError(errors.ErrCodeInvalidInput, "invalid cursor", err)
+		}
+	}
+	if limit <= 0 {
+		limit = constants.NotificationPageSize
+	}
+	limit = min(limit, constants.NotificationMaxPageSize)
+
+	// Fetch one extra row to learn whether there is a next page.
+	notifications, err := s.repo.List(ctx, u.ID, after, limit+1, unreadOnly)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInternal, "failed to load notifications", err)
+	}
+	unread, err := s.repo.CountUnread(ctx, u.ID)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInternal, "failed to load notifications", err)
+	}
+
+	page := &NotificationPage{Data: notifications, UnreadCount: unread}
+	if len(notifications) > limit {
+		page.Data = notifications[:limit]
+		page.NextCursor = encodeInboxCursor(page.Data[limit-1])
+	}
+	if page.Data == nil {
+		page.Data = []*Notification{}
+	}
+	return page, nil
+}
+
+func (s *inbox) MarkRead(ctx context.Context, tenantID, email, id string) (*Notification, error) {
+	u, err := s.currentUser(ctx, tenantID, email)
+	if err != nil {
+		return nil, err
+	}
+	n, err := s.repo.FindByID(ctx, u.ID, id)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInternal, "failed to load notification", err)
+	}
+	if n == nil {
+		return nil, errors.NewAppError(errors.ErrCodeNotFound, "notification not found", nil)
+	}
+	if n.ReadAt != nil {
+		return n, nil
+	}
+
+	now := s.now().UTC()
+	if err := s.repo.MarkRead(ctx, n.ID, now); err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInternal, "failed to update notification", err)
+	}
+	n.ReadAt = &now
+	return n, nil
+}
+
+func (s *inbox) MarkAllRead(ctx context.Context, tenantID, email string) (int64, error) {
+	u, err := s.currentUser(ctx, tenantID, email)
+	if err != nil {
+		return 0, err
+	}
+	marked, err := s.repo.MarkAllRead(ctx, u.ID, s.now().UTC())
+	if err != nil {
+		return 0, errors.NewAppError(errors.ErrCodeInternal, "failed to update notifications", err)
+	}
+	return marked, nil
+}
+
+func (s *inbox) Prune(ctx context.Context, now time.Time) error {
+	// Inactive tenants keep their retention too, in case they come back.
+	tenants, err := s.tenants.FindAllIncludingInactive(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tenants: %w", err)
+	}
+
+	var errs []error
+	for _, t := range tenants {
+		cutoff := now.UTC().Add(-t.NotificationSettings().Retention())
+		pruned, err := s.repo.PruneBefore(ctx, t.ID, cutoff)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t.ID, err))
+			continue
+		}
+		if pruned > 0 {
+			s.logger.WithFields(logrus.Fields{
+				"tenantID": t.ID,
+				"pruned":   pruned,
+			}).Info("pruned old notifications")
+		}
+	}
+	return stdErrors.Join(errs...)
+}
+
+func (s *inbox) currentUser(ctx context.Context, tenantID, email string) (*user.User, error) {
+	u, err := s.users.GetByEmail(ctx, tenantID, email)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInternal, "failed to fetch user information", err)
+	}
+	if u == nil {
+		return nil, errors.NewAppError(errors.ErrCodeNotFound, "user not found", nil)
+	}
+	return u, nil
+}
+
+// encodeInboxCursor returns an opaque cursor for the position of n.
+func encodeInboxCursor(n *Notification) string {
+	raw := strconv.FormatInt(n.CreatedAt.UnixMilli(), 10) + "_" + n.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeInboxCursor(cursor string) (*InboxCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	millis, id, ok := strings.Cut(string(raw), "_")
+	if !ok || id == "" {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	ms, err := strconv.ParseInt(millis, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &InboxCursor{CreatedAt: time.UnixMilli(ms).UTC(), ID: id}, nil
+}
+
+// RunInboxPrune deletes expired notifications periodically until ctx is
+// cancelled.
+func RunInboxPrune(ctx context.Context, inbox Inbox, logger *logrus.Logger) {
+	ticker := time.NewTicker(constants.NotificationPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := inbox.Prune(ctx, now); err != nil {
+				logger.WithError(err).Error("failed to prune notifications")
+			}
+		}
+	}
+}
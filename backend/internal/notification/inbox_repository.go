@@ -0,0 +1,93 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// InboxRepository stores in-portal notifications
+type InboxRepository interface {
+	Add(ctx context.Context, notifications ...*Notification) error
+	// List returns up to limit of the user's notifications, newest first,
+	// starting after the cursor position when after is not nil.
+	List(ctx context.Context, userID string, after *InboxCursor, limit int, unreadOnly bool) ([]*Notification, error)
+	CountUnread(ctx context.Context, userID string) (int64, error)
+	// FindByID returns the user's notification, or nil if the user has none
+	// with that ID.
+	FindByID(ctx context.Context, userID, id string) (*Notification, error)
+	MarkRead(ctx context.Context, id string, at time.Time) error
+	MarkAllRead(ctx context.Context, userID string, at time.Time) (int64, error)
+	// PruneBefore deletes the tenant's notifications created before cutoff.
+	PruneBefore(ctx context.Context, tenantID string, cutoff time.Time) (int64, error)
+}
+
+type gormInboxRepository struct {
+	db *gorm.DB
+}
+
+// NewInboxRepository creates a new notification center repository
+func NewInboxRepository(db *gorm.DB) InboxRepository {
+	return &gormInboxRepository{db: db}
+}
+
+func (r *gormInboxRepository) Add(ctx context.Context, notifications ...*Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(notifications).Error
+}
+
+func (r *gormInboxRepository) List(ctx context.Context, userID string, after *InboxCursor, limit int, unreadOnly bool) ([]*Notification, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if after != nil {
+		query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", after.CreatedAt, after.CreatedAt, after.ID)
+	}
+
+	var notifications []*Notification
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *gormInboxRepository) CountUnread(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *gormInboxRepository) FindByID(ctx context.Context, userID, id string) (*Notification, error) {
+	var n Notification
+	err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&n).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &n, nil
+}
+
+func (r *gormInboxRepository) MarkRead(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&Notification{}).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", at).Error
+}
+
+func (r *gormInboxRepository) MarkAllRead(ctx context.Context, userID string, at time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Model(&Notification{}).
+		Where("user_id = ? AND read_at IS NULL", userID).
+		Update("read_at", at)
+	return result.RowsAffected, result.Error
+}
+
+func (r *gormInboxRepository) PruneBefore(ctx context.Context, tenantID string, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).Delete(&Notification{}, "tenant_id = ? AND created_at < ?", tenantID, cutoff)
+	return result.RowsAffected, result.Error
+}
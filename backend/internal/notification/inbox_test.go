@@ -0,0 +1,187 @@
+package notification
+
+import (
+	"context"
+	"io"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/tenant"
+	"werk-ticketing/internal/user"
+)
+
+type memoryInbox struct {
+	InboxRepository
+	notifications []*Notification
+}
+
+func (r *memoryInbox) Add(ctx context.Context, notifications ...*Notification) error {
+	r.notifications = append(r.notifications, notifications...)
+	return nil
+}
+
+func (r *memoryInbox) List(ctx context.Context, userID string, after *InboxCursor, limit int, unreadOnly bool) ([]*Notification, error) {
+	var found []*Notification
+	for _, n := range r.notifications {
+		if n.UserID != userID || (unreadOnly && n.ReadAt != nil) {
+			continue
+		}
+		if after != nil && !(n.CreatedAt.Before(after.CreatedAt) || (n.CreatedAt.Equal(after.CreatedAt) && n.ID < after.ID)) {
+			continue
+		}
+		found = append(found, n)
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if !found[i].CreatedAt.Equal(found[j].CreatedAt) {
+			return found[i].CreatedAt.After(found[j].CreatedAt)
+		}
+		return found[i].ID > found[j].ID
+	})
+	return found[:min(limit, len(found))], nil
+}
+
+func (r *memoryInbox) CountUnread(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	for _, n := range r.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryInbox) FindByID(ctx context.Context, userID, id string) (*Notification, error) {
+	for _, n := range r.notifications {
+		if n.UserID == userID && n.ID == id {
+			return n, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryInbox) MarkRead(ctx context.Context, id string, at time.Time) error {
+	for _, n := range r.notifications {
+		if n.ID == id {
+			n.ReadAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *memoryInbox) PruneBefore(ctx context.Context, tenantID string, cutoff time.Time) (int64, error) {
+	kept := r.notifications[:0]
+	for _, n := range r.notifications {
+		if n.TenantID != tenantID || !n.CreatedAt.Before(cutoff) {
+			kept = append(kept, n)
+		}
+	}
+	pruned := int64(len(r.notifications) - len(kept))
+	r.notifications = kept
+	return pruned, nil
+}
+
+func (r *memoryUsers) GetByEmail(ctx context.Context, tenantID, email string) (*user.User, error) {
+	for _, u := range r.users {
+		if u.TenantID == tenantID && u.Email == email {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryTenants) FindAllIncludingInactive(ctx context.Context) ([]*tenant.Tenant, error) {
+	return []*tenant.Tenant{r.tenant}, nil
+}
+
+func newTestInbox(settings *tenant.NotificationSettings) (*inbox, *memoryInbox, *time.Time) {
+	repo := &memoryInbox{}
+	users := &memoryUsers{users: []*user.User{{ID: "u1", TenantID: "t1", Email: "budi@acme.co.id", InvGateUserID: 7}}}
+	tenants := &memoryTenants{tenant: &tenant.Tenant{ID: "t1", IsActive: true, Notifications: settings}}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	svc := NewInbox(repo, users, tenants, logger).(*inbox)
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, repo, &now
+}
+
+func TestInboxIgnoresEmailPreferences(t *testing.T) {
+	svc, repo, _ := newTestInbox(&tenant.NotificationSettings{Digest: tenant.DigestOff})
+	if err := svc.Dispatch(context.Background(), testEvent); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if len(repo.notifications) != 1 {
+		t.Fatalf("stored %d notifications", len(repo.notifications))
+	}
+	n := repo.notifications[0]
+	if n.UserID != "u1" || n.TenantID != "t1" || n.TicketID != 5 || n.Payload.TicketTitle != "VPN" || n.Payload.StatusID != 5 {
+		t.Errorf("notification = %+v", n)
+	}
+}
+
+func TestInboxPagesAndMarksRead(t *testing.T) {
+	svc, _, now := newTestInbox(nil)
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		*now = now.Add(time.Minute)
+		svc.Dispatch(ctx, Event{Type: EventCommentAdded, TicketID: i, RequesterID: 7})
+	}
+
+	var tickets []int
+	cursor := ""
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatal("pagination did not end")
+		}
+		page, err := svc.List(ctx, "t1", "budi@acme.co.id", cursor, 2, false)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if page.UnreadCount != 5 {
+			t.Errorf("unread = %d", page.UnreadCount)
+		}
+		for _, n := range page.Data {
+			tickets = append(tickets, n.TicketID)
+		}
+		if cursor = page.NextCursor; cursor == "" {
+			break
+		}
+	}
+	if len(tickets) != 5 || tickets[0] != 5 || tickets[4] != 1 {
+		t.Fatalf("listed tickets %v, want newest first", tickets)
+	}
+
+	first, _ := svc.List(ctx, "t1", "budi@acme.co.id", "", 1, false)
+	if _, err := svc.MarkRead(ctx, "t1", "budi@acme.co.id", first.Data[0].ID); err != nil {
+		t.Fatalf("MarkRead: %v", err)
+	}
+	unread, _ := svc.List(ctx, "t1", "budi@acme.co.id", "", 0, true)
+	if unread.UnreadCount != 4 || len(unread.Data) != 4 {
+		t.Errorf("after MarkRead: unread = %d, listed %d", unread.UnreadCount, len(unread.Data))
+	}
+
+	if _, err := svc.MarkRead(ctx, "t1", "budi@acme.co.id", "missing"); err == nil {
+		t.Error("expected an error for an unknown notification")
+	}
+	if _, err := svc.List(ctx, "t1", "budi@acme.co.id", "not a cursor", 0, false); err == nil {
+		t.Error("expected an error for a malformed cursor")
+	}
+}
+
+func TestInboxPruneFollowsRetention(t *testing.T) {
+	svc, repo, now := newTestInbox(&tenant.NotificationSettings{RetentionDays: 30})
+	ctx := context.Background()
+	svc.Dispatch(ctx, testEvent)
+	*now = now.Add(20 * 24 * time.Hour)
+	svc.Dispatch(ctx, testEvent)
+
+	if err := svc.Prune(ctx, now.Add(15*24*time.Hour)); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if len(repo.notifications) != 1 || !repo.notifications[0].CreatedAt.Equal(*now) {
+		t.Errorf("kept %+v, want only the newer notification", repo.notifications)
+	}
+}
@@ -0,0 +1,111 @@
+package notification
+
+import "time"
+
+// Ticket event types.
+const (
+	EventStatusChanged    = "status_changed"
+	EventCommentAdded     = "comment_added"
+	EventSolutionProposed = "solution_proposed"
+)
+
+// Event is a change on a ticket that its requester should hear about.
+type Event struct {
+	Type        string
+	TicketID    int
+	TicketTitle string
+	// RequesterID is the InvGate user ID of the ticket's creator.
+	RequesterID int
+	StatusID    int
+	CommentID   int
+	// Comment is a plain-text excerpt for comment and solution events.
+	Comment    string
+	OccurredAt time.Time
+}
+
+// Unsubscribe stops ticket update emails to a user for one ticket, or for
+// all tickets when TicketID is 0.
+type Unsubscribe struct {
+	ID        string    `gorm:"type:char(36);primaryKey"`
+	UserID    string    `gorm:"type:char(36);not null;uniqueIndex:idx_notification_unsubscribes_user_ticket,priority:1"`
+	TicketID  int       `gorm:"not null;default:0;uniqueIndex:idx_notification_unsubscribes_user_ticket,priority:2"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (Unsubscribe) TableName() string {
+	return "notification_unsubscribes"
+}
+
+// DigestItem is a ticket event waiting for the user's next digest email.
+type DigestItem struct {
+	ID          string    `gorm:"type:char(36);primaryKey"`
+	TenantID    string    `gorm:"type:char(36);not null;index"`
+	UserID      string    `gorm:"type:char(36);not null;index"`
+	Type        string    `gorm:"size:32;not null"`
+	TicketID    int       `gorm:"not null"`
+	TicketTitle string    `gorm:"size:255"`
+	StatusID    int       `gorm:"not null;default:0"`
+	Comment     string    `gorm:"type:text"`
+	CreatedAt   time.Time `gorm:"autoCreateTime;index"`
+}
+
+// TableName specifies the table name for GORM
+func (DigestItem) TableName() string {
+	return "notification_digest_items"
+}
+
+// PreferencesResponse is the current user's email notification preferences.
+type PreferencesResponse struct {
+	EmailEnabled bool `json:"email_enabled"`
+	// UnsubscribedTickets lists tickets muted one by one.
+	UnsubscribedTickets []int `json:"unsubscribed_tickets"`
+}
+
+// UpdatePreferencesRequest turns all ticket update emails on or off.
+type UpdatePreferencesRequest struct {
+	EmailEnabled *bool `json:"email_enabled" binding:"required"`
+}
+
+// TicketSubscriptionRequest turns ticket update emails on or off for one ticket.
+type TicketSubscriptionRequest struct {
+	Subscribed *bool `json:"subscribed" binding:"required"`
+}
+
+// UnsubscribeRequest carries the token from an email unsubscribe link.
+type UnsubscribeRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// Notification is an entry in a user's in-portal notification center.
+type Notification struct {
+	ID        string              `gorm:"type:char(36);primaryKey" json:"id"`
+	TenantID  string              `gorm:"type:char(36);not null;index:idx_notifications_tenant_created,priority:1" json:"-"`
+	UserID    string              `gorm:"type:char(36);not null;index:idx_notifications_user_created,priority:1" json:"-"`
+	Type      string              `gorm:"size:32;not null" json:"type"`
+	TicketID  int                 `gorm:"not null" json:"ticket_id"`
+	Payload   NotificationPayload `gorm:"type:json;serializer:json" json:"payload"`
+	ReadAt    *time.Time          `json:"read_at"`
+	CreatedAt time.Time           `gorm:"index:idx_notifications_user_created,priority:2;index:idx_notifications_tenant_created,priority:2" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// NotificationPayload describes the ticket event behind a notification.
+type NotificationPayload struct {
+	TicketTitle string `json:"ticket_title,omitempty"`
+	StatusID    int    `json:"status_id,omitempty"`
+	CommentID   int    `json:"comment_id,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// NotificationPage is one page of the current user's notifications, newest
+// first. NextCursor is empty on the last page.
+type NotificationPage struct {
+	Data        []*Notification `json:"data"`
+	NextCursor  string          `json:"next_cursor,omitempty"`
+	UnreadCount int64           `json:"unread_count"`
+}
@@ -0,0 +1,83 @@
+package notification
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Repository stores email unsubscribes and pending digest items
+type Repository interface {
+	// Unsubscribe mutes a ticket for a user, or all tickets when ticketID
+	// is 0. Muting twice is not an error.
+	Unsubscribe(ctx context.Context, unsubscribe *Unsubscribe) error
+	Resubscribe(ctx context.Context, userID string, ticketID int) error
+	ListUnsubscribes(ctx context.Context, userID string) ([]*Unsubscribe, error)
+	// IsUnsubscribed reports whether the user muted the ticket or all tickets.
+	IsUnsubscribed(ctx context.Context, userID string, ticketID int) (bool, error)
+
+	AddDigestItem(ctx context.Context, item *DigestItem) error
+	// PendingDigests returns, for each user with waiting items, the user,
+	// tenant and creation time of the oldest item.
+	PendingDigests(ctx context.Context) ([]*DigestItem, error)
+	ListDigestItems(ctx context.Context, userID string) ([]*DigestItem, error)
+	DeleteDigestItems(ctx context.Context, ids []string) error
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new notification repository
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) Unsubscribe(ctx context.Context, unsubscribe *Unsubscribe) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(unsubscribe).Error
+}
+
+func (r *gormRepository) Resubscribe(ctx context.Context, userID string, ticketID int) error {
+	return r.db.WithContext(ctx).Delete(&Unsubscribe{}, "user_id = ? AND ticket_id = ?", userID, ticketID).Error
+}
+
+func (r *gormRepository) ListUnsubscribes(ctx context.Context, userID string) ([]*Unsubscribe, error) {
+	var unsubscribes []*Unsubscribe
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("ticket_id").Find(&unsubscribes).Error
+	return unsubscribes, err
+}
+
+func (r *gormRepository) IsUnsubscribed(ctx context.Context, userID string, ticketID int) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&Unsubscribe{}).
+		Where("user_id = ? AND ticket_id IN ?", userID, []int{0, ticketID}).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *gormRepository) AddDigestItem(ctx context.Context, item *DigestItem) error {
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+func (r *gormRepository) PendingDigests(ctx context.Context) ([]*DigestItem, error) {
+	var pending []*DigestItem
+	err := r.db.WithContext(ctx).Model(&DigestItem{}).
+		Select("user_id, tenant_id, MIN(created_at) AS created_at").
+		Group("user_id, tenant_id").
+		Scan(&pending).Error
+	return pending, err
+}
+
+func (r *gormRepository) ListDigestItems(ctx context.Context, userID string) ([]*DigestItem, error) {
+	var items []*DigestItem
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at").Find(&items).Error
+	return items, err
+}
+
+func (r *gormRepository) DeleteDigestItems(ctx context.Context, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Delete(&DigestItem{}, "id IN ?", ids).Error
+}
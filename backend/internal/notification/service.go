@@ -0,0 +1,300 @@
+package notification
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/tenant"
+	"werk-ticketing/internal/user"
+)
+
+// Email templates used for ticket updates; see the email package.
+const (
+	templateTicketUpdate = "ticket_update"
+	templateTicketDigest = "ticket_digest"
+)
+
+// Mailer sends templated emails on behalf of a tenant.
+type Mailer interface {
+	Send(ctx context.Context, t *tenant.Tenant, to, name, locale string, vars map[string]any) error
+}
+
+// Service notifies requesters about changes on their tickets and manages
+// their email preferences.
+type Service interface {
+	// Dispatch emails an event to the requester's accounts, or queues it
+	// for their next digest, following tenant settings and unsubscribes.
+	Dispatch(ctx context.Context, ev Event) error
+	// SendDigests sends the digests that are due.
+	SendDigests(ctx context.Context, now time.Time) error
+
+	Preferences(ctx context.Context, tenantID, email string) (*PreferencesResponse, error)
+	SetEmailEnabled(ctx context.Context, tenantID, email string, enabled bool) error
+	SetTicketSubscription(ctx context.Context, tenantID, email string, ticketID int, subscribed bool) error
+	// UnsubscribeByToken applies the unsubscribe link from an email.
+	UnsubscribeByToken(ctx context.Context, token string) error
+}
+
+type service struct {
+	repo        Repository
+	users       user.Repository
+	tenants     tenant.Repository
+	mailer      Mailer
+	secret      []byte
+	frontendURL string
+	logger      *logrus.Logger
+}
+
+// NewService creates a notification service. secret signs unsubscribe
+// links and frontendURL is the base of the links in emails.
+func NewService(repo Repository, users user.Repository, tenants tenant.Repository, mailer Mailer, secret, frontendURL string, logger *logrus.Logger) Service {
+	return &service{
+		repo:        repo,
+		users:       users,
+		tenants:     tenants,
+		mailer:      mailer,
+		secret:      []byte(secret),
+		frontendURL: strings.TrimRight(frontendURL, "/"),
+		logger:      logger,
+	}
+}
+
+func (s *service) Dispatch(ctx context.Context, ev Event) error {
+	accounts, err := s.users.ListByInvGateUserID(ctx, ev.RequesterID)
+	if err != nil {
+		return fmt.Errorf("failed to find requester: %w", err)
+	}
+
+	var errs []error
+	for _, u := range accounts {
+		if err := s.notify(ctx, u, ev); err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"tenantID": u.TenantID,
+				"userID":   u.ID,
+				"ticketID": ev.TicketID,
+				"event":    ev.Type,
+			}).Error("failed to notify requester")
+			errs = append(errs, err)
+		}
+	}
+	return stdErrors.Join(errs...)
+}
+
+func (s *service) notify(ctx context.Context, u *user.User, ev Event) error {
+	// Inactive tenants are not found, so their users get no emails.
+	t, err := s.tenants.FindByID(ctx, u.TenantID)
+	if err != nil || t == nil {
+		return err
+	}
+	settings := t.NotificationSettings()
+	if settings.Digest == tenant.DigestOff {
+		return nil
+	}
+	if muted, err := s.repo.IsUnsubscribed(ctx, u.ID, ev.TicketID); err != nil || muted {
+		return err
+	}
+
+	if settings.DigestInterval() > 0 {
+		return s.repo.AddDigestItem(ctx, &DigestItem{
+			ID:          uuid.New().String(),
+			TenantID:    t.ID,
+			UserID:      u.ID,
+			Type:        ev.Type,
+			TicketID:    ev.TicketID,
+			TicketTitle: ev.TicketTitle,
+			StatusID:    ev.StatusID,
+			Comment:     ev.Comment,
+		})
+	}
+
+	locale := t.PortalBranding().DefaultLanguage
+	vars := s.updateVars(locale, ev.Type, ev.TicketID, ev.TicketTitle, ev.StatusID, ev.Comment)
+	vars["UnsubscribeTicketLink"] = s.unsubscribeLink(u.ID, ev.TicketID)
+	vars["UnsubscribeAllLink"] = s.unsubscribeLink(u.ID, 0)
+	return s.mailer.Send(ctx, t, u.Email, templateTicketUpdate, locale, vars)
+}
+
+func (s *service) SendDigests(ctx context.Context, now time.Time) error {
+	pending, err := s.repo.PendingDigests(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list pending digests: %w", err)
+	}
+
+	for _, p := range pending {
+		if err := s.sendDigest(ctx, p, now); err != nil {
+			s.logger.WithError(err).WithFields(logrus.Fields{
+				"tenantID": p.TenantID,
+				"userID":   p.UserID,
+			}).Error("failed to send ticket digest")
+		}
+	}
+	return nil
+}
+
+func (s *service) sendDigest(ctx context.Context, pending *DigestItem, now time.Time) error {
+	t, err := s.tenants.FindByIDIncludingInactive(ctx, pending.TenantID)
+	if err != nil {
+		return err
+	}
+	u, err := s.users.GetByID(ctx, pending.TenantID, pending.UserID)
+	if err != nil {
+		return err
+	}
+
+	items, err := s.repo.ListDigestItems(ctx, pending.UserID)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+
+	// Items of deleted users or tenants, or of tenants that stopped
+	// batching, are dropped; the latter were collected under old settings.
+	var settings tenant.NotificationSettings
+	if t != nil && t.IsActive && u != nil {
+		settings = t.NotificationSettings()
+	}
+	interval := settings.DigestInterval()
+	if interval == 0 {
+		return s.repo.DeleteDigestItems(ctx, ids)
+	}
+	if now.Sub(pending.CreatedAt) < interval {
+		return nil
+	}
+
+	locale := t.PortalBranding().DefaultLanguage
+	updates := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		// Tickets muted after the item was collected are skipped.
+		if muted, err := s.repo.IsUnsubscribed(ctx, u.ID, item.TicketID); err != nil {
+			return err
+		} else if muted {
+			continue
+		}
+		updates = append(updates, s.updateVars(locale, item.Type, item.TicketID, item.TicketTitle, item.StatusID, item.Comment))
+	}
+
+	if len(updates) > 0 {
+		vars := map[string]any{
+			"Updates":            updates,
+			"UnsubscribeAllLink": s.unsubscribeLink(u.ID, 0),
+		}
+		if err := s.mailer.Send(ctx, t, u.Email, templateTicketDigest, locale, vars); err != nil {
+			return err
+		}
+	}
+	return s.repo.DeleteDigestItems(ctx, ids)
+}
+
+// updateVars are the template variables describing one ticket update.
+func (s *service) updateVars(locale, eventType string, ticketID int, title string, statusID int, comment string) map[string]any {
+	return map[string]any{
+		"Event":       eventType,
+		"TicketID":    ticketID,
+		"TicketTitle": title,
+		"TicketLink":  fmt.Sprintf("%s/tickets/%d", s.frontendURL, ticketID),
+		"Status":      statusName(locale, statusID),
+		"Comment":     comment,
+	}
+}
+
+func (s *service) unsubscribeLink(userID string, ticketID int) string {
+	return fmt.Sprintf("%s/unsubscribe?token=%s", s.frontendURL, signUnsubscribeToken(s.secret, userID, ticketID))
+}
+
+func (s *service) Preferences(ctx context.Context, tenantID, email string) (*PreferencesResponse, error) {
+	u, err := s.currentUser(ctx, tenantID, email)
+	if err != nil {
+		return nil, err
+	}
+
+	unsubscribes, err := s.repo.ListUnsubscribes(ctx, u.ID)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInternal, "failed to load notification preferences", err)
+	}
+	prefs := &PreferencesResponse{EmailEnabled: true, UnsubscribedTickets: []int{}}
+	for _, unsub := range unsubscribes {
+		if unsub.TicketID == 0 {
+			prefs.EmailEnabled = false
+		} else {
+			prefs.UnsubscribedTickets = append(prefs.UnsubscribedTickets, unsub.TicketID)
+		}
+	}
+	return prefs, nil
+}
+
+func (s *service) SetEmailEnabled(ctx context.Context, tenantID, email string, enabled bool) error {
+	return s.SetTicketSubscription(ctx, tenantID, email, 0, enabled)
+}
+
+func (s *service) SetTicketSubscription(ctx context.Context, tenantID, email string, ticketID int, subscribed bool) error {
+	u, err := s.currentUser(ctx, tenantID, email)
+	if err != nil {
+		return err
+	}
+	return s.setSubscription(ctx, u.ID, ticketID, subscribed)
+}
+
+func (s *service) UnsubscribeByToken(ctx context.Context, token string) error {
+	userID, ticketID, err := parseUnsubscribeToken(s.secret, token)
+	if err != nil {
+		return errors.NewAppError(errors.ErrCodeInvalidInput, "invalid unsubscribe link", err)
+	}
+	return s.setSubscription(ctx, userID, ticketID, false)
+}
+
+func (s *service) setSubscription(ctx context.Context, userID string, ticketID int, subscribed bool) error {
+	var err error
+	if subscribed {
+		err = s.repo.Resubscribe(ctx, userID, ticketID)
+	} else {
+		err = s.repo.Unsubscribe(ctx, &Unsubscribe{ID: uuid.New().String(), UserID: userID, TicketID: ticketID})
+	}
+	if err != nil {
+		return errors.NewAppError(errors.ErrCodeInternal, "failed to update notification preferences", err)
+	}
+	return nil
+}
+
+func (s *service) currentUser(ctx context.Context, tenantID, email string) (*user.User, error) {
+	u, err := s.users.GetByEmail(ctx, tenantID, email)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInternal, "failed to fetch user information", err)
+	}
+	if u == nil {
+		return nil, errors.NewAppError(errors.ErrCodeNotFound, "user not found", nil)
+	}
+	return u, nil
+}
+
+var statusNames = map[string]map[int]string{
+	tenant.LanguageEnglish: {
+		1: "New", 2: "Open", 3: "Pending", 4: "Waiting",
+		5: "Resolved", 6: "Closed", 7: "Rejected", 8: "Canceled",
+	},
+	tenant.LanguageIndonesian: {
+		1: "Baru", 2: "Terbuka", 3: "Tertunda", 4: "Menunggu",
+		5: "Terselesaikan", 6: "Ditutup", 7: "Ditolak", 8: "Dibatalkan",
+	},
+}
+
+// statusName returns the localized name of an InvGate status.
+func statusName(locale string, statusID int) string {
+	names, ok := statusNames[locale]
+	if !ok {
+		names = statusNames[tenant.LanguageIndonesian]
+	}
+	if name, ok := names[statusID]; ok {
+		return name
+	}
+	return fmt.Sprintf("#%d", statusID)
+}
@@ -0,0 +1,184 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/tenant"
+	"werk-ticketing/internal/user"
+)
+
+type memoryRepo struct {
+	Repository
+	unsubscribes map[string]bool // user + ":" + ticket
+	digest       []*DigestItem
+}
+
+func unsubscribeKey(userID string, ticketID int) string {
+	return fmt.Sprintf("%s:%d", userID, ticketID)
+}
+
+func (r *memoryRepo) Unsubscribe(ctx context.Context, u *Unsubscribe) error {
+	r.unsubscribes[unsubscribeKey(u.UserID, u.TicketID)] = true
+	return nil
+}
+
+func (r *memoryRepo) IsUnsubscribed(ctx context.Context, userID string, ticketID int) (bool, error) {
+	return r.unsubscribes[unsubscribeKey(userID, 0)] || r.unsubscribes[unsubscribeKey(userID, ticketID)], nil
+}
+
+func (r *memoryRepo) AddDigestItem(ctx context.Context, item *DigestItem) error {
+	item.CreatedAt = time.Now()
+	r.digest = append(r.digest, item)
+	return nil
+}
+
+func (r *memoryRepo) PendingDigests(ctx context.Context) ([]*DigestItem, error) {
+	if len(r.digest) == 0 {
+		return nil, nil
+	}
+	first := r.digest[0]
+	return []*DigestItem{{UserID: first.UserID, TenantID: first.TenantID, CreatedAt: first.CreatedAt}}, nil
+}
+
+func (r *memoryRepo) ListDigestItems(ctx context.Context, userID string) ([]*DigestItem, error) {
+	return r.digest, nil
+}
+
+func (r *memoryRepo) DeleteDigestItems(ctx context.Context, ids []string) error {
+	r.digest = nil
+	return nil
+}
+
+type memoryUsers struct {
+	user.Repository
+	users []*user.User
+}
+
+func (r *memoryUsers) ListByInvGateUserID(ctx context.Context, id int) ([]*user.User, error) {
+	var found []*user.User
+	for _, u := range r.users {
+		if u.InvGateUserID == id {
+			found = append(found, u)
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryUsers) GetByID(ctx context.Context, tenantID, id string) (*user.User, error) {
+	for _, u := range r.users {
+		if u.TenantID == tenantID && u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+type memoryTenants struct {
+	tenant.Repository
+	tenant *tenant.Tenant
+}
+
+func (r *memoryTenants) FindByID(ctx context.Context, id string) (*tenant.Tenant, error) {
+	return r.tenant, nil
+}
+
+func (r *memoryTenants) FindByIDIncludingInactive(ctx context.Context, id string) (*tenant.Tenant, error) {
+	return r.tenant, nil
+}
+
+type sentEmail struct {
+	to, name, locale string
+	vars             map[string]any
+}
+
+type recordingMailer struct{ sent []sentEmail }
+
+func (m *recordingMailer) Send(ctx context.Context, t *tenant.Tenant, to, name, locale string, vars map[string]any) error {
+	m.sent = append(m.sent, sentEmail{to, name, locale, vars})
+	return nil
+}
+
+func newTestService(digest string) (*service, *memoryRepo, *recordingMailer) {
+	repo := &memoryRepo{unsubscribes: map[string]bool{}}
+	mailer := &recordingMailer{}
+	users := &memoryUsers{users: []*user.User{{ID: "u1", TenantID: "t1", Email: "budi@acme.co.id", InvGateUserID: 7}}}
+	tenants := &memoryTenants{tenant: &tenant.Tenant{ID: "t1", IsActive: true, Notifications: &tenant.NotificationSettings{Digest: digest}}}
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	svc := NewService(repo, users, tenants, mailer, "secret", "https://portal.example.com/", logger).(*service)
+	return svc, repo, mailer
+}
+
+var testEvent = Event{Type: EventStatusChanged, TicketID: 5, TicketTitle: "VPN", RequesterID: 7, StatusID: 5}
+
+func TestDispatchSendsImmediately(t *testing.T) {
+	svc, _, mailer := newTestService(tenant.DigestImmediate)
+	if err := svc.Dispatch(context.Background(), testEvent); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if len(mailer.sent) != 1 {
+		t.Fatalf("sent %d emails", len(mailer.sent))
+	}
+	sent := mailer.sent[0]
+	if sent.to != "budi@acme.co.id" || sent.name != templateTicketUpdate || sent.locale != tenant.LanguageIndonesian {
+		t.Errorf("sent = %+v", sent)
+	}
+	if sent.vars["TicketLink"] != "https://portal.example.com/tickets/5" || sent.vars["Status"] != "Terselesaikan" {
+		t.Errorf("vars = %+v", sent.vars)
+	}
+
+	// The ticket's unsubscribe link mutes only that ticket.
+	link := sent.vars["UnsubscribeTicketLink"].(string)
+	token := link[strings.Index(link, "token=")+len("token="):]
+	if err := svc.UnsubscribeByToken(context.Background(), token); err != nil {
+		t.Fatalf("UnsubscribeByToken: %v", err)
+	}
+	svc.Dispatch(context.Background(), testEvent)
+	other := testEvent
+	other.TicketID = 6
+	svc.Dispatch(context.Background(), other)
+	if len(mailer.sent) != 2 || mailer.sent[1].vars["TicketID"] != 6 {
+		t.Errorf("after unsubscribing ticket 5, sent = %+v", mailer.sent)
+	}
+
+	if err := svc.UnsubscribeByToken(context.Background(), token+"x"); err == nil {
+		t.Error("tampered token accepted")
+	}
+}
+
+func TestDispatchCollectsDigest(t *testing.T) {
+	svc, repo, mailer := newTestService(tenant.DigestHourly)
+	svc.Dispatch(context.Background(), testEvent)
+	svc.Dispatch(context.Background(), Event{Type: EventCommentAdded, TicketID: 6, RequesterID: 7, Comment: "Done"})
+	if len(mailer.sent) != 0 || len(repo.digest) != 2 {
+		t.Fatalf("sent %d emails, %d digest items", len(mailer.sent), len(repo.digest))
+	}
+
+	// Nothing is sent until the oldest item is an interval old.
+	svc.SendDigests(context.Background(), time.Now())
+	if len(mailer.sent) != 0 {
+		t.Fatal("digest sent early")
+	}
+	svc.SendDigests(context.Background(), time.Now().Add(time.Hour))
+	if len(mailer.sent) != 1 || mailer.sent[0].name != templateTicketDigest || len(repo.digest) != 0 {
+		t.Fatalf("sent = %+v, pending = %d", mailer.sent, len(repo.digest))
+	}
+	if updates := mailer.sent[0].vars["Updates"].([]map[string]any); len(updates) != 2 || updates[1]["Comment"] != "Done" {
+		t.Errorf("updates = %+v", updates)
+	}
+}
+
+func TestDispatchRespectsDigestOff(t *testing.T) {
+	svc, repo, mailer := newTestService(tenant.DigestOff)
+	svc.Dispatch(context.Background(), testEvent)
+	if len(mailer.sent) != 0 || len(repo.digest) != 0 {
+		t.Errorf("tenant with notifications off got %d emails, %d digest items", len(mailer.sent), len(repo.digest))
+	}
+}
@@ -0,0 +1,152 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/invgate"
+)
+
+// TicketState is the last seen state of an InvGate ticket, kept so changes
+// can be detected between syncs.
+type TicketState struct {
+	TicketID  int `gorm:"primaryKey;autoIncrement:false"`
+	CreatorID int `gorm:"not null;index"`
+	StatusID  int `gorm:"not null"`
+	// LastUpdate is InvGate's last_update in unix seconds.
+	LastUpdate int64 `gorm:"not null"`
+	// LastCommentID is the newest comment already seen; 0 until the
+	// comments are first fetched.
+	LastCommentID int       `gorm:"not null;default:0"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for GORM
+func (TicketState) TableName() string {
+	return "ticket_states"
+}
+
+// StateRepository stores the last seen state of each ticket
+type StateRepository interface {
+	// Find returns nil (and no error) for tickets not seen before.
+	Find(ctx context.Context, ticketID int) (*TicketState, error)
+	Save(ctx context.Context, state *TicketState) error
+}
+
+type gormStateRepository struct {
+	db *gorm.DB
+}
+
+// NewStateRepository creates a new ticket state repository
+func NewStateRepository(db *gorm.DB) StateRepository {
+	return &gormStateRepository{db: db}
+}
+
+func (r *gormStateRepository) Find(ctx context.Context, ticketID int) (*TicketState, error) {
+	var state TicketState
+	err := r.db.WithContext(ctx).Where("ticket_id = ?", ticketID).First(&state).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (r *gormStateRepository) Save(ctx context.Context, state *TicketState) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(state).Error
+}
+
+// Changed reports whether incident was updated since state was recorded.
+func (s *TicketState) Changed(incident invgate.Incident) bool {
+	return s == nil || incident.LastUpdate > s.LastUpdate || incident.StatusID != s.StatusID
+}
+
+// Detect compares a ticket with its previous state and returns the new
+// state and the events for its requester. A ticket seen for the first time
+// only records a baseline. Comments by the requester are not reported, and
+// a proposed solution replaces the status change that comes with it.
+func Detect(prev *TicketState, incident invgate.Incident, comments []invgate.Comment) (TicketState, []Event) {
+	next := TicketState{
+		TicketID:   incident.ID,
+		CreatorID:  incident.CreatorID,
+		StatusID:   incident.StatusID,
+		LastUpdate: incident.LastUpdate,
+	}
+	if prev != nil {
+		next.LastCommentID = prev.LastCommentID
+	}
+	for _, c := range comments {
+		if c.ID > next.LastCommentID {
+			next.LastCommentID = c.ID
+		}
+	}
+	if prev == nil {
+		return next, nil
+	}
+
+	base := Event{
+		TicketID:    incident.ID,
+		TicketTitle: incident.Title,
+		RequesterID: incident.CreatorID,
+		StatusID:    incident.StatusID,
+		OccurredAt:  time.Unix(incident.LastUpdate, 0),
+	}
+
+	sorted := append([]invgate.Comment(nil), comments...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	var events []Event
+	solution := false
+	for _, c := range sorted {
+		// Agents' internal notes must never reach the requester.
+		if c.ID <= prev.LastCommentID || c.AuthorID == incident.CreatorID || c.Internal() {
+			continue
+		}
+		// Until the comments have been fetched once, only their timestamps
+		// tell which ones are new.
+		if prev.LastCommentID == 0 && c.CreatedAt <= prev.LastUpdate {
+			continue
+		}
+		ev := base
+		ev.Type, ev.CommentID, ev.Comment = EventCommentAdded, c.ID, excerpt(c.Comment)
+		if c.IsSolution {
+			ev.Type, solution = EventSolutionProposed, true
+		}
+		if c.CreatedAt > 0 {
+			ev.OccurredAt = time.Unix(c.CreatedAt, 0)
+		}
+		events = append(events, ev)
+	}
+
+	if incident.StatusID != prev.StatusID && !solution {
+		ev := base
+		ev.Type = EventStatusChanged
+		events = append([]Event{ev}, events...)
+	}
+	return next, events
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// excerpt turns an InvGate comment, which may be HTML, into short plain text.
+func excerpt(comment string) string {
+	text := html.UnescapeString(htmlTag.ReplaceAllString(comment, " "))
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= constants.NotificationCommentExcerpt {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:constants.NotificationCommentExcerpt])) + "…"
+}
@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+
+	"werk-ticketing/internal/invgate"
+)
+
+func TestDetectBaselineHasNoEvents(t *testing.T) {
+	incident := invgate.Incident{ID: 10, CreatorID: 7, StatusID: 2, LastUpdate: 1000}
+	next, events := Detect(nil, incident, nil)
+	if len(events) != 0 {
+		t.Errorf("events for a new ticket = %+v", events)
+	}
+	if next.TicketID != 10 || next.CreatorID != 7 || next.StatusID != 2 || next.LastUpdate != 1000 {
+		t.Errorf("baseline = %+v", next)
+	}
+}
+
+func TestDetectChanges(t *testing.T) {
+	prev := &TicketState{TicketID: 10, CreatorID: 7, StatusID: 2, LastUpdate: 1000}
+	incident := invgate.Incident{ID: 10, Title: "VPN", CreatorID: 7, StatusID: 4, LastUpdate: 2000}
+	comments := []invgate.Comment{
+		{ID: 1, AuthorID: 9, Comment: "old", CreatedAt: 900},
+		{ID: 3, AuthorID: 7, Comment: "requester reply", CreatedAt: 1600},
+		{ID: 2, AuthorID: 9, Comment: "<p>Please  restart&nbsp;the <b>client</b></p>", CreatedAt: 1500},
+	}
+
+	next, events := Detect(prev, incident, comments)
+	if next.LastCommentID != 3 || next.StatusID != 4 {
+		t.Errorf("next = %+v", next)
+	}
+	if len(events) != 2 || events[0].Type != EventStatusChanged || events[1].Type != EventCommentAdded {
+		t.Fatalf("events = %+v", events)
+	}
+	if ev := events[1]; ev.CommentID != 2 || ev.Comment != "Please restart the client" || ev.RequesterID != 7 || ev.TicketTitle != "VPN" {
+		t.Errorf("comment event = %+v", ev)
+	}
+
+	// Once comment IDs are known they decide what is new.
+	incident.LastUpdate = 3000
+	_, events = Detect(&next, incident, append(comments, invgate.Comment{ID: 4, AuthorID: 9, Comment: "Fixed", IsSolution: true, CreatedAt: 2000}))
+	if len(events) != 1 || events[0].Type != EventSolutionProposed || events[0].CommentID != 4 {
+		t.Errorf("events after solution = %+v", events)
+	}
+}
+
+func TestDetectSkipsInternalComments(t *testing.T) {
+	hidden, visible := false, true
+	prev := &TicketState{TicketID: 10, CreatorID: 7, StatusID: 2, LastUpdate: 1000, LastCommentID: 1}
+	incident := invgate.Incident{ID: 10, CreatorID: 7, StatusID: 2, LastUpdate: 2000}
+	next, events := Detect(prev, incident, []invgate.Comment{
+		{ID: 2, AuthorID: 9, Comment: "internal note", CustomerVisible: &hidden},
+		{ID: 3, AuthorID: 9, Comment: "reply", CustomerVisible: &visible},
+	})
+	if len(events) != 1 || events[0].CommentID != 3 {
+		t.Errorf("events = %+v, want only the visible comment", events)
+	}
+	if next.LastCommentID != 3 {
+		t.Errorf("LastCommentID = %d, want 3", next.LastCommentID)
+	}
+}
+
+func TestDetectSolutionReplacesStatusChange(t *testing.T) {
+	prev := &TicketState{TicketID: 10, CreatorID: 7, StatusID: 2, LastUpdate: 1000, LastCommentID: 1}
+	incident := invgate.Incident{ID: 10, CreatorID: 7, StatusID: 5, LastUpdate: 2000}
+	_, events := Detect(prev, incident, []invgate.Comment{{ID: 2, AuthorID: 9, IsSolution: true, Comment: strings.Repeat("a", 600)}})
+	if len(events) != 1 || events[0].Type != EventSolutionProposed {
+		t.Fatalf("events = %+v", events)
+	}
+	if got := []rune(events[0].Comment); len(got) != 501 || got[500] != '…' {
+		t.Errorf("excerpt has %d runes", len(got))
+	}
+}
@@ -0,0 +1,160 @@
+package notification
+
+import (
+	"context"
+	stdErrors "errors"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/user"
+)
+
+// ErrTicketNotInTenant is returned by SyncTicket when the ticket's requester
+// has no account in the tenant.
+var ErrTicketNotInTenant = stdErrors.New("ticket does not belong to the tenant")
+
+// Listener receives the ticket events found by the syncer.
+type Listener interface {
+	Dispatch(ctx context.Context, ev Event) error
+}
+
+// Syncer polls InvGate for ticket changes and dispatches an event for
+// each status change, agent comment and proposed solution.
+type Syncer struct {
+	client    invgate.Service
+	states    StateRepository
+	users     user.Repository
+	listeners []Listener
+	logger    *logrus.Logger
+	// mu keeps polling and webhook-triggered syncs from reporting the same
+	// change twice.
+	mu sync.Mutex
+}
+
+// NewSyncer creates a ticket syncer dispatching to the listeners in order.
+func NewSyncer(client invgate.Service, states StateRepository, users user.Repository, logger *logrus.Logger, listeners ...Listener) *Syncer {
+	return &Syncer{client: client, states: states, users: users, listeners: listeners, logger: logger}
+}
+
+// Run syncs periodically until ctx is cancelled.
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(constants.TicketSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.SyncOnce(ctx); err != nil && ctx.Err() == nil {
+			s.logger.WithError(err).Error("ticket sync failed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncOnce walks the portal's ticket view once.
+func (s *Syncer) SyncOnce(ctx context.Context) error {
+	pageKey := ""
+	for page := 0; page < constants.TicketSyncMaxPages; page++ {
+		resp, err := s.client.GetTicketsByView(ctx, constants.InvGateTicketViewID, pageKey, 0)
+		if err != nil {
+			return err
+		}
+		for _, incident := range resp.Incidents {
+			if err := s.syncTicket(ctx, incident); err != nil {
+				// The state is not saved, so the ticket is retried next sync.
+				s.logger.WithError(err).WithField("ticketID", incident.ID).Warn("failed to sync ticket")
+			}
+		}
+		if resp.NextPageKey == "" || resp.NextPageKey == pageKey {
+			return nil
+		}
+		pageKey = resp.NextPageKey
+	}
+	s.logger.WithField("pages", constants.TicketSyncMaxPages).Warn("ticket sync stopped at page limit")
+	return nil
+}
+
+func (s *Syncer) syncTicket(ctx context.Context, incident invgate.Incident) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, err := s.states.Find(ctx, incident.ID)
+	if err != nil || !prev.Changed(incident) {
+		return err
+	}
+	// New tickets only record a baseline, so their comments are not needed.
+	return s.apply(ctx, prev, incident, prev != nil)
+}
+
+// SyncTicket refreshes one ticket of a tenant from InvGate, e.g. when a
+// webhook reports a change. It fails with ErrTicketNotInTenant when the
+// ticket's requester is not one of the tenant's users. A ticket not seen
+// before only records a baseline, like a new ticket found by polling.
+func (s *Syncer) SyncTicket(ctx context.Context, tenantID string, ticketID int) error {
+	incident, err := s.client.GetTicketDetail(ctx, strconv.Itoa(ticketID))
+	if err != nil {
+		return err
+	}
+
+	accounts, err := s.users.ListByInvGateUserID(ctx, incident.CreatorID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(accounts, func(u *user.User) bool { return u.TenantID == tenantID }) {
+		return ErrTicketNotInTenant
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	prev, err := s.states.Find(ctx, incident.ID)
+	if err != nil {
+		return err
+	}
+	return s.apply(ctx, prev, *incident, prev != nil)
+}
+
+func (s *Syncer) apply(ctx context.Context, prev *TicketState, incident invgate.Incident, withComments bool) error {
+	var comments []invgate.Comment
+	if withComments {
+		var err error
+		if comments, err = s.client.GetTicketComments(ctx, incident.ID); err != nil {
+			return err
+		}
+	}
+
+	next, events := Detect(prev, incident, comments)
+	for _, ev := range events {
+		for _, l := range s.listeners {
+			// Failures are logged by the listener; a ticket is not notified twice.
+			_ = l.Dispatch(ctx, ev)
+		}
+	}
+	return s.states.Save(ctx, &next)
+}
+
+// RunDigests sends due digests periodically until ctx is cancelled.
+func RunDigests(ctx context.Context, service Service, logger *logrus.Logger) {
+	ticker := time.NewTicker(constants.DigestCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := service.SendDigests(ctx, now); err != nil {
+				logger.WithError(err).Error("failed to send ticket digests")
+			}
+		}
+	}
+}
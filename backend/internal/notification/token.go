@@ -0,0 +1,44 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	stdErrors "errors"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidToken is returned for unsubscribe tokens that fail verification.
+var ErrInvalidToken = stdErrors.New("invalid unsubscribe token")
+
+// Unsubscribe links must work without logging in, so they carry the user
+// and ticket signed with the server secret. They do not expire.
+
+func signUnsubscribeToken(secret []byte, userID string, ticketID int) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(userID + ":" + strconv.Itoa(ticketID)))
+	return payload + "." + unsubscribeSignature(secret, payload)
+}
+
+func parseUnsubscribeToken(secret []byte, token string) (string, int, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(unsubscribeSignature(secret, payload))) {
+		return "", 0, ErrInvalidToken
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", 0, ErrInvalidToken
+	}
+	userID, rawTicketID, ok := strings.Cut(string(decoded), ":")
+	ticketID, err := strconv.Atoi(rawTicketID)
+	if !ok || userID == "" || err != nil || ticketID < 0 {
+		return "", 0, ErrInvalidToken
+	}
+	return userID, ticketID, nil
+}
+
+func unsubscribeSignature(secret []byte, payload string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("unsubscribe:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,153 @@
+package realtime
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"werk-ticketing/internal/notification"
+)
+
+// Event is a ticket change pushed to the ticket's requester.
+type Event struct {
+	// ID is "<broker epoch>-<sequence>", so IDs from before a restart are
+	// recognised as unknown rather than compared with new ones.
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	TicketID   int       `json:"ticket_id"`
+	StatusID   int       `json:"status_id,omitempty"`
+	CommentID  int       `json:"comment_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+
+	seq         uint64
+	requesterID int
+}
+
+// Subscription receives the events for one requester's tickets.
+type Subscription struct {
+	ch          chan Event
+	requesterID int
+}
+
+// Events is closed when the subscription ends, including when the client
+// fell too far behind.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Broker fans ticket events out to connected clients and keeps recent
+// events so reconnecting clients can resume. It is in-memory, so clients
+// of one server instance only see events that instance syncs.
+type Broker struct {
+	epoch       string
+	historySize int
+	buffer      int
+
+	mu      sync.Mutex
+	seq     uint64
+	history []Event // oldest first
+	subs    map[*Subscription]struct{}
+}
+
+// NewBroker creates a broker keeping historySize events for resume and
+// buffering up to buffer events per client.
+func NewBroker(historySize, buffer int) *Broker {
+	return &Broker{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		historySize: historySize,
+		buffer:      buffer,
+		subs:        map[*Subscription]struct{}{},
+	}
+}
+
+// Dispatch publishes a ticket event found by the syncer.
+func (b *Broker) Dispatch(ctx context.Context, ev notification.Event) error {
+	b.Publish(Event{
+		Type:       ev.Type,
+		TicketID:   ev.TicketID,
+		StatusID:   ev.StatusID,
+		CommentID:  ev.CommentID,
+		OccurredAt: ev.OccurredAt,
+	}, ev.RequesterID)
+	return nil
+}
+
+// Publish assigns ev an ID and sends it to the requester's subscriptions.
+// A subscription whose buffer is full is closed; its client resumes from
+// the history when it reconnects.
+func (b *Broker) Publish(ev Event, requesterID int) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	ev.seq, ev.requesterID = b.seq, requesterID
+	ev.ID = b.epoch + "-" + strconv.FormatUint(b.seq, 10)
+
+	b.history = append(b.history, ev)
+	if len(b.history) > b.historySize {
+		n := copy(b.history, b.history[len(b.history)-b.historySize:])
+		b.history = b.history[:n]
+	}
+
+	for s := range b.subs {
+		if s.requesterID != requesterID {
+			continue
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			delete(b.subs, s)
+			close(s.ch)
+		}
+	}
+	return ev
+}
+
+// Subscribe registers a client for events on the requester's tickets and
+// returns the requester's events after lastEventID for replay. resumed is
+// false when lastEventID is set but cannot be resumed from, because it is
+// from before a restart or older than the history; the client should then
+// reload its tickets.
+func (b *Broker) Subscribe(requesterID int, lastEventID string) (sub *Subscription, replay []Event, resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = &Subscription{ch: make(chan Event, b.buffer), requesterID: requesterID}
+	b.subs[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+	last, ok := b.parseID(lastEventID)
+	if !ok || last > b.seq || (len(b.history) > 0 && b.history[0].seq > last+1) {
+		return sub, nil, false
+	}
+	for _, ev := range b.history {
+		if ev.seq > last && ev.requesterID == requesterID {
+			replay = append(replay, ev)
+		}
+	}
+	return sub, replay, true
+}
+
+// Unsubscribe ends a subscription.
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// parseID returns the sequence of an event ID issued by this broker.
+func (b *Broker) parseID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != b.epoch {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(seq, 10, 64)
+	return n, err == nil
+}
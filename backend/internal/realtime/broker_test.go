@@ -0,0 +1,138 @@
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/notification"
+	"werk-ticketing/internal/user"
+)
+
+func TestBrokerScopesEventsToRequester(t *testing.T) {
+	b := NewBroker(10, 4)
+	alice, _, _ := b.Subscribe(1, "")
+	bob, _, _ := b.Subscribe(2, "")
+
+	b.Dispatch(context.Background(), notification.Event{Type: notification.EventCommentAdded, TicketID: 7, RequesterID: 1})
+
+	select {
+	case ev := <-alice.Events():
+		if ev.TicketID != 7 || ev.Type != notification.EventCommentAdded || ev.ID == "" {
+			t.Errorf("event = %+v", ev)
+		}
+	default:
+		t.Fatal("alice got no event")
+	}
+	select {
+	case ev := <-bob.Events():
+		t.Errorf("bob got %+v", ev)
+	default:
+	}
+}
+
+func TestBrokerResume(t *testing.T) {
+	b := NewBroker(3, 4)
+	first := b.Publish(Event{TicketID: 1}, 1)
+	b.Publish(Event{TicketID: 2}, 2)
+	b.Publish(Event{TicketID: 3}, 1)
+
+	_, replay, resumed := b.Subscribe(1, first.ID)
+	if !resumed || len(replay) != 1 || replay[0].TicketID != 3 {
+		t.Fatalf("resume = %v %+v", resumed, replay)
+	}
+
+	// Pushing first out of the history loses the events right after it.
+	b.Publish(Event{TicketID: 4}, 1)
+	b.Publish(Event{TicketID: 5}, 1)
+	if _, _, resumed := b.Subscribe(1, first.ID); resumed {
+		t.Error("resumed from an event older than the history")
+	}
+	if _, _, resumed := b.Subscribe(1, "other-1"); resumed {
+		t.Error("resumed from another broker's event")
+	}
+}
+
+func TestBrokerDropsSlowSubscribers(t *testing.T) {
+	b := NewBroker(10, 1)
+	sub, _, _ := b.Subscribe(1, "")
+	b.Publish(Event{TicketID: 1}, 1)
+	b.Publish(Event{TicketID: 2}, 1)
+
+	if ev := <-sub.Events(); ev.TicketID != 1 {
+		t.Fatalf("first event = %+v", ev)
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Fatal("subscription still open after overflowing")
+	}
+	b.Unsubscribe(sub) // must not close twice
+}
+
+type memoryUsers struct {
+	user.Repository
+	user *user.User
+}
+
+func (r *memoryUsers) GetByEmail(ctx context.Context, tenantID, email string) (*user.User, error) {
+	return r.user, nil
+}
+
+func TestStreamReplaysAndPushes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	b := NewBroker(10, 4)
+	seen := b.Publish(Event{Type: notification.EventStatusChanged, TicketID: 1}, 5)
+	b.Publish(Event{Type: notification.EventCommentAdded, TicketID: 2}, 5)
+
+	router := gin.New()
+	router.GET("/tickets/stream", NewHandler(b, &memoryUsers{user: &user.User{InvGateUserID: 5}}, logger).Stream)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/tickets/stream", nil)
+	req.Header.Set("Last-Event-ID", seen.ID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	next := func(prefix string) string {
+		t.Helper()
+		for lines.Scan() {
+			if strings.HasPrefix(lines.Text(), prefix) {
+				return lines.Text()
+			}
+		}
+		t.Fatalf("stream ended before %q: %v", prefix, lines.Err())
+		return ""
+	}
+
+	if got := next("event:"); got != "event: "+notification.EventCommentAdded {
+		t.Errorf("replayed %q", got)
+	}
+	if got := next("data:"); !strings.Contains(got, `"ticket_id":2`) {
+		t.Errorf("replayed data %q", got)
+	}
+
+	b.Publish(Event{Type: notification.EventSolutionProposed, TicketID: 3}, 5)
+	if got := next("event:"); got != "event: "+notification.EventSolutionProposed {
+		t.Errorf("pushed %q", got)
+	}
+}
@@ -0,0 +1,103 @@
+package realtime
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/response"
+	"werk-ticketing/internal/user"
+)
+
+// Handler streams ticket updates to the portal over Server-Sent Events
+type Handler struct {
+	broker *Broker
+	users  user.Repository
+	logger *logrus.Logger
+}
+
+// NewHandler creates a new ticket stream handler
+func NewHandler(broker *Broker, users user.Repository, logger *logrus.Logger) *Handler {
+	return &Handler{broker: broker, users: users, logger: logger}
+}
+
+// Stream handles GET /tickets/stream. Each event is named after its type
+// (status_changed, comment_added, solution_proposed) and carries the event
+// as JSON. A "reset" event tells the client that events were missed and
+// its tickets should be reloaded.
+func (h *Handler) Stream(c *gin.Context) {
+	ctx := c.Request.Context()
+	u, err := h.users.GetByEmail(ctx, middleware.GetTenantID(c), middleware.GetUserEmail(c))
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to retrieve user information")
+		return
+	}
+	if u == nil {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "user not found")
+		return
+	}
+	if u.InvGateUserID == 0 {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "user is not synchronized with InvGate. Please contact administrator.")
+		return
+	}
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.Query("last_event_id")
+	}
+	sub, replay, resumed := h.broker.Subscribe(u.InvGateUserID, lastEventID)
+	defer h.broker.Unsubscribe(sub)
+
+	// The stream outlives the server's write timeout.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.WithError(err).Warn("failed to clear write deadline for ticket stream")
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no") // stop nginx from buffering the stream
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	fmt.Fprintf(w, "retry: %d\n\n", constants.StreamRetryMillis)
+	if !resumed {
+		fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+	}
+	for _, ev := range replay {
+		writeEvent(w, ev)
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(constants.StreamHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case ev, ok := <-sub.Events():
+			if !ok {
+				// Dropped for falling behind; the client resumes on reconnect.
+				return
+			}
+			writeEvent(w, ev)
+		}
+		w.Flush()
+	}
+}
+
+func writeEvent(w io.Writer, ev Event) {
+	data, _ := json.Marshal(ev)
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data)
+}
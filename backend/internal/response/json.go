@@ -30,8 +30,17 @@ func Error(c *gin.Context, status int, message string) {
 	})
 }
 
+// errorCodeKey records the error code sent, for the access log.
+const errorCodeKey = "errorCode"
+
+// GetErrorCode returns the error code sent in the response, if any.
+func GetErrorCode(c *gin.Context) string {
+	return c.GetString(errorCodeKey)
+}
+
 // ErrorWithCode writes error payload with error code
 func ErrorWithCode(c *gin.Context, status int, code, message string) {
+	c.Set(errorCodeKey, code)
 	c.AbortWithStatusJSON(status, gin.H{
 		"success": false,
 		"error":   message,
@@ -39,13 +48,24 @@ func ErrorWithCode(c *gin.Context, status int, code, message string) {
 	})
 }
 
+// ErrorWithDetails writes error payload with error code and structured details
+func ErrorWithDetails(c *gin.Context, status int, code, message string, details interface{}) {
+	c.Set(errorCodeKey, code)
+	c.AbortWithStatusJSON(status, gin.H{
+		"success": false,
+		"error":   message,
+		"code":    code,
+		"details": details,
+	})
+}
+
 // AppError writes application error response
 func AppError(c *gin.Context, appErr *errors.AppError) {
 	status := http.StatusInternalServerError
 	code := appErr.Code
 
 	switch code {
-	case errors.ErrCodeInvalidInput:
+	case errors.ErrCodeInvalidInput, errors.ErrCodeAttachmentRejected:
 		status = http.StatusBadRequest
 	case errors.ErrCodeNotFound:
 		status = http.StatusNotFound
@@ -59,6 +79,10 @@ func AppError(c *gin.Context, appErr *errors.AppError) {
 	case errors.ErrCodeEmailAlreadyExist:
 		// Email already exists should return 409 Conflict (not 500)
 		status = http.StatusConflict
+	case errors.ErrCodeConflict:
+		status = http.StatusConflict
+	case errors.ErrCodeTooManyRequests:
+		status = http.StatusTooManyRequests
 	case errors.ErrCodeExternalService:
 		status = http.StatusBadGateway
 	default:
@@ -0,0 +1,24 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/user"
+)
+
+// setupAdminAuditRoutes configures audit log routes (requires tenant context, auth and the admin role)
+func (r *Router) setupAdminAuditRoutes(api *gin.RouterGroup) {
+	adminRoutes := api.Group("/admin")
+	adminRoutes.Use(middleware.WithAuth(r.authService), middleware.RequireRole(r.userRepo, user.RoleAdmin, r.logger))
+	{
+		// GET /admin/audit-events - The caller's tenant's audit events, newest first
+		// Query: actor, action, target_type, target_id, request_id,
+		// from, to (RFC 3339), cursor (next_cursor of the previous page), limit
+		adminRoutes.GET("/audit-events", r.auditHandler.List)
+
+		// GET /admin/audit-events/export - Download matching audit events
+		// Query: the filters above and format=csv|jsonl (default csv)
+		adminRoutes.GET("/audit-events/export", r.auditHandler.Export)
+	}
+}
@@ -0,0 +1,36 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/user"
+)
+
+// setupAdminEmailRoutes configures email template and outbox management routes (requires tenant context, auth and the admin role)
+func (r *Router) setupAdminEmailRoutes(api *gin.RouterGroup) {
+	adminRoutes := api.Group("/admin")
+	adminRoutes.Use(middleware.WithAuth(r.authService), middleware.RequireRole(r.userRepo, user.RoleAdmin, r.logger))
+	{
+		// GET /admin/email-templates - List built-in templates, locales and sample variables
+		adminRoutes.GET("/email-templates", r.emailHandler.ListTemplates)
+
+		// POST /admin/email-templates/:name/preview - Render a template (or draft) with sample data
+		adminRoutes.POST("/email-templates/:name/preview", r.emailHandler.Preview)
+
+		// GET /admin/tenants/:id/email-templates - List a tenant's template overrides
+		adminRoutes.GET("/tenants/:id/email-templates", r.emailHandler.ListOverrides)
+
+		// PUT /admin/tenants/:id/email-templates/:name/:locale - Save a tenant override
+		adminRoutes.PUT("/tenants/:id/email-templates/:name/:locale", r.emailHandler.SaveOverride)
+
+		// DELETE /admin/tenants/:id/email-templates/:name/:locale - Revert to the built-in template
+		adminRoutes.DELETE("/tenants/:id/email-templates/:name/:locale", r.emailHandler.DeleteOverride)
+
+		// GET /admin/email-outbox - List queued emails by status (dead-lettered by default)
+		adminRoutes.GET("/email-outbox", r.emailHandler.ListOutbox)
+
+		// POST /admin/email-outbox/:id/retry - Requeue a dead-lettered email
+		adminRoutes.POST("/email-outbox/:id/retry", r.emailHandler.RetryOutbox)
+	}
+}
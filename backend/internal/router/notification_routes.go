@@ -0,0 +1,42 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/middleware"
+)
+
+// setupNotificationRoutes configures ticket update email preference and notification center routes (requires tenant context and auth)
+func (r *Router) setupNotificationRoutes(api *gin.RouterGroup) {
+	notificationRoutes := api.Group("")
+	notificationRoutes.Use(middleware.WithAuth(r.authService))
+	{
+		// GET /notification-preferences - Current user's email preferences
+		notificationRoutes.GET("/notification-preferences", r.notificationHandler.GetPreferences)
+
+		// PUT /notification-preferences - Turn all ticket update emails on or off
+		// Body JSON: { "email_enabled": bool }
+		notificationRoutes.PUT("/notification-preferences", r.notificationHandler.UpdatePreferences)
+
+		// PUT /tickets/:id/subscription - Turn update emails for one ticket on or off
+		// Body JSON: { "subscribed": bool }
+		notificationRoutes.PUT("/tickets/:id/subscription", r.notificationHandler.UpdateTicketSubscription)
+
+		// GET /notifications - Current user's notification center, newest first
+		// Query: cursor (next_cursor of the previous page), limit, unread=true
+		notificationRoutes.GET("/notifications", r.notificationHandler.ListNotifications)
+
+		// POST /notifications/read-all - Mark every notification read
+		notificationRoutes.POST("/notifications/read-all", r.notificationHandler.MarkAllNotificationsRead)
+
+		// POST /notifications/:id/read - Mark one notification read
+		notificationRoutes.POST("/notifications/:id/read", r.notificationHandler.MarkNotificationRead)
+	}
+}
+
+// setupPublicNotificationRoutes configures routes used from email links (no tenant or auth)
+func (r *Router) setupPublicNotificationRoutes(api *gin.RouterGroup) {
+	// POST /email/unsubscribe - Apply a signed unsubscribe link
+	// Body JSON: { "token": string }
+	api.POST("/email/unsubscribe", r.notificationHandler.Unsubscribe)
+}
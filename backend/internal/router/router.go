@@ -4,24 +4,44 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/auth"
 	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/email"
+	"werk-ticketing/internal/health"
+	"werk-ticketing/internal/invgatehook"
+	"werk-ticketing/internal/metrics"
 	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/notification"
+	"werk-ticketing/internal/realtime"
 	"werk-ticketing/internal/tenant"
 	"werk-ticketing/internal/ticket"
 	"werk-ticketing/internal/upload"
 	"werk-ticketing/internal/user"
+	"werk-ticketing/internal/webhook"
 )
 
 // Router holds all route dependencies
 type Router struct {
-	authHandler   *auth.Handler
-	ticketHandler *ticket.Handler
-	userHandler   *user.Handler
-	tenantHandler *tenant.Handler
-	authService   auth.Service
-	tenantRepo    tenant.Repository
-	logger        *logrus.Logger
+	authHandler         *auth.Handler
+	ticketHandler       *ticket.Handler
+	userHandler         *user.Handler
+	tenantHandler       *tenant.Handler
+	uploadHandler       *upload.Handler
+	emailHandler        *email.Handler
+	notificationHandler *notification.Handler
+	invgateHookHandler  *invgatehook.Handler
+	webhookHandler      *webhook.Handler
+	streamHandler       *realtime.Handler
+	auditHandler        *audit.Handler
+	healthHandler       *health.Handler
+	authService         auth.Service
+	tenantRepo          tenant.Repository
+	userRepo            user.Repository
+	filesPath           string
+	accessLogSampleRate float64
+	metricsToken        string
+	logger              *logrus.Logger
 }
 
 // NewRouter creates a new router instance
@@ -30,18 +50,42 @@ func NewRouter(
 	ticketHandler *ticket.Handler,
 	userHandler *user.Handler,
 	tenantHandler *tenant.Handler,
+	uploadHandler *upload.Handler,
+	emailHandler *email.Handler,
+	notificationHandler *notification.Handler,
+	invgateHookHandler *invgatehook.Handler,
+	webhookHandler *webhook.Handler,
+	streamHandler *realtime.Handler,
+	auditHandler *audit.Handler,
+	healthHandler *health.Handler,
 	authService auth.Service,
 	tenantRepo tenant.Repository,
+	userRepo user.Repository,
+	filesPath string,
+	accessLogSampleRate float64,
+	metricsToken string,
 	logger *logrus.Logger,
 ) *Router {
 	return &Router{
-		authHandler:   authHandler,
-		ticketHandler: ticketHandler,
-		userHandler:   userHandler,
-		tenantHandler: tenantHandler,
-		authService:   authService,
-		tenantRepo:    tenantRepo,
-		logger:        logger,
+		authHandler:         authHandler,
+		ticketHandler:       ticketHandler,
+		userHandler:         userHandler,
+		tenantHandler:       tenantHandler,
+		uploadHandler:       uploadHandler,
+		emailHandler:        emailHandler,
+		notificationHandler: notificationHandler,
+		invgateHookHandler:  invgateHookHandler,
+		webhookHandler:      webhookHandler,
+		streamHandler:       streamHandler,
+		auditHandler:        auditHandler,
+		healthHandler:       healthHandler,
+		authService:         authService,
+		tenantRepo:          tenantRepo,
+		userRepo:            userRepo,
+		filesPath:           filesPath,
+		accessLogSampleRate: accessLogSampleRate,
+		metricsToken:        metricsToken,
+		logger:              logger,
 	}
 }
 
@@ -51,32 +95,39 @@ func (r *Router) SetupRoutes() *gin.Engine {
 
 	// Global middleware (order matters!)
 	router.Use(
-		gin.Logger(),
+		middleware.RequestID(r.logger),
+		middleware.Tracing(),
+		middleware.Logging(r.logger, r.accessLogSampleRate),
+		middleware.Metrics(),
 		middleware.Recover(r.logger),
 		middleware.CORS(),
 		middleware.SecurityHeaders(),
 		middleware.RateLimit(),
+		middleware.AuditContext(),
 	)
 
 	// Set max request size
-	router.MaxMultipartMemory = constants.MaxRequestSize
+	router.MaxMultipartMemory = constants.MultipartMemoryLimit
 
 	// API versioning: /api/v1
 	apiV1 := router.Group("/api/v1")
 
 	// Public routes (no tenant or auth required)
 	// These are accessible without any authentication or tenant context
-	r.setupAuthRoutes(apiV1)         // Login, register, forgot-password, etc.
-	r.setupPublicTenantRoutes(apiV1) // Tenant public info endpoint
+	r.setupAuthRoutes(apiV1)               // Login, register, forgot-password, etc.
+	r.setupPublicTenantRoutes(apiV1)       // Tenant public info endpoint
+	r.setupPublicNotificationRoutes(apiV1) // Unsubscribe links from emails
+	r.setupInboundWebhookRoutes(apiV1)     // InvGate ticket change webhooks
 
 	// Public reference data endpoints (no auth, but may need tenant context in future)
 	apiV1.GET("/categories", r.ticketHandler.GetCategories)
 	apiV1.GET("/ticket-meta", r.ticketHandler.GetMeta)
 	apiV1.GET("/statuses", r.ticketHandler.GetStatuses)
 
-	// Articles endpoint (public, no auth required)
+	// Articles endpoint (no auth required; the tenant decides whether its
+	// portal has a knowledge base)
 	articleRoutes := apiV1.Group("/articles")
-	articleRoutes.Use(middleware.ArticleRateLimit())
+	articleRoutes.Use(middleware.ArticleRateLimit(), middleware.WithTenant(r.tenantRepo, middleware.TenantFromHeader))
 	{
 		articleRoutes.GET("", r.ticketHandler.GetArticlesByCategory)
 	}
@@ -87,7 +138,11 @@ func (r *Router) SetupRoutes() *gin.Engine {
 	protectedRoutes.Use(middleware.WithTenant(r.tenantRepo, middleware.TenantFromHeader))
 	{
 		r.setupTicketRoutes(protectedRoutes)
-		r.setupAdminTenantRoutes(protectedRoutes) // Admin tenant CRUD routes
+		r.setupAdminTenantRoutes(protectedRoutes)  // Admin tenant CRUD routes
+		r.setupAdminEmailRoutes(protectedRoutes)   // Admin email template routes
+		r.setupAdminWebhookRoutes(protectedRoutes) // Admin tenant webhook routes
+		r.setupAdminAuditRoutes(protectedRoutes)   // Admin audit log routes
+		r.setupNotificationRoutes(protectedRoutes) // Ticket update email preferences
 
 		// User endpoint (proxy to InvGate user API, requires auth)
 		userRoutes := protectedRoutes.Group("/users")
@@ -100,12 +155,16 @@ func (r *Router) SetupRoutes() *gin.Engine {
 		}
 	}
 
-	// Upload Endpoint (Protected)
-	protectedRoutes.POST("/upload", func(c *gin.Context) {
-		upload.NewHandler().UploadFile(c)
-	})
+	// Upload endpoints (tenant + auth; private files are tenant-scoped)
+	uploadRoutes := protectedRoutes.Group("")
+	uploadRoutes.Use(middleware.WithAuth(r.authService))
+	{
+		uploadRoutes.POST("/upload", middleware.UploadDeadlines(), r.uploadHandler.UploadFile)
+		uploadRoutes.GET("/uploads/:id", r.uploadHandler.Get)
+	}
 
-	// Health check endpoint (no versioning, no tenant required)
+	// Health check endpoint (no versioning, no tenant required); kept for
+	// existing monitors, it does not check dependencies - see /readyz
 	router.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{
 			"status":  "ok",
@@ -113,8 +172,19 @@ func (r *Router) SetupRoutes() *gin.Engine {
 		})
 	})
 
-	// Serve uploaded files (Static)
-	router.Static("/uploads", "./uploads")
+	// Kubernetes-style probes: /livez checks the process only, /readyz its
+	// dependencies (database, schema, email and optionally InvGate)
+	router.GET("/livez", r.healthHandler.Livez)
+	router.GET("/readyz", r.healthHandler.Readyz)
+
+	// Prometheus metrics (no versioning, no tenant required)
+	router.GET("/metrics", middleware.MetricsAuth(r.metricsToken), gin.WrapH(metrics.Handler()))
+
+	// Public uploads (tenant logos), stable URLs without authentication
+	router.GET("/uploads/:id", r.uploadHandler.GetPublic)
+
+	// Serve locally stored files through signed, time-limited URLs
+	router.GET(r.filesPath+"/*key", r.uploadHandler.ServeFile)
 
 	return router
 }
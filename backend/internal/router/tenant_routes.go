@@ -1,6 +1,11 @@
 package router
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/user"
+)
 
 // setupPublicTenantRoutes configures public tenant routes (no auth or tenant middleware required)
 func (r *Router) setupPublicTenantRoutes(api *gin.RouterGroup) {
@@ -9,12 +14,11 @@ func (r *Router) setupPublicTenantRoutes(api *gin.RouterGroup) {
 	api.GET("/tenants/:slug/info", r.tenantHandler.GetPublicInfo)
 }
 
-// setupAdminTenantRoutes configures admin tenant management routes (requires tenant context and auth)
+// setupAdminTenantRoutes configures admin tenant management routes (requires tenant context, auth and the operator role)
 func (r *Router) setupAdminTenantRoutes(api *gin.RouterGroup) {
-	// Admin routes for tenant management (require authentication)
+	// Tenant management spans tenants, so it is limited to operators
 	adminRoutes := api.Group("/admin/tenants")
-	// TODO: Add admin role check middleware here
-	// adminRoutes.Use(middleware.WithAdminRole(r.authService))
+	adminRoutes.Use(middleware.WithAuth(r.authService), middleware.RequireRole(r.userRepo, user.RoleOperator, r.logger))
 	{
 		// POST /admin/tenants - Create a new tenant
 		adminRoutes.POST("", r.tenantHandler.Create)
@@ -14,13 +14,19 @@ func (r *Router) setupTicketRoutes(api *gin.RouterGroup) {
 	{
 		// POST /api/tickets - Create a new ticket
 		// Creates a ticket in InvGate Armmada and saves it to local database
-		ticketRoutes.POST("", r.ticketHandler.Create)
+		// Multipart bodies may take longer than the server ReadTimeout to arrive
+		ticketRoutes.POST("", middleware.UploadDeadlines(), r.ticketHandler.Create)
 
 		// GET /api/tickets - List all tickets
 		// Returns paginated list of tickets, filtered by creator_id (optional)
 		// Query params: ?creator_id=email&page=1&limit=10
 		ticketRoutes.GET("", r.ticketHandler.List)
 
+		// GET /api/tickets/stream - Server-Sent Events for changes to the user's tickets
+		// Events: status_changed, comment_added, solution_proposed; "reset" means reload
+		// Resume with the Last-Event-ID header (or ?last_event_id=)
+		ticketRoutes.GET("/stream", r.streamHandler.Stream)
+
 		// GET /api/tickets/:id - Get ticket detail by ID
 		// Returns detailed information about a specific ticket
 		// Path param: id (InvGate ticket ID)
@@ -37,7 +43,7 @@ func (r *Router) setupTicketRoutes(api *gin.RouterGroup) {
 		ticketRoutes.GET("/:id/comments", r.ticketHandler.GetComments)
 
 		// POST /api/tickets/:id/comments - Add comment to ticket
-		ticketRoutes.POST("/:id/comments", r.ticketHandler.AddComment)
+		ticketRoutes.POST("/:id/comments", middleware.UploadDeadlines(), r.ticketHandler.AddComment)
 
 		// GET /api/tickets/attachments/:attachment_id - Download attachment file
 		ticketRoutes.GET("/attachments/:attachment_id", r.ticketHandler.GetAttachment)
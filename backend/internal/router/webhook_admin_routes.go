@@ -0,0 +1,40 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/user"
+)
+
+// setupAdminWebhookRoutes configures tenant webhook subscription and delivery log routes (requires tenant context, auth and the admin role)
+func (r *Router) setupAdminWebhookRoutes(api *gin.RouterGroup) {
+	adminRoutes := api.Group("/admin")
+	adminRoutes.Use(middleware.WithAuth(r.authService), middleware.RequireRole(r.userRepo, user.RoleAdmin, r.logger))
+	{
+		// GET /admin/webhook-events - List event types webhooks can subscribe to
+		adminRoutes.GET("/webhook-events", r.webhookHandler.ListEvents)
+
+		// GET /admin/tenants/:id/webhooks - List a tenant's webhook subscriptions
+		adminRoutes.GET("/tenants/:id/webhooks", r.webhookHandler.ListSubscriptions)
+
+		// POST /admin/tenants/:id/webhooks - Create a subscription; the signing secret is returned once
+		// Body JSON: { "url": string, "events": [string], "secret"?: string, "description"?: string, "is_active"?: bool }
+		adminRoutes.POST("/tenants/:id/webhooks", r.webhookHandler.CreateSubscription)
+
+		// PUT /admin/tenants/:id/webhooks/:webhook_id - Update a subscription
+		adminRoutes.PUT("/tenants/:id/webhooks/:webhook_id", r.webhookHandler.UpdateSubscription)
+
+		// DELETE /admin/tenants/:id/webhooks/:webhook_id - Delete a subscription and its delivery log
+		adminRoutes.DELETE("/tenants/:id/webhooks/:webhook_id", r.webhookHandler.DeleteSubscription)
+
+		// GET /admin/tenants/:id/webhooks/:webhook_id/deliveries - Latest deliveries, optionally by status
+		adminRoutes.GET("/tenants/:id/webhooks/:webhook_id/deliveries", r.webhookHandler.ListDeliveries)
+
+		// GET /admin/webhook-deliveries/:id - One delivery with its payload and response
+		adminRoutes.GET("/webhook-deliveries/:id", r.webhookHandler.GetDelivery)
+
+		// POST /admin/webhook-deliveries/:id/redeliver - Send a logged delivery again
+		adminRoutes.POST("/webhook-deliveries/:id/redeliver", r.webhookHandler.Redeliver)
+	}
+}
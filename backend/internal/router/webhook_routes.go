@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// setupInboundWebhookRoutes configures routes InvGate calls when tickets change (no tenant header or auth;
+// the tenant comes from the path and the request is verified with its webhook secret)
+func (r *Router) setupInboundWebhookRoutes(api *gin.RouterGroup) {
+	// POST /webhooks/invgate/:tenant - Ticket updated, comment added or solution proposed
+	// Headers: X-Webhook-Signature: sha256=<hex HMAC of "<timestamp>.<delivery ID>.<body>">
+	// with X-Webhook-Timestamp and X-Webhook-Delivery, or X-Webhook-Token: <secret>
+	api.POST("/webhooks/invgate/:tenant", r.invgateHookHandler.Receive)
+}
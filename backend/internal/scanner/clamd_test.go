@@ -0,0 +1,109 @@
+package scanner_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"werk-ticketing/internal/scanner"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+// startStubClamd runs a minimal clamd that answers INSTREAM requests,
+// flagging streams that contain the EICAR test string.
+func startStubClamd(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveStubClamd(conn)
+		}
+	}()
+	return "tcp://" + ln.Addr().String()
+}
+
+func serveStubClamd(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	cmd, err := r.ReadString(0)
+	if err != nil || cmd != "zINSTREAM\x00" {
+		io.WriteString(conn, "UNKNOWN COMMAND\x00")
+		return
+	}
+
+	var data bytes.Buffer
+	for {
+		var size uint32
+		if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+			return
+		}
+		if size == 0 {
+			break
+		}
+		if _, err := io.CopyN(&data, r, int64(size)); err != nil {
+			return
+		}
+	}
+
+	if bytes.Contains(data.Bytes(), []byte("EICAR-STANDARD-ANTIVIRUS-TEST-FILE")) {
+		io.WriteString(conn, "stream: Eicar-Test-Signature FOUND\x00")
+		return
+	}
+	io.WriteString(conn, "stream: OK\x00")
+}
+
+func TestClamdScan(t *testing.T) {
+	s := scanner.NewClamd(startStubClamd(t))
+	ctx := context.Background()
+
+	// Larger than one INSTREAM chunk so the stream is split.
+	clean := strings.Repeat("harmless ", 20000)
+	result, err := s.Scan(ctx, strings.NewReader(clean))
+	if err != nil || result.Infected {
+		t.Fatalf("clean scan = %+v, %v", result, err)
+	}
+
+	result, err = s.Scan(ctx, strings.NewReader(clean+eicar))
+	if err != nil {
+		t.Fatalf("infected scan: %v", err)
+	}
+	if !result.Infected || result.Signature != "Eicar-Test-Signature" {
+		t.Errorf("infected scan = %+v", result)
+	}
+}
+
+func TestClamdUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	if _, err := scanner.NewClamd(addr).Scan(context.Background(), strings.NewReader("data")); err == nil {
+		t.Error("expected an error when clamd is unreachable")
+	}
+}
+
+func TestNoopReportsClean(t *testing.T) {
+	result, err := scanner.NewNoop().Scan(context.Background(), strings.NewReader(eicar))
+	if err != nil || result.Infected {
+		t.Errorf("noop scan = %+v, %v", result, err)
+	}
+}
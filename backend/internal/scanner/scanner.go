@@ -0,0 +1,51 @@
+package scanner
+
+import (
+	"context"
+	"io"
+	"mime/multipart"
+
+	"werk-ticketing/internal/config"
+)
+
+// Scanner inspects file content for malware before it leaves the portal.
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+// Result is the verdict for a single file. Signature names the detected
+// malware when Infected is true.
+type Result struct {
+	Infected  bool
+	Signature string
+}
+
+// New returns the scanner selected by configuration: clamd when
+// CLAMD_ADDRESS is set, otherwise a no-op scanner.
+func New(cfg *config.Config) Scanner {
+	if cfg.ClamdAddress == "" {
+		return NewNoop()
+	}
+	return NewClamd(cfg.ClamdAddress)
+}
+
+// ScanFile scans an uploaded multipart file.
+func ScanFile(ctx context.Context, s Scanner, fh *multipart.FileHeader) (Result, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return Result{}, err
+	}
+	defer file.Close()
+	return s.Scan(ctx, file)
+}
+
+type noop struct{}
+
+// NewNoop returns a scanner that reports every file as clean.
+func NewNoop() Scanner {
+	return noop{}
+}
+
+func (noop) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	return Result{}, nil
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"werk-ticketing/internal/config"
+)
+
+// ErrNotFound is returned when a key does not exist.
+var ErrNotFound = stdErrors.New("object not found")
+
+// ErrInvalidKey is returned for keys that are empty or escape their prefix.
+var ErrInvalidKey = stdErrors.New("invalid object key")
+
+// Object describes a stored blob.
+type Object struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Blob stores files by key. Keys always start with the owning tenant ID,
+// see TenantKey.
+type Blob interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens a stored object. The caller must close the returned reader.
+	Get(ctx context.Context, key string) (io.ReadCloser, *Object, error)
+	Delete(ctx context.Context, key string) error
+	// SignedURL returns a URL that allows downloading key until ttl elapses.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// New returns the backend selected by STORAGE_DRIVER.
+func New(cfg *config.Config) (Blob, error) {
+	switch cfg.StorageDriver {
+	case "", "local":
+		return NewLocal(cfg.StorageLocalDir, cfg.StorageSigningKey, cfg.StoragePublicPath), nil
+	case "s3":
+		return NewS3(S3Options{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			UseSSL:    cfg.S3UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.StorageDriver)
+	}
+}
+
+// TenantKey builds the key for name inside the tenant's prefix.
+func TenantKey(tenantID string, parts ...string) (string, error) {
+	if tenantID == "" || strings.ContainsAny(tenantID, "/\\") {
+		return "", ErrInvalidKey
+	}
+	key := path.Join(append([]string{tenantID}, parts...)...)
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(key, tenantID+"/") {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
+
+// TenantOf returns the tenant ID a key belongs to.
+func TenantOf(key string) string {
+	tenantID, _, _ := strings.Cut(key, "/")
+	return tenantID
+}
+
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") || path.Clean(key) != key {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == ".." || segment == "." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package storage_test
+
+import (
+	"context"
+	stdErrors "errors"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"werk-ticketing/internal/storage"
+)
+
+func TestTenantKey(t *testing.T) {
+	key, err := storage.TenantKey("tenant-1", "uploads", "logo.png")
+	if err != nil || key != "tenant-1/uploads/logo.png" {
+		t.Fatalf("TenantKey = %q, %v", key, err)
+	}
+	if storage.TenantOf(key) != "tenant-1" {
+		t.Errorf("TenantOf(%q) = %q", key, storage.TenantOf(key))
+	}
+
+	for _, parts := range [][]string{
+		{"tenant-1", "../tenant-2/secret.png"},
+		{"", "logo.png"},
+		{"tenant/1", "logo.png"},
+		{"tenant-1", ""},
+	} {
+		if key, err := storage.TenantKey(parts[0], parts[1]); err == nil {
+			t.Errorf("TenantKey(%q, %q) = %q, want error", parts[0], parts[1], key)
+		}
+	}
+}
+
+// exercise runs the Blob contract against a backend.
+func exercise(t *testing.T, blob storage.Blob) {
+	t.Helper()
+	ctx := context.Background()
+	key, _ := storage.TenantKey("tenant-1", "uploads", "note.txt")
+
+	if err := blob.Put(ctx, key, strings.NewReader("hello"), 5, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	body, obj, err := blob.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "hello" || obj.Size != 5 || !strings.HasPrefix(obj.ContentType, "text/plain") {
+		t.Errorf("Get = %q, %+v", data, obj)
+	}
+
+	if err := blob.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, _, err := blob.Get(ctx, key); !stdErrors.Is(err, storage.ErrNotFound) {
+		t.Errorf("Get after Delete error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalBlob(t *testing.T) {
+	exercise(t, storage.NewLocal(t.TempDir(), "secret", "/files"))
+}
+
+func TestLocalSignedURL(t *testing.T) {
+	local := storage.NewLocal(t.TempDir(), "secret", "/files")
+	key := "tenant-1/uploads/logo.png"
+
+	signed, err := local.SignedURL(context.Background(), key, time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	u, err := url.Parse(signed)
+	if err != nil || u.Path != "/files/"+key {
+		t.Fatalf("SignedURL = %q", signed)
+	}
+	q := u.Query()
+	if err := local.Verify(key, q.Get("expires"), q.Get("signature")); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := local.Verify("tenant-2/uploads/logo.png", q.Get("expires"), q.Get("signature")); err == nil {
+		t.Error("signature accepted for another key")
+	}
+
+	expired, _ := local.SignedURL(context.Background(), key, -time.Minute)
+	u, _ = url.Parse(expired)
+	if err := local.Verify(key, u.Query().Get("expires"), u.Query().Get("signature")); err == nil {
+		t.Error("expired signature accepted")
+	}
+}
+
+// TestS3Blob runs against a real S3-compatible server, e.g.
+// `docker run -p 9000:9000 minio/minio server /data` with a "werk-test" bucket.
+func TestS3Blob(t *testing.T) {
+	endpoint := os.Getenv("STORAGE_TEST_S3_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("STORAGE_TEST_S3_ENDPOINT not set")
+	}
+	blob, err := storage.NewS3(storage.S3Options{
+		Endpoint:  endpoint,
+		Bucket:    "werk-test",
+		AccessKey: os.Getenv("STORAGE_TEST_S3_ACCESS_KEY"),
+		SecretKey: os.Getenv("STORAGE_TEST_S3_SECRET_KEY"),
+	})
+	if err != nil {
+		t.Fatalf("NewS3: %v", err)
+	}
+	exercise(t, blob)
+
+	key := "tenant-1/uploads/signed.txt"
+	if err := blob.Put(context.Background(), key, strings.NewReader("signed"), 6, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	defer blob.Delete(context.Background(), key)
+
+	signed, err := blob.SignedURL(context.Background(), key, time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	resp, err := http.Get(signed)
+	if err != nil {
+		t.Fatalf("GET signed URL: %v", err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(data) != "signed" {
+		t.Errorf("signed download = %d %q", resp.StatusCode, data)
+	}
+}
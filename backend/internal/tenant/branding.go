@@ -0,0 +1,167 @@
+package tenant
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"werk-ticketing/internal/validator"
+)
+
+// BrandingVersion is the current schema version of the Branding document.
+// Documents stored with an older version are upgraded by Normalize.
+const BrandingVersion = 1
+
+// Languages supported by the portal.
+const (
+	LanguageIndonesian = "id"
+	LanguageEnglish    = "en"
+)
+
+var hexColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Branding is the tenant's portal configuration document. It is stored as
+// JSON on the tenant and served, normalized, from /tenants/:slug/info.
+type Branding struct {
+	Version int `json:"version"`
+
+	SecondaryColor string `json:"secondary_color,omitempty"`
+	AccentColor    string `json:"accent_color,omitempty"`
+	// FaviconURL overrides the favicon generated from the logo.
+	FaviconURL string `json:"favicon_url,omitempty"`
+
+	PortalTitle     string         `json:"portal_title,omitempty"`
+	LoginMessage    string         `json:"login_message,omitempty"`
+	Support         SupportContact `json:"support"`
+	DefaultLanguage string         `json:"default_language,omitempty"`
+	Features        Features       `json:"features"`
+}
+
+// SupportContact is shown to portal users who need help outside the portal.
+type SupportContact struct {
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+	URL   string `json:"url,omitempty"`
+	Hours string `json:"hours,omitempty"`
+}
+
+// Features toggles portal functionality. Nil fields take the defaults
+// applied by Normalize.
+type Features struct {
+	KnowledgeBase  *bool `json:"knowledge_base,omitempty"`
+	Attachments    *bool `json:"attachments,omitempty"`
+	RatingRequired *bool `json:"rating_required,omitempty"`
+}
+
+// BrandingFieldError reports an invalid field of a Branding document.
+type BrandingFieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// BrandingError is returned by Validate when the document is invalid.
+type BrandingError struct {
+	Fields []BrandingFieldError
+}
+
+func (e *BrandingError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", f.Field, f.Message))
+	}
+	return "invalid branding: " + strings.Join(parts, "; ")
+}
+
+// Normalize returns a copy of b upgraded to BrandingVersion with defaults
+// filled in. It is safe to call on a nil document.
+func (b *Branding) Normalize() Branding {
+	var out Branding
+	if b != nil {
+		out = *b
+	}
+	out.Version = BrandingVersion
+	out.SecondaryColor = strings.ToUpper(strings.TrimSpace(out.SecondaryColor))
+	out.AccentColor = strings.ToUpper(strings.TrimSpace(out.AccentColor))
+	out.FaviconURL = strings.TrimSpace(out.FaviconURL)
+	out.PortalTitle = strings.TrimSpace(out.PortalTitle)
+	out.LoginMessage = strings.TrimSpace(out.LoginMessage)
+	out.Support = SupportContact{
+		Email: strings.TrimSpace(out.Support.Email),
+		Phone: strings.TrimSpace(out.Support.Phone),
+		URL:   strings.TrimSpace(out.Support.URL),
+		Hours: strings.TrimSpace(out.Support.Hours),
+	}
+	if out.DefaultLanguage == "" {
+		out.DefaultLanguage = LanguageIndonesian
+	}
+	out.Features = Features{
+		KnowledgeBase:  boolOr(out.Features.KnowledgeBase, true),
+		Attachments:    boolOr(out.Features.Attachments, true),
+		RatingRequired: boolOr(out.Features.RatingRequired, true),
+	}
+	return out
+}
+
+// Validate checks the document and reports every invalid field.
+func (b *Branding) Validate() error {
+	var fields []BrandingFieldError
+	fail := func(field, message string) {
+		fields = append(fields, BrandingFieldError{Field: field, Message: message})
+	}
+
+	if b.Version > BrandingVersion {
+		fail("version", fmt.Sprintf("must be at most %d", BrandingVersion))
+	}
+	for field, color := range map[string]string{"secondary_color": b.SecondaryColor, "accent_color": b.AccentColor} {
+		if color != "" && !hexColorRegex.MatchString(color) {
+			fail(field, "must be a hex color such as #1976D2")
+		}
+	}
+	if b.FaviconURL != "" && !validBrandingURL(b.FaviconURL) {
+		fail("favicon_url", "must be an http(s) URL or a path starting with /")
+	}
+	if utf8.RuneCountInString(b.PortalTitle) > 100 {
+		fail("portal_title", "must be at most 100 characters")
+	}
+	if utf8.RuneCountInString(b.LoginMessage) > 1000 {
+		fail("login_message", "must be at most 1000 characters")
+	}
+	if b.Support.Email != "" && !validator.ValidateEmail(b.Support.Email) {
+		fail("support.email", "must be a valid email address")
+	}
+	if len(b.Support.Phone) > 50 {
+		fail("support.phone", "must be at most 50 characters")
+	}
+	if b.Support.URL != "" && !validBrandingURL(b.Support.URL) {
+		fail("support.url", "must be an http(s) URL or a path starting with /")
+	}
+	if utf8.RuneCountInString(b.Support.Hours) > 200 {
+		fail("support.hours", "must be at most 200 characters")
+	}
+	if b.DefaultLanguage != "" && b.DefaultLanguage != LanguageIndonesian && b.DefaultLanguage != LanguageEnglish {
+		fail("default_language", "must be one of: id, en")
+	}
+
+	if len(fields) > 0 {
+		return &BrandingError{Fields: fields}
+	}
+	return nil
+}
+
+// validBrandingURL accepts absolute http(s) URLs and site-relative paths.
+func validBrandingURL(raw string) bool {
+	if strings.HasPrefix(raw, "/") && !strings.HasPrefix(raw, "//") {
+		return true
+	}
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func boolOr(v *bool, def bool) *bool {
+	if v != nil {
+		return v
+	}
+	return &def
+}
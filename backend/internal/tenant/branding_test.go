@@ -0,0 +1,56 @@
+package tenant
+
+import (
+	stdErrors "errors"
+	"testing"
+)
+
+func TestBrandingNormalizeDefaults(t *testing.T) {
+	b := (*Branding)(nil).Normalize()
+	if b.Version != BrandingVersion || b.DefaultLanguage != LanguageIndonesian {
+		t.Errorf("Normalize(nil) = %+v", b)
+	}
+	if !*b.Features.KnowledgeBase || !*b.Features.Attachments || !*b.Features.RatingRequired {
+		t.Errorf("default features = %+v", b.Features)
+	}
+
+	off := false
+	b = (&Branding{Version: 0, AccentColor: " #ff0000 ", Features: Features{Attachments: &off}}).Normalize()
+	if b.Version != BrandingVersion || b.AccentColor != "#FF0000" || *b.Features.Attachments || !*b.Features.KnowledgeBase {
+		t.Errorf("Normalize = %+v", b)
+	}
+}
+
+func TestBrandingValidate(t *testing.T) {
+	valid := Branding{
+		Version:         BrandingVersion,
+		SecondaryColor:  "#112233",
+		FaviconURL:      "/uploads/favicon.png",
+		Support:         SupportContact{Email: "help@example.com", URL: "https://help.example.com"},
+		DefaultLanguage: LanguageEnglish,
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Validate(valid) = %v", err)
+	}
+
+	invalid := Branding{
+		Version:         BrandingVersion + 1,
+		AccentColor:     "red",
+		FaviconURL:      "javascript:alert(1)",
+		Support:         SupportContact{Email: "not-an-email", URL: "//evil.example.com"},
+		DefaultLanguage: "fr",
+	}
+	var branding *BrandingError
+	if err := invalid.Validate(); !stdErrors.As(err, &branding) {
+		t.Fatalf("Validate(invalid) = %v", err)
+	}
+	got := map[string]bool{}
+	for _, f := range branding.Fields {
+		got[f.Field] = true
+	}
+	for _, field := range []string{"version", "accent_color", "favicon_url", "support.email", "support.url", "default_language"} {
+		if !got[field] {
+			t.Errorf("missing error for %s in %v", field, branding.Fields)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package tenant
+
+import "context"
+
+type idKey struct{}
+
+// WithID returns a context carrying the ID of the tenant a request was made
+// for, as identified by the tenant middleware.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// IDFrom returns the tenant ID stored in ctx, or "" when there is none.
+func IDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
@@ -11,4 +11,8 @@ var (
 
 	// ErrTenantSlugExists is returned when a tenant slug already exists
 	ErrTenantSlugExists = errors.New("tenant slug already exists")
+
+	// ErrInvalidLogo is returned when a logo URL points to an upload that
+	// is not a logo
+	ErrInvalidLogo = errors.New("logo_url does not reference an uploaded logo")
 )
@@ -1,23 +1,41 @@
 package tenant
 
 import (
+	"context"
+	stdErrors "errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"werk-ticketing/internal/attachment"
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/errors"
 	"werk-ticketing/internal/response"
 )
 
+// BrandingAssets resolves and cleans up uploaded tenant logos.
+type BrandingAssets interface {
+	// LogoURLs returns the URL of each standard logo size.
+	LogoURLs(url string) map[string]string
+	// PublishLogo makes an uploaded logo public once a tenant uses it. It
+	// returns ErrInvalidLogo when url references an upload that is not a
+	// logo uploaded by tenantID.
+	PublishLogo(ctx context.Context, tenantID, url string) error
+	// ReleaseLogo removes a logo the tenant no longer uses.
+	ReleaseLogo(ctx context.Context, url string)
+}
+
 // Handler handles HTTP requests for tenant management
 type Handler struct {
-	repo Repository
+	repo   Repository
+	assets BrandingAssets
+	audit  audit.Recorder
 }
 
 // NewHandler creates a new tenant handler
-func NewHandler(repo Repository) *Handler {
-	return &Handler{repo: repo}
+func NewHandler(repo Repository, assets BrandingAssets, auditLog audit.Recorder) *Handler {
+	return &Handler{repo: repo, assets: assets, audit: auditLog}
 }
 
 // Create handles POST /admin/tenants
@@ -28,6 +46,11 @@ func (h *Handler) Create(c *gin.Context) {
 		return
 	}
 
+	branding, ok := validateBranding(c, req.Branding)
+	if !ok || !validateNotifications(c, req.Notifications) || !validateAttachmentPolicy(c, req.AttachmentPolicy) {
+		return
+	}
+
 	// Check if slug already exists
 	existing, err := h.repo.FindBySlug(c.Request.Context(), req.Slug)
 	if err != nil {
@@ -51,8 +74,13 @@ func (h *Handler) Create(c *gin.Context) {
 		InvGatePassword:   req.InvGatePassword,
 		EmailDomain:       req.EmailDomain,
 		EmailSender:       req.EmailSender,
+		EmailAPIKey:       req.EmailAPIKey,
+		WebhookSecret:     req.WebhookSecret,
 		LogoURL:           req.LogoURL,
 		PrimaryColor:      req.PrimaryColor,
+		Branding:          branding,
+		AttachmentPolicy:  req.AttachmentPolicy,
+		Notifications:     req.Notifications,
 		IsActive:          true,
 	}
 
@@ -60,10 +88,21 @@ func (h *Handler) Create(c *gin.Context) {
 		tenant.PrimaryColor = "#1976D2"
 	}
 
+	if tenant.LogoURL != "" && !h.publishLogo(c, tenant.LogoURL) {
+		return
+	}
+
 	if err := h.repo.Create(c.Request.Context(), tenant); err != nil {
 		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to create tenant")
 		return
 	}
+	h.audit.Record(c.Request.Context(), &audit.Event{
+		TenantID:   tenant.ID,
+		Action:     audit.ActionTenantCreated,
+		TargetType: audit.TargetTenant,
+		TargetID:   tenant.ID,
+		Changes:    audit.Diff(nil, tenant),
+	})
 
 	response.Success(c, http.StatusCreated, tenant.ToPublicInfo())
 }
@@ -113,6 +152,10 @@ func (h *Handler) Update(c *gin.Context) {
 		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid request body")
 		return
 	}
+	branding, ok := validateBranding(c, req.Branding)
+	if !ok || !validateNotifications(c, req.Notifications) || !validateAttachmentPolicy(c, req.AttachmentPolicy) {
+		return
+	}
 
 	tenant, err := h.repo.FindByIDIncludingInactive(c.Request.Context(), id)
 	if err != nil {
@@ -124,6 +167,8 @@ func (h *Handler) Update(c *gin.Context) {
 		return
 	}
 
+	before := *tenant
+
 	// Update fields if provided
 	if req.Name != "" {
 		tenant.Name = req.Name
@@ -152,6 +197,13 @@ func (h *Handler) Update(c *gin.Context) {
 	if req.EmailSender != "" {
 		tenant.EmailSender = req.EmailSender
 	}
+	if req.EmailAPIKey != "" {
+		tenant.EmailAPIKey = req.EmailAPIKey
+	}
+	if req.WebhookSecret != "" {
+		tenant.WebhookSecret = req.WebhookSecret
+	}
+	previousLogoURL := tenant.LogoURL
 	if req.LogoURL != nil {
 		tenant.LogoURL = *req.LogoURL
 	}
@@ -161,15 +213,66 @@ func (h *Handler) Update(c *gin.Context) {
 	if req.IsActive != nil {
 		tenant.IsActive = *req.IsActive
 	}
+	if branding != nil {
+		tenant.Branding = branding
+	}
+	if req.AttachmentPolicy != nil {
+		tenant.AttachmentPolicy = req.AttachmentPolicy
+	}
+	if req.Notifications != nil {
+		tenant.Notifications = req.Notifications
+	}
+
+	if tenant.LogoURL != previousLogoURL && tenant.LogoURL != "" && !h.publishLogo(c, tenant.LogoURL) {
+		return
+	}
+
+	changes := audit.Diff(&before, tenant)
+	for field, value := range map[string]string{
+		"invgate_password": req.InvGatePassword,
+		"email_api_key":    req.EmailAPIKey,
+		"webhook_secret":   req.WebhookSecret,
+	} {
+		if value != "" {
+			changes.Secret(field)
+		}
+	}
 
 	if err := h.repo.Update(c.Request.Context(), tenant); err != nil {
 		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to update tenant")
 		return
 	}
+	h.audit.Record(c.Request.Context(), &audit.Event{
+		TenantID:   tenant.ID,
+		Action:     audit.ActionTenantUpdated,
+		TargetType: audit.TargetTenant,
+		TargetID:   tenant.ID,
+		Changes:    changes,
+	})
+
+	if previousLogoURL != "" && previousLogoURL != tenant.LogoURL {
+		h.assets.ReleaseLogo(c.Request.Context(), previousLogoURL)
+	}
 
 	response.Success(c, http.StatusOK, tenant)
 }
 
+// publishLogo makes the uploaded logo behind url public, responding with an
+// error and returning false when it cannot. Only logos uploaded from the
+// caller's tenant may be used.
+func (h *Handler) publishLogo(c *gin.Context, url string) bool {
+	err := h.assets.PublishLogo(c.Request.Context(), IDFrom(c.Request.Context()), url)
+	if stdErrors.Is(err, ErrInvalidLogo) {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, err.Error())
+		return false
+	}
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to publish logo")
+		return false
+	}
+	return true
+}
+
 // Delete handles DELETE /admin/tenants/:id
 func (h *Handler) Delete(c *gin.Context) {
 	id := c.Param("id")
@@ -192,6 +295,13 @@ func (h *Handler) Delete(c *gin.Context) {
 		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to delete tenant")
 		return
 	}
+	h.audit.Record(c.Request.Context(), &audit.Event{
+		TenantID:   tenant.ID,
+		Action:     audit.ActionTenantDeleted,
+		TargetType: audit.TargetTenant,
+		TargetID:   tenant.ID,
+		Changes:    audit.Changes{"is_active": {Before: tenant.IsActive, After: false}},
+	})
 
 	response.Success(c, http.StatusOK, gin.H{"message": "tenant deleted successfully"})
 }
@@ -222,11 +332,23 @@ func (h *Handler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
+	wasActive := tenant.IsActive
 	tenant.IsActive = req.IsActive
 	if err := h.repo.Update(c.Request.Context(), tenant); err != nil {
 		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to update tenant status")
 		return
 	}
+	action := audit.ActionTenantDeactivated
+	if req.IsActive {
+		action = audit.ActionTenantActivated
+	}
+	h.audit.Record(c.Request.Context(), &audit.Event{
+		TenantID:   tenant.ID,
+		Action:     action,
+		TargetType: audit.TargetTenant,
+		TargetID:   tenant.ID,
+		Changes:    audit.Changes{"is_active": {Before: wasActive, After: req.IsActive}},
+	})
 
 	response.Success(c, http.StatusOK, tenant.ToPublicInfo())
 }
@@ -249,5 +371,54 @@ func (h *Handler) GetPublicInfo(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, http.StatusOK, tenant.ToPublicInfo())
+	info := tenant.ToPublicInfo()
+	info.LogoURLs = h.assets.LogoURLs(tenant.LogoURL)
+	if info.Branding.FaviconURL == "" {
+		info.Branding.FaviconURL = info.LogoURLs["favicon"]
+	}
+	response.Success(c, http.StatusOK, info)
+}
+
+// validateBranding checks a branding document from a request and returns it
+// normalized. It writes the error response and reports false when invalid.
+func validateBranding(c *gin.Context, b *Branding) (*Branding, bool) {
+	if b == nil {
+		return nil, true
+	}
+	normalized := b.Normalize()
+	normalized.Version = b.Version
+	if err := normalized.Validate(); err != nil {
+		var invalid *BrandingError
+		if stdErrors.As(err, &invalid) {
+			response.ErrorWithDetails(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, invalid.Error(), invalid.Fields)
+		} else {
+			response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid branding")
+		}
+		return nil, false
+	}
+	normalized.Version = BrandingVersion
+	return &normalized, true
+}
+
+// validateNotifications checks notification settings from a request. It
+// writes the error response and reports false when invalid.
+func validateNotifications(c *gin.Context, s *NotificationSettings) bool {
+	if s == nil {
+		return true
+	}
+	if err := s.Validate(); err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid notification settings: "+err.Error())
+		return false
+	}
+	return true
+}
+
+// validateAttachmentPolicy checks an attachment policy from a request. It
+// writes the error response and reports false when invalid.
+func validateAttachmentPolicy(c *gin.Context, p *attachment.Policy) bool {
+	if err := p.Validate(); err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid attachment policy: "+err.Error())
+		return false
+	}
+	return true
 }
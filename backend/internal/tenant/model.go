@@ -1,6 +1,10 @@
 package tenant
 
-import "time"
+import (
+	"time"
+
+	"werk-ticketing/internal/attachment"
+)
 
 // Tenant represents a tenant/organization in the multi-tenant system.
 // Each tenant has its own InvGate credentials and branding configuration.
@@ -20,10 +24,22 @@ type Tenant struct {
 	// Email Configuration
 	EmailDomain string `gorm:"column:email_domain;size:255" json:"email_domain,omitempty"`
 	EmailSender string `gorm:"column:email_sender;size:255" json:"email_sender,omitempty"`
+	EmailAPIKey string `gorm:"column:email_api_key;size:255" json:"-"` // Never expose in JSON
+
+	// Shared secret InvGate uses to sign webhook calls to this tenant
+	WebhookSecret string `gorm:"column:webhook_secret;size:255" json:"-"` // Never expose in JSON
 
 	// Branding
 	LogoURL      string `gorm:"column:logo_url;size:255" json:"logo_url,omitempty"`
 	PrimaryColor string `gorm:"column:primary_color;size:7;default:#1976D2" json:"primary_color"`
+	// Portal configuration document; nil uses the defaults
+	Branding *Branding `gorm:"column:branding;type:json;serializer:json" json:"branding,omitempty"`
+
+	// Attachment limits; nil uses the defaults
+	AttachmentPolicy *attachment.Policy `gorm:"column:attachment_policy;type:json;serializer:json" json:"attachment_policy,omitempty"`
+
+	// Ticket update notifications; nil uses the defaults
+	Notifications *NotificationSettings `gorm:"column:notification_settings;type:json;serializer:json" json:"notification_settings,omitempty"`
 
 	// Status
 	IsActive  bool      `gorm:"column:is_active;default:true" json:"is_active"`
@@ -48,8 +64,14 @@ type CreateTenantRequest struct {
 	InvGatePassword   string `json:"invgate_password" binding:"required"`
 	EmailDomain       string `json:"email_domain,omitempty"`
 	EmailSender       string `json:"email_sender,omitempty"`
+	EmailAPIKey       string `json:"email_api_key,omitempty"`
+	WebhookSecret     string `json:"webhook_secret,omitempty"`
 	LogoURL           string `json:"logo_url,omitempty"`
 	PrimaryColor      string `json:"primary_color,omitempty"`
+
+	Branding         *Branding             `json:"branding,omitempty"`
+	AttachmentPolicy *attachment.Policy    `json:"attachment_policy,omitempty"`
+	Notifications    *NotificationSettings `json:"notification_settings,omitempty"`
 }
 
 // UpdateTenantRequest is the DTO for updating a tenant
@@ -63,9 +85,41 @@ type UpdateTenantRequest struct {
 	InvGatePassword   string  `json:"invgate_password,omitempty"`
 	EmailDomain       string  `json:"email_domain,omitempty"`
 	EmailSender       string  `json:"email_sender,omitempty"`
+	EmailAPIKey       string  `json:"email_api_key,omitempty"`
+	WebhookSecret     string  `json:"webhook_secret,omitempty"`
 	LogoURL           *string `json:"logo_url,omitempty"`
 	PrimaryColor      string  `json:"primary_color,omitempty"`
 	IsActive          *bool   `json:"is_active,omitempty"`
+
+	// Branding replaces the whole document when provided.
+	Branding         *Branding             `json:"branding,omitempty"`
+	AttachmentPolicy *attachment.Policy    `json:"attachment_policy,omitempty"`
+	Notifications    *NotificationSettings `json:"notification_settings,omitempty"`
+}
+
+// PortalBranding returns the tenant's branding document with defaults applied.
+func (t *Tenant) PortalBranding() Branding {
+	return t.Branding.Normalize()
+}
+
+// NotificationSettings returns the tenant's notification settings with
+// defaults applied.
+func (t *Tenant) NotificationSettings() NotificationSettings {
+	return t.Notifications.Normalize()
+}
+
+// TicketAttachmentPolicy returns the policy applied to ticket and comment attachments.
+func (t *Tenant) TicketAttachmentPolicy() attachment.Policy {
+	return t.AttachmentPolicy.Resolve(attachment.DefaultTicketTypes)
+}
+
+// ImageUploadPolicy returns the policy applied to image uploads. It keeps the
+// tenant's size limit but only ever accepts images.
+func (t *Tenant) ImageUploadPolicy() attachment.Policy {
+	policy := t.AttachmentPolicy.Resolve(attachment.ImageTypes)
+	policy.MaxFiles = 1
+	policy.AllowedTypes = attachment.ImageTypes
+	return policy
 }
 
 // TenantPublicInfo is the public-facing tenant info (for frontend branding)
@@ -75,6 +129,9 @@ type TenantPublicInfo struct {
 	Slug         string `json:"slug"`
 	LogoURL      string `json:"logo_url,omitempty"`
 	PrimaryColor string `json:"primary_color"`
+	// LogoURLs maps each standard size (original, favicon, header, email) to its URL.
+	LogoURLs map[string]string `json:"logo_urls,omitempty"`
+	Branding Branding          `json:"branding"`
 }
 
 // ToPublicInfo converts a Tenant to TenantPublicInfo
@@ -85,5 +142,6 @@ func (t *Tenant) ToPublicInfo() TenantPublicInfo {
 		Slug:         t.Slug,
 		LogoURL:      t.LogoURL,
 		PrimaryColor: t.PrimaryColor,
+		Branding:     t.PortalBranding(),
 	}
 }
@@ -0,0 +1,73 @@
+package tenant
+
+import (
+	"fmt"
+	"time"
+)
+
+// How often requesters are emailed about ticket updates.
+const (
+	DigestImmediate = "immediate" // one email per update
+	DigestHourly    = "hourly"
+	DigestDaily     = "daily"
+	DigestOff       = "off" // no ticket update emails
+)
+
+// How long in-portal notifications are kept.
+const (
+	DefaultNotificationRetentionDays = 90
+	MaxNotificationRetentionDays     = 365
+)
+
+// NotificationSettings configures ticket update notifications for a tenant.
+type NotificationSettings struct {
+	Digest string `json:"digest,omitempty"`
+	// RetentionDays is how long in-portal notifications are kept.
+	RetentionDays int `json:"retention_days,omitempty"`
+}
+
+// Normalize returns the settings with defaults applied. It is safe to call
+// on a nil receiver.
+func (s *NotificationSettings) Normalize() NotificationSettings {
+	var out NotificationSettings
+	if s != nil {
+		out = *s
+	}
+	if out.Digest == "" {
+		out.Digest = DigestImmediate
+	}
+	if out.RetentionDays == 0 {
+		out.RetentionDays = DefaultNotificationRetentionDays
+	}
+	return out
+}
+
+// Validate checks the settings.
+func (s NotificationSettings) Validate() error {
+	switch s.Digest {
+	case "", DigestImmediate, DigestHourly, DigestDaily, DigestOff:
+	default:
+		return fmt.Errorf("digest must be one of %s, %s, %s or %s", DigestImmediate, DigestHourly, DigestDaily, DigestOff)
+	}
+	if s.RetentionDays < 0 || s.RetentionDays > MaxNotificationRetentionDays {
+		return fmt.Errorf("retention_days must be between 1 and %d", MaxNotificationRetentionDays)
+	}
+	return nil
+}
+
+// DigestInterval is how long updates are collected before a digest email
+// is sent; zero when updates are not batched.
+func (s NotificationSettings) DigestInterval() time.Duration {
+	switch s.Digest {
+	case DigestHourly:
+		return time.Hour
+	case DigestDaily:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// Retention is how long in-portal notifications are kept.
+func (s NotificationSettings) Retention() time.Duration {
+	return time.Duration(s.RetentionDays) * 24 * time.Hour
+}
@@ -0,0 +1,108 @@
+package ticket
+
+import (
+	stdErrors "errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"werk-ticketing/internal/invgate"
+)
+
+var (
+	errRangeUnsupported   = stdErrors.New("unsupported range")
+	errRangeUnsatisfiable = stdErrors.New("range not satisfiable")
+)
+
+// parseByteRange parses a single "bytes=" range against a file of the given
+// size and returns the inclusive start and end offsets. Multiple ranges are
+// reported as unsupported so the caller can fall back to the full file.
+func parseByteRange(header string, size int64) (int64, int64, error) {
+	spec, ok := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !ok || strings.Contains(spec, ",") {
+		return 0, 0, errRangeUnsupported
+	}
+
+	startStr, endStr, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0, errRangeUnsupported
+	}
+
+	if startStr == "" {
+		// Suffix range: the last N bytes.
+		n, err := strconv.ParseInt(endStr, 10, 64)
+		if err != nil || n < 0 {
+			return 0, 0, errRangeUnsupported
+		}
+		if n == 0 || size == 0 {
+			return 0, 0, errRangeUnsatisfiable
+		}
+		if n > size {
+			n = size
+		}
+		return size - n, size - 1, nil
+	}
+
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, errRangeUnsupported
+	}
+	if start >= size {
+		return 0, 0, errRangeUnsatisfiable
+	}
+
+	end := size - 1
+	if endStr != "" {
+		end, err = strconv.ParseInt(endStr, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, errRangeUnsupported
+		}
+		if end >= size {
+			end = size - 1
+		}
+	}
+	return start, end, nil
+}
+
+// rangeApplies evaluates If-Range: the range is only served when the
+// validator still matches the current representation.
+func rangeApplies(ifRange string, download *invgate.Download) bool {
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) {
+		return download.ETag != "" && ifRange == download.ETag
+	}
+	return download.LastModified != "" && ifRange == download.LastModified
+}
+
+// notModified evaluates If-None-Match / If-Modified-Since for downloads that
+// InvGate answered in full.
+func notModified(opts invgate.DownloadOptions, download *invgate.Download) bool {
+	if opts.IfNoneMatch != "" {
+		if download.ETag == "" {
+			return false
+		}
+		current := strings.TrimPrefix(download.ETag, "W/")
+		for _, tag := range strings.Split(opts.IfNoneMatch, ",") {
+			tag = strings.TrimSpace(tag)
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+				return true
+			}
+		}
+		return false
+	}
+
+	if opts.IfModifiedSince == "" || download.LastModified == "" {
+		return false
+	}
+	since, err := http.ParseTime(opts.IfModifiedSince)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(download.LastModified)
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
@@ -2,12 +2,17 @@ package ticket
 
 import (
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"werk-ticketing/internal/constants"
 	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/invgate"
 	"werk-ticketing/internal/response"
 )
 
@@ -34,7 +39,14 @@ func (h *Handler) GetAttachment(c *gin.Context) {
 		return
 	}
 
-	data, filename, contentType, err := h.service.GetTicketAttachment(c.Request.Context(), attachmentID)
+	opts := invgate.DownloadOptions{
+		Range:           c.GetHeader("Range"),
+		IfRange:         c.GetHeader("If-Range"),
+		IfNoneMatch:     c.GetHeader("If-None-Match"),
+		IfModifiedSince: c.GetHeader("If-Modified-Since"),
+	}
+
+	download, err := h.service.GetTicketAttachment(c.Request.Context(), attachmentID, opts)
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			response.AppError(c, appErr)
@@ -43,13 +55,79 @@ func (h *Handler) GetAttachment(c *gin.Context) {
 		}
 		return
 	}
+	defer download.Body.Close()
+
+	header := c.Writer.Header()
+	if download.ETag != "" {
+		header.Set("ETag", download.ETag)
+	}
+	if download.LastModified != "" {
+		header.Set("Last-Modified", download.LastModified)
+	}
 
-	if contentType == "" {
-		contentType = "application/octet-stream"
+	if download.StatusCode == http.StatusNotModified || notModified(opts, download) {
+		c.Status(http.StatusNotModified)
+		return
 	}
 
-	if filename != "" {
-		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	if download.Filename != "" {
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": download.Filename}))
+	}
+
+	status := http.StatusOK
+	length := download.ContentLength
+	var body io.Reader = download.Body
+
+	switch {
+	case download.StatusCode == http.StatusPartialContent:
+		// InvGate honored the Range header itself.
+		status = http.StatusPartialContent
+		header.Set("Content-Range", download.ContentRange)
+		header.Set("Accept-Ranges", "bytes")
+	case length >= 0:
+		header.Set("Accept-Ranges", "bytes")
+		if opts.Range == "" || !rangeApplies(opts.IfRange, download) {
+			break
+		}
+
+		// InvGate ignored the Range header: skip ahead in the stream.
+		start, end, rangeErr := parseByteRange(opts.Range, length)
+		if rangeErr == errRangeUnsatisfiable {
+			header.Set("Content-Range", fmt.Sprintf("bytes */%d", length))
+			response.ErrorWithCode(c, http.StatusRequestedRangeNotSatisfiable, errors.ErrCodeInvalidInput, "requested range not satisfiable")
+			return
+		}
+		if rangeErr != nil {
+			break // Unsupported (e.g. multipart) ranges get the full file.
+		}
+
+		if _, err := io.CopyN(io.Discard, download.Body, start); err != nil {
+			response.ErrorWithCode(c, http.StatusBadGateway, errors.ErrCodeExternalService, "failed to read attachment")
+			return
+		}
+		status = http.StatusPartialContent
+		body = io.LimitReader(download.Body, end-start+1)
+		header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, length))
+		length = end - start + 1
 	}
-	c.Data(http.StatusOK, contentType, data)
+
+	c.DataFromReader(status, length, download.ContentType, &deadlineReader{
+		Reader:     body,
+		controller: http.NewResponseController(c.Writer),
+		idle:       time.Duration(constants.DownloadIdleTimeoutSeconds) * time.Second,
+	}, nil)
+}
+
+// deadlineReader pushes the server write deadline forward while data keeps
+// flowing, so large attachments are not cut off by the server WriteTimeout.
+type deadlineReader struct {
+	io.Reader
+	controller *http.ResponseController
+	idle       time.Duration
+}
+
+func (r *deadlineReader) Read(p []byte) (int, error) {
+	// Not every ResponseWriter supports deadlines (e.g. in tests); ignore the error.
+	_ = r.controller.SetWriteDeadline(time.Now().Add(r.idle))
+	return r.Reader.Read(p)
 }
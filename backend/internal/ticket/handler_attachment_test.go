@@ -0,0 +1,97 @@
+package ticket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/invgate/invgatetest"
+)
+
+func serveAttachment(t *testing.T, f *ticketFixture, attachmentID int, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/tickets/attachments/:attachment_id", NewHandler(f.service).GetAttachment)
+
+	req := httptest.NewRequest(http.MethodGet, "/tickets/attachments/"+strconv.Itoa(attachmentID), nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGetAttachmentStreamsFullFile(t *testing.T) {
+	f := newTicketFixture(t)
+	id := f.fake.AddAttachment(invgatetest.Attachment{Name: "report.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.7 body")})
+
+	rec := serveAttachment(t, f, id, nil)
+	if rec.Code != http.StatusOK || rec.Body.String() != "%PDF-1.7 body" {
+		t.Fatalf("response = %d %q", rec.Code, rec.Body.String())
+	}
+	for key, want := range map[string]string{
+		"Content-Type":        "application/pdf",
+		"Content-Length":      "13",
+		"Content-Disposition": `attachment; filename=report.pdf`,
+		"Accept-Ranges":       "bytes",
+	} {
+		if got := rec.Header().Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if rec.Header().Get("ETag") == "" || rec.Header().Get("Last-Modified") == "" {
+		t.Errorf("validators missing: %v", rec.Header())
+	}
+}
+
+func TestGetAttachmentRangeFallback(t *testing.T) {
+	f := newTicketFixture(t)
+	f.fake.DisableRanges()
+	id := f.fake.AddAttachment(invgatetest.Attachment{Name: "log.txt", Data: []byte("0123456789")})
+
+	rec := serveAttachment(t, f, id, http.Header{"Range": {"bytes=2-5"}})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "2345" {
+		t.Fatalf("range response = %d %q", rec.Code, rec.Body.String())
+	}
+	if got := rec.Header().Get("Content-Range"); got != "bytes 2-5/10" {
+		t.Errorf("Content-Range = %q", got)
+	}
+
+	rec = serveAttachment(t, f, id, http.Header{"Range": {"bytes=-3"}})
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "789" {
+		t.Errorf("suffix range response = %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = serveAttachment(t, f, id, http.Header{"Range": {"bytes=20-"}})
+	if rec.Code != http.StatusRequestedRangeNotSatisfiable || rec.Header().Get("Content-Range") != "bytes */10" {
+		t.Errorf("unsatisfiable range response = %d %q", rec.Code, rec.Header().Get("Content-Range"))
+	}
+
+	rec = serveAttachment(t, f, id, http.Header{"Range": {"bytes=0-1"}, "If-Range": {`"stale"`}})
+	if rec.Code != http.StatusOK || rec.Body.String() != "0123456789" {
+		t.Errorf("stale If-Range response = %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetAttachmentNotModified(t *testing.T) {
+	f := newTicketFixture(t)
+	id := f.fake.AddAttachment(invgatetest.Attachment{Name: "log.txt", Data: []byte("cached")})
+
+	etag := serveAttachment(t, f, id, nil).Header().Get("ETag")
+	rec := serveAttachment(t, f, id, http.Header{"If-None-Match": {etag}})
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Errorf("conditional response = %d %q", rec.Code, rec.Body.String())
+	}
+
+	f.fake.DisableRanges()
+	rec = serveAttachment(t, f, id, http.Header{"If-None-Match": {etag}})
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("conditional response without upstream support = %d", rec.Code)
+	}
+}
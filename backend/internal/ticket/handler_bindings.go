@@ -9,12 +9,13 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/constants"
 )
 
 // bindTicketMultipart parses multipart form-data into TicketRequest.
 func bindTicketMultipart(c *gin.Context) (TicketRequest, error) {
-	const maxMemory = 32 << 20 // 32MB
-	if err := c.Request.ParseMultipartForm(maxMemory); err != nil {
+	if err := c.Request.ParseMultipartForm(constants.MultipartMemoryLimit); err != nil {
 		return TicketRequest{}, fmt.Errorf("invalid multipart form: %w", err)
 	}
 
@@ -90,8 +91,7 @@ func bindTicketMultipart(c *gin.Context) (TicketRequest, error) {
 
 // bindCommentMultipart parses multipart form-data for ticket comments.
 func bindCommentMultipart(c *gin.Context) (TicketCommentRequest, error) {
-	const maxMemory = 32 << 20 // 32MB
-	if err := c.Request.ParseMultipartForm(maxMemory); err != nil {
+	if err := c.Request.ParseMultipartForm(constants.MultipartMemoryLimit); err != nil {
 		return TicketCommentRequest{}, fmt.Errorf("invalid multipart form: %w", err)
 	}
 
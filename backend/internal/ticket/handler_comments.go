@@ -39,7 +39,10 @@ func (h *Handler) AddComment(c *gin.Context) {
 	if strings.Contains(c.GetHeader("Content-Type"), "multipart/form-data") {
 		req, err = bindCommentMultipart(c)
 		if err != nil {
-			response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, err.Error())
+			multipartError(c, err)
+			return
+		}
+		if !checkAttachments(c, req.AttachmentFiles) {
 			return
 		}
 	} else {
@@ -120,6 +120,11 @@ func (h *Handler) GetMeta(c *gin.Context) {
 
 // GetArticlesByCategory handles GET /api/articles
 func (h *Handler) GetArticlesByCategory(c *gin.Context) {
+	if t := middleware.GetTenant(c); t != nil && !*t.PortalBranding().Features.KnowledgeBase {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "knowledge base is disabled for this portal")
+		return
+	}
+
 	categoryIDParam := c.Query("category_id")
 	if categoryIDParam == "" {
 		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "category_id query parameter is required")
@@ -201,7 +206,7 @@ func (h *Handler) Update(c *gin.Context) {
 		}
 	}
 
-	resp, err := h.service.UpdateTicket(c.Request.Context(), ticketID, req)
+	resp, err := h.service.UpdateTicket(c.Request.Context(), middleware.GetTenantID(c), ticketID, req, middleware.GetUserEmail(c))
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			response.AppError(c, appErr)
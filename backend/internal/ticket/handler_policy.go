@@ -0,0 +1,58 @@
+package ticket
+
+import (
+	stdErrors "errors"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"werk-ticketing/internal/attachment"
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/response"
+)
+
+// multipartError writes the response for a multipart body that could not be
+// parsed, telling a body over the upload limit apart from a malformed one.
+func multipartError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if stdErrors.As(err, &tooLarge) {
+		response.ErrorWithCode(c, http.StatusRequestEntityTooLarge, errors.ErrCodeAttachmentRejected, "request body too large")
+		return
+	}
+	response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, err.Error())
+}
+
+// checkAttachments applies the tenant's attachment policy and writes the
+// error response when a file is rejected. It reports whether the request
+// may proceed.
+func checkAttachments(c *gin.Context, files []*multipart.FileHeader) bool {
+	if len(files) == 0 {
+		return true
+	}
+
+	var policy attachment.Policy
+	if t := middleware.GetTenant(c); t != nil {
+		if !*t.PortalBranding().Features.Attachments {
+			response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeAttachmentRejected, "attachments are disabled for this portal")
+			return false
+		}
+		policy = t.TicketAttachmentPolicy()
+	} else {
+		policy = (*attachment.Policy)(nil).Resolve(attachment.DefaultTicketTypes)
+	}
+
+	err := policy.Check(files)
+	if err == nil {
+		return true
+	}
+
+	var rejected *attachment.RejectedError
+	if stdErrors.As(err, &rejected) {
+		response.ErrorWithDetails(c, http.StatusBadRequest, errors.ErrCodeAttachmentRejected, rejected.Error(), rejected.Violations)
+	} else {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "failed to read attachment")
+	}
+	return false
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/middleware"
 	"werk-ticketing/internal/response"
 )
 
@@ -35,13 +36,18 @@ func (h *Handler) AcceptSolution(c *gin.Context) {
 		return
 	}
 
-	if body.Rating < 1 || body.Rating > 5 {
+	// Tenants may make the rating optional; an omitted rating is sent as none.
+	ratingRequired := true
+	if t := middleware.GetTenant(c); t != nil {
+		ratingRequired = *t.PortalBranding().Features.RatingRequired
+	}
+	if (body.Rating != 0 || ratingRequired) && (body.Rating < 1 || body.Rating > 5) {
 		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "rating must be between 1 and 5")
 		return
 	}
 
 	// Comment is required only if rating is less than 4
-	if body.Rating < 4 && strings.TrimSpace(body.Comment) == "" {
+	if body.Rating != 0 && body.Rating < 4 && strings.TrimSpace(body.Comment) == "" {
 		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "comment is required when rating is less than 4")
 		return
 	}
@@ -52,7 +58,7 @@ func (h *Handler) AcceptSolution(c *gin.Context) {
 		Comment:   body.Comment,
 	}
 
-	resp, err := h.service.UpdateTicketSolution(c.Request.Context(), req)
+	resp, err := h.service.UpdateTicketSolution(c.Request.Context(), middleware.GetTenantID(c), req, middleware.GetUserEmail(c))
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			response.AppError(c, appErr)
@@ -98,7 +104,7 @@ func (h *Handler) RejectSolution(c *gin.Context) {
 		Comment:   body.Comment,
 	}
 
-	resp, err := h.service.RejectTicketSolution(c.Request.Context(), req)
+	resp, err := h.service.RejectTicketSolution(c.Request.Context(), middleware.GetTenantID(c), req, middleware.GetUserEmail(c))
 	if err != nil {
 		if appErr, ok := err.(*errors.AppError); ok {
 			response.AppError(c, appErr)
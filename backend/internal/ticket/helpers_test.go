@@ -0,0 +1,90 @@
+package ticket
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/audit/audittest"
+	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/invgate/invgatetest"
+	"werk-ticketing/internal/scanner"
+	"werk-ticketing/internal/user"
+	"werk-ticketing/internal/user/usertest"
+	"werk-ticketing/internal/webhook"
+)
+
+const testTenantID = "tenant-1"
+
+// stubScanner reports files containing marker as infected.
+type stubScanner struct {
+	marker string
+}
+
+func (s *stubScanner) Scan(ctx context.Context, r io.Reader) (scanner.Result, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return scanner.Result{}, err
+	}
+	if s.marker != "" && strings.Contains(string(data), s.marker) {
+		return scanner.Result{Infected: true, Signature: "Test-Signature"}, nil
+	}
+	return scanner.Result{}, nil
+}
+
+// recordingPublisher records published webhook events.
+type recordingPublisher struct {
+	events []string
+	data   []webhook.EventData
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, tenantID, event string, data webhook.EventData) error {
+	p.events = append(p.events, event)
+	p.data = append(p.data, data)
+	return nil
+}
+
+// ticketFixture is a ticket service talking to a fake InvGate, with two
+// portal users, alice and bob, linked to InvGate users of their own.
+type ticketFixture struct {
+	service  Service
+	fake     *invgatetest.Server
+	scanner  *stubScanner
+	webhooks *recordingPublisher
+	audit    *audittest.Recorder
+	alice    int
+	bob      int
+}
+
+func newTicketFixture(t *testing.T) *ticketFixture {
+	t.Helper()
+
+	fake := invgatetest.NewServer()
+	t.Cleanup(fake.Close)
+
+	alice := fake.AddUser(invgatetest.User{Email: "alice@example.com"})
+	bob := fake.AddUser(invgatetest.User{Email: "bob@example.com"})
+	users := usertest.NewRepository(
+		&user.User{ID: "u-alice", TenantID: testTenantID, Email: "alice@example.com", InvGateUserID: alice},
+		&user.User{ID: "u-bob", TenantID: testTenantID, Email: "bob@example.com", InvGateUserID: bob},
+	)
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	files := &stubScanner{}
+	webhooks := &recordingPublisher{}
+	auditLog := &audittest.Recorder{}
+	return &ticketFixture{
+		service:  NewService(invgate.NewService(fake.Config()), users, files, webhooks, auditLog, logger),
+		fake:     fake,
+		scanner:  files,
+		webhooks: webhooks,
+		audit:    auditLog,
+		alice:    alice,
+		bob:      bob,
+	}
+}
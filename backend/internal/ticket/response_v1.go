@@ -0,0 +1,256 @@
+package ticket
+
+import (
+	"fmt"
+
+	"werk-ticketing/internal/invgate"
+)
+
+// The types in this file are the public, versioned JSON contract of the
+// ticket API (v1). They are decoupled from the InvGate payloads so that
+// changes on the InvGate side do not leak to API consumers. Within v1,
+// fields may be added but must never be renamed, retyped or removed.
+
+// TicketV1 is a ticket as exposed by the API.
+type TicketV1 struct {
+	ID              int    `json:"id"`
+	PrettyID        string `json:"pretty_id"`
+	InvGateID       string `json:"inv_gate_id"`
+	WrkTicketID     string `json:"wrk_ticket_id"`
+	Title           string `json:"title"`
+	Description     string `json:"description"`
+	Status          string `json:"status"`
+	StatusID        int    `json:"status_id"`
+	PriorityID      int    `json:"priority_id"`
+	CategoryID      int    `json:"category_id"`
+	TypeID          int    `json:"type_id"`
+	SourceID        int    `json:"source_id"`
+	UserID          int    `json:"user_id"`
+	CreatorID       int    `json:"creator_id"`
+	AssignedID      int    `json:"assigned_id"`
+	AssignedGroupID int    `json:"assigned_group_id"`
+	LocationID      int    `json:"location_id"`
+	Rating          int    `json:"rating"`
+	CreatedAt       int64  `json:"created_at"`
+	LastUpdate      int64  `json:"last_update"`
+	DateOcurred     int64  `json:"date_ocurred"`
+	ClosedAt        *int64 `json:"closed_at"`
+	SolvedAt        *int64 `json:"solved_at"`
+	Attachments     []int  `json:"attachments"`
+	Collaborators   []int  `json:"collaborators"`
+}
+
+// PaginationV1 describes the page returned by a list endpoint.
+type PaginationV1 struct {
+	Page       int  `json:"page"`
+	Limit      int  `json:"limit"`
+	Total      int  `json:"total"`
+	TotalPages int  `json:"total_pages"`
+	HasNext    bool `json:"has_next"`
+	HasPrev    bool `json:"has_prev"`
+}
+
+// TicketListV1 is a paginated list of tickets.
+type TicketListV1 struct {
+	Data       []TicketV1   `json:"data"`
+	Pagination PaginationV1 `json:"pagination"`
+}
+
+// AttachmentV1 is a file attached to a ticket, comment or article.
+type AttachmentV1 struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	URL       string `json:"url,omitempty"`
+	Hash      string `json:"hash,omitempty"`
+	Extension string `json:"extension,omitempty"`
+}
+
+// CommentV1 is a message on a ticket.
+type CommentV1 struct {
+	ID              int            `json:"id"`
+	IncidentID      int            `json:"incident_id"`
+	AuthorID        int            `json:"author_id"`
+	Comment         string         `json:"comment"`
+	CreatedAt       int64          `json:"created_at"`
+	CustomerVisible bool           `json:"customer_visible"`
+	IsSolution      bool           `json:"is_solution"`
+	MsgNum          int            `json:"msg_num"`
+	Reference       string         `json:"reference,omitempty"`
+	Attachments     []AttachmentV1 `json:"attachments"`
+}
+
+// CommentListV1 wraps the comments of a ticket.
+type CommentListV1 struct {
+	Data []CommentV1 `json:"data"`
+}
+
+// CategoryV1 is a ticket category.
+type CategoryV1 struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// CategoryListV1 wraps the categories available to portal users.
+type CategoryListV1 struct {
+	Data []CategoryV1 `json:"data"`
+}
+
+// ArticleV1 is a knowledge base article.
+type ArticleV1 struct {
+	ID             int            `json:"id"`
+	Title          string         `json:"title"`
+	Content        string         `json:"content"`
+	CategoryID     int            `json:"category_id"`
+	AuthorID       int            `json:"author_id"`
+	ResponsibleID  int            `json:"responsible_id"`
+	CreationDate   int64          `json:"creation_date"`
+	LastUpdateDate int64          `json:"last_update_date"`
+	SolvedRequests int            `json:"solved_requests"`
+	Views          int            `json:"views"`
+	IsPrivate      bool           `json:"is_private"`
+	Rating         float64        `json:"rating"`
+	Attachments    []AttachmentV1 `json:"attachments"`
+}
+
+// ArticleListV1 wraps the articles of a category.
+type ArticleListV1 struct {
+	Data []ArticleV1 `json:"data"`
+}
+
+// UserV1 is an InvGate user as exposed by the API.
+type UserV1 struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	LastName string `json:"lastname"`
+	Email    string `json:"email"`
+	Type     int    `json:"type"`
+}
+
+// ActionResultV1 is returned by write operations (create, update, comment,
+// accept/reject solution).
+type ActionResultV1 struct {
+	ID      int    `json:"id,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func newTicketV1(incident invgate.Incident) TicketV1 {
+	status := incident.StatusLabel
+	if status == "" {
+		status = getStatusName(incident.StatusID)
+	}
+
+	prettyID := incident.PrettyID
+	if prettyID == "" {
+		prettyID = fmt.Sprintf("ARM-#%d", incident.ID)
+	}
+
+	attachments := incident.AttachmentIDs
+	if attachments == nil {
+		attachments = []int{}
+	}
+	collaborators := incident.Collaborators
+	if collaborators == nil {
+		collaborators = []int{}
+	}
+
+	return TicketV1{
+		ID:              incident.ID,
+		PrettyID:        prettyID,
+		InvGateID:       fmt.Sprintf("%d", incident.ID),
+		WrkTicketID:     fmt.Sprintf("WRK-#%d", incident.ID),
+		Title:           incident.Title,
+		Description:     incident.Description,
+		Status:          status,
+		StatusID:        incident.StatusID,
+		PriorityID:      incident.PriorityID,
+		CategoryID:      incident.CategoryID,
+		TypeID:          incident.TypeID,
+		SourceID:        incident.SourceID,
+		UserID:          incident.CustomerID,
+		CreatorID:       incident.CreatorID,
+		AssignedID:      incident.AgentID,
+		AssignedGroupID: incident.AssignedGroupID,
+		LocationID:      incident.LocationID,
+		Rating:          incident.Rating,
+		CreatedAt:       incident.CreatedAt,
+		LastUpdate:      incident.LastUpdate,
+		DateOcurred:     incident.DateOcurred,
+		ClosedAt:        incident.ClosedAt,
+		SolvedAt:        incident.SolvedAt,
+		Attachments:     attachments,
+		Collaborators:   collaborators,
+	}
+}
+
+func newAttachmentV1(attachment invgate.Attachment) AttachmentV1 {
+	return AttachmentV1{
+		ID:        attachment.ID,
+		Name:      attachment.Name,
+		URL:       attachment.URL,
+		Hash:      attachment.Hash,
+		Extension: attachment.Extension,
+	}
+}
+
+func newAttachmentListV1(attachments []invgate.Attachment) []AttachmentV1 {
+	out := make([]AttachmentV1, 0, len(attachments))
+	for _, attachment := range attachments {
+		out = append(out, newAttachmentV1(attachment))
+	}
+	return out
+}
+
+func newCommentV1(comment invgate.Comment) CommentV1 {
+	return CommentV1{
+		ID:              comment.ID,
+		IncidentID:      comment.IncidentID,
+		AuthorID:        comment.AuthorID,
+		Comment:         comment.Comment,
+		CreatedAt:       comment.CreatedAt,
+		CustomerVisible: comment.CustomerVisible != nil && *comment.CustomerVisible,
+		IsSolution:      comment.IsSolution,
+		MsgNum:          comment.MsgNum,
+		Reference:       comment.Reference,
+		Attachments:     newAttachmentListV1(comment.Attachments),
+	}
+}
+
+func newArticleV1(article invgate.Article) ArticleV1 {
+	return ArticleV1{
+		ID:             article.ID,
+		Title:          article.Title,
+		Content:        article.Content,
+		CategoryID:     article.CategoryID,
+		AuthorID:       article.AuthorID,
+		ResponsibleID:  article.ResponsibleID,
+		CreationDate:   article.CreatedAt,
+		LastUpdateDate: article.UpdatedAt,
+		SolvedRequests: article.SolvedRequests,
+		Views:          article.Views,
+		IsPrivate:      article.IsPrivate,
+		Rating:         article.Rating,
+		Attachments:    newAttachmentListV1(article.Attachments),
+	}
+}
+
+func newUserV1(user invgate.User) UserV1 {
+	return UserV1{
+		ID:       user.ID,
+		Name:     user.Name,
+		LastName: user.LastName,
+		Email:    user.Email,
+		Type:     user.Type,
+	}
+}
+
+func newActionResultV1(result *invgate.Result) ActionResultV1 {
+	if result == nil {
+		return ActionResultV1{}
+	}
+	return ActionResultV1{
+		ID:      result.ID,
+		Status:  result.Status,
+		Message: result.Info,
+	}
+}
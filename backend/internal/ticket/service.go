@@ -2,44 +2,92 @@ package ticket
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/sirupsen/logrus"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/logging"
+	"werk-ticketing/internal/scanner"
 	"werk-ticketing/internal/user"
+	"werk-ticketing/internal/webhook"
 )
 
 // Service handles ticket business logic.
 // Methods that require user lookup now need tenantID for multi-tenant support.
 type Service interface {
-	CreateTicket(ctx context.Context, tenantID string, req TicketRequest, creatorEmail string) (map[string]interface{}, error)
-	GetTickets(ctx context.Context, tenantID, creatorID string, page, limit int) (map[string]interface{}, error)
-	GetTicketDetail(ctx context.Context, ticketID string) (map[string]interface{}, error)
-	GetCategories(ctx context.Context) (map[string]interface{}, error)
+	CreateTicket(ctx context.Context, tenantID string, req TicketRequest, creatorEmail string) (*ActionResultV1, error)
+	GetTickets(ctx context.Context, tenantID, creatorID string, page, limit int) (*TicketListV1, error)
+	GetTicketDetail(ctx context.Context, ticketID string) (*TicketV1, error)
+	GetCategories(ctx context.Context) (*CategoryListV1, error)
 	GetTicketMeta(ctx context.Context) (map[string]interface{}, error)
 	GetStatuses(ctx context.Context) (map[string]interface{}, error)
-	AddTicketComment(ctx context.Context, tenantID string, req TicketCommentRequest, authorEmail string) (map[string]interface{}, error)
-	GetTicketComments(ctx context.Context, ticketID int) (map[string]interface{}, error)
-	GetTicketAttachment(ctx context.Context, attachmentID string) ([]byte, string, string, error)
-	GetTicketAttachmentInfo(ctx context.Context, attachmentID string) (map[string]interface{}, error)
-	UpdateTicketSolution(ctx context.Context, req TicketSolutionRequest) (map[string]interface{}, error)
-	RejectTicketSolution(ctx context.Context, req TicketSolutionRejectRequest) (map[string]interface{}, error)
-	UpdateTicket(ctx context.Context, ticketID int, req TicketUpdateRequest) (map[string]interface{}, error)
-	GetInvGateUser(ctx context.Context, userID int) (map[string]interface{}, error)
-	GetArticlesByCategory(ctx context.Context, categoryID int) (map[string]interface{}, error)
+	AddTicketComment(ctx context.Context, tenantID string, req TicketCommentRequest, authorEmail string) (*ActionResultV1, error)
+	GetTicketComments(ctx context.Context, ticketID int) (*CommentListV1, error)
+	GetTicketAttachment(ctx context.Context, attachmentID string, opts invgate.DownloadOptions) (*invgate.Download, error)
+	GetTicketAttachmentInfo(ctx context.Context, attachmentID string) (*AttachmentV1, error)
+	UpdateTicketSolution(ctx context.Context, tenantID string, req TicketSolutionRequest, userEmail string) (*ActionResultV1, error)
+	RejectTicketSolution(ctx context.Context, tenantID string, req TicketSolutionRejectRequest, userEmail string) (*ActionResultV1, error)
+	UpdateTicket(ctx context.Context, tenantID string, ticketID int, req TicketUpdateRequest, userEmail string) (*ActionResultV1, error)
+	GetInvGateUser(ctx context.Context, userID int) (*UserV1, error)
+	GetArticlesByCategory(ctx context.Context, categoryID int) (*ArticleListV1, error)
 }
 
 type service struct {
 	client   invgate.Service
 	userRepo user.Repository
+	scanner  scanner.Scanner
+	webhooks webhook.Publisher
+	audit    audit.Recorder
 	logger   *logrus.Logger
 }
 
-// NewService creates a new ticket service.
-func NewService(client invgate.Service, userRepo user.Repository, logger *logrus.Logger) Service {
+// NewService creates a new ticket service. webhooks and auditLog may be nil
+// when tenant webhooks or the audit log are not used.
+func NewService(client invgate.Service, userRepo user.Repository, scanner scanner.Scanner, webhooks webhook.Publisher, auditLog audit.Recorder, logger *logrus.Logger) Service {
 	return &service{
 		client:   client,
 		userRepo: userRepo,
+		scanner:  scanner,
+		webhooks: webhooks,
+		audit:    auditLog,
 		logger:   logger,
 	}
 }
+
+// record appends a ticket change to the audit log.
+func (s *service) record(ctx context.Context, tenantID, action string, ticketID int, actorEmail string, changes audit.Changes) {
+	if s.audit == nil {
+		return
+	}
+	s.audit.Record(ctx, &audit.Event{
+		TenantID:   tenantID,
+		ActorEmail: actorEmail,
+		Action:     action,
+		TargetType: audit.TargetTicket,
+		TargetID:   strconv.Itoa(ticketID),
+		Changes:    changes,
+	})
+}
+
+// log returns the request's log entry, which carries the request ID and
+// the tenant and user fields added by the middleware.
+func (s *service) log(ctx context.Context) *logrus.Entry {
+	return logging.FromContext(ctx, s.logger)
+}
+
+// publish queues a webhook event for the tenant's subscriptions. The ticket
+// change already happened, so a failure is only logged.
+func (s *service) publish(ctx context.Context, tenantID, event string, data webhook.EventData) {
+	if s.webhooks == nil || tenantID == "" {
+		return
+	}
+	if err := s.webhooks.Publish(ctx, tenantID, event, data); err != nil {
+		s.log(ctx).WithError(err).WithFields(logrus.Fields{
+			"tenantID": tenantID,
+			"event":    event,
+			"ticketID": data.TicketID,
+		}).Error("failed to queue webhook event")
+	}
+}
@@ -5,13 +5,16 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/webhook"
 )
 
-func (s *service) AddTicketComment(ctx context.Context, tenantID string, req TicketCommentRequest, authorEmail string) (map[string]interface{}, error) {
+func (s *service) AddTicketComment(ctx context.Context, tenantID string, req TicketCommentRequest, authorEmail string) (*ActionResultV1, error) {
 	user, err := s.userRepo.GetByEmail(ctx, tenantID, authorEmail)
 	if err != nil {
-		s.logger.WithError(err).WithField("authorEmail", authorEmail).Error("failed to get user by email")
+		s.log(ctx).WithError(err).WithField("authorEmail", authorEmail).Error("failed to get user by email")
 		return nil, errors.NewAppError(
 			errors.ErrCodeInternal,
 			"failed to retrieve user information",
@@ -28,31 +31,42 @@ func (s *service) AddTicketComment(ctx context.Context, tenantID string, req Tic
 
 	authorID := user.InvGateUserID
 
+	if err := s.scanAttachments(ctx, tenantID, authorEmail, req.AttachmentFiles); err != nil {
+		return nil, err
+	}
+
 	resp, err := s.client.AddTicketComment(ctx, req.RequestID, authorID, req.Comment, req.AttachmentFiles)
 	if err != nil {
-		s.logger.WithError(err).WithFields(logrus.Fields{
-			"requestID": req.RequestID,
-			"authorID":  authorID,
+		s.log(ctx).WithError(err).WithFields(logrus.Fields{
+			"ticketID": req.RequestID,
+			"authorID": authorID,
 		}).Error("failed to add comment to InvGate ticket")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to add comment to ticket",
-			err,
-		)
+		return nil, invgate.AsAppError(err, "failed to add comment to ticket")
 	}
 
-	return resp, nil
+	s.record(ctx, tenantID, audit.ActionTicketCommented, req.RequestID, authorEmail, audit.Changes{
+		"comment": {After: req.Comment},
+	})
+	s.publish(ctx, tenantID, webhook.EventCommentAdded, webhook.EventData{
+		TicketID:   req.RequestID,
+		ActorEmail: authorEmail,
+		Comment:    req.Comment,
+	})
+
+	result := newActionResultV1(resp)
+	return &result, nil
 }
 
-func (s *service) GetTicketComments(ctx context.Context, ticketID int) (map[string]interface{}, error) {
+func (s *service) GetTicketComments(ctx context.Context, ticketID int) (*CommentListV1, error) {
 	resp, err := s.client.GetTicketComments(ctx, ticketID)
 	if err != nil {
-		s.logger.WithError(err).WithField("requestID", ticketID).Error("failed to get ticket comments from InvGate")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to fetch ticket comments",
-			err,
-		)
+		s.log(ctx).WithError(err).WithField("ticketID", ticketID).Error("failed to get ticket comments from InvGate")
+		return nil, invgate.AsAppError(err, "failed to fetch ticket comments")
+	}
+
+	comments := make([]CommentV1, 0, len(resp))
+	for _, comment := range resp {
+		comments = append(comments, newCommentV1(comment))
 	}
-	return resp, nil
+	return &CommentListV1{Data: comments}, nil
 }
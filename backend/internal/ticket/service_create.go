@@ -2,18 +2,19 @@ package ticket
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/sirupsen/logrus"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/errors"
 	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/webhook"
 )
 
-func (s *service) CreateTicket(ctx context.Context, tenantID string, req TicketRequest, creatorEmail string) (map[string]interface{}, error) {
+func (s *service) CreateTicket(ctx context.Context, tenantID string, req TicketRequest, creatorEmail string) (*ActionResultV1, error) {
 	user, err := s.userRepo.GetByEmail(ctx, tenantID, creatorEmail)
 	if err != nil {
-		s.logger.WithError(err).WithField("creatorEmail", creatorEmail).Error("failed to get user by email")
+		s.log(ctx).WithError(err).WithField("creatorEmail", creatorEmail).Error("failed to get user by email")
 		return nil, errors.NewAppError(
 			errors.ErrCodeInternal,
 			"failed to retrieve user information",
@@ -30,7 +31,7 @@ func (s *service) CreateTicket(ctx context.Context, tenantID string, req TicketR
 
 	invgateUserID := user.InvGateUserID
 	if invgateUserID == 0 {
-		s.logger.WithField("creatorEmail", creatorEmail).Error("user has no invgate_user_id")
+		s.log(ctx).WithField("creatorEmail", creatorEmail).Error("user has no invgate_user_id")
 		return nil, errors.NewAppError(
 			errors.ErrCodeInternal,
 			"user is not synchronized with InvGate. Please contact administrator.",
@@ -38,6 +39,10 @@ func (s *service) CreateTicket(ctx context.Context, tenantID string, req TicketR
 		)
 	}
 
+	if err := s.scanAttachments(ctx, tenantID, creatorEmail, req.AttachmentFiles); err != nil {
+		return nil, err
+	}
+
 	payload := invgate.CreateTicketPayload{
 		SourceID:    req.SourceID,
 		CreatorID:   invgateUserID,
@@ -50,7 +55,7 @@ func (s *service) CreateTicket(ctx context.Context, tenantID string, req TicketR
 		DateOcurred: req.DateOcurred,
 	}
 
-	var invgateResp map[string]interface{}
+	var invgateResp *invgate.Result
 	if len(req.AttachmentFiles) > 0 {
 		invgateResp, err = s.client.CreateTicketWithAttachments(ctx, payload, req.AttachmentFiles)
 	} else {
@@ -58,7 +63,7 @@ func (s *service) CreateTicket(ctx context.Context, tenantID string, req TicketR
 	}
 
 	if err != nil {
-		s.logger.WithError(err).
+		s.log(ctx).WithError(err).
 			WithFields(logrus.Fields{
 				"creator_id":      payload.CreatorID,
 				"customer_id":     payload.CustomerID,
@@ -70,30 +75,35 @@ func (s *service) CreateTicket(ctx context.Context, tenantID string, req TicketR
 			}).
 			Error("failed to create ticket in InvGate")
 
-		errorMsg := "failed to create ticket in external service"
-		if err.Error() != "" {
-			errorMsg = fmt.Sprintf("InvGate API error: %s", err.Error())
-		}
-
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			errorMsg,
-			err,
-		)
+		return nil, invgate.AsAppError(err, "failed to create ticket in external service")
 	}
 
-	// Extract InvGate ID for logging
-	invGateID, err := extractInvGateID(invgateResp)
-	if err != nil {
-		s.logger.WithError(err).WithField("response", invgateResp).Warn("failed to extract InvGate ID from response")
-		invGateID = ""
+	if invgateResp.ID == 0 {
+		s.log(ctx).WithField("status", invgateResp.Status).Warn("InvGate response did not include a ticket ID")
 	}
 
-	s.logger.WithFields(logrus.Fields{
-		"invGateID":    invGateID,
+	s.log(ctx).WithFields(logrus.Fields{
+		"invGateID":    invgateResp.ID,
 		"title":        req.Title,
 		"creatorEmail": creatorEmail,
 	}).Info("ticket created successfully in InvGate")
 
-	return invgateResp, nil
+	if invgateResp.ID != 0 {
+		s.record(ctx, tenantID, audit.ActionTicketCreated, invgateResp.ID, creatorEmail, audit.Changes{
+			"title":       {After: req.Title},
+			"category_id": {After: req.CategoryID},
+			"type_id":     {After: req.TypeID},
+			"priority_id": {After: req.PriorityID},
+		})
+		s.publish(ctx, tenantID, webhook.EventTicketCreated, webhook.EventData{
+			TicketID:   invgateResp.ID,
+			Title:      req.Title,
+			CategoryID: req.CategoryID,
+			PriorityID: req.PriorityID,
+			ActorEmail: creatorEmail,
+		})
+	}
+
+	result := newActionResultV1(invgateResp)
+	return &result, nil
 }
@@ -4,10 +4,12 @@ import (
 	"context"
 	"sort"
 
+	"werk-ticketing/internal/constants"
 	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/invgate"
 )
 
-func (s *service) GetTickets(ctx context.Context, tenantID, creatorID string, page, limit int) (map[string]interface{}, error) {
+func (s *service) GetTickets(ctx context.Context, tenantID, creatorID string, page, limit int) (*TicketListV1, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -21,7 +23,7 @@ func (s *service) GetTickets(ctx context.Context, tenantID, creatorID string, pa
 	// Get user from database to retrieve InvGateUserID
 	user, err := s.userRepo.GetByEmail(ctx, tenantID, creatorID)
 	if err != nil {
-		s.logger.WithError(err).
+		s.log(ctx).WithError(err).
 			WithField("email", creatorID).
 			Error("failed to get user from database")
 		return nil, errors.NewAppError(
@@ -32,7 +34,7 @@ func (s *service) GetTickets(ctx context.Context, tenantID, creatorID string, pa
 	}
 
 	if user == nil {
-		s.logger.WithField("email", creatorID).
+		s.log(ctx).WithField("email", creatorID).
 			Warn("user not found")
 		return nil, errors.NewAppError(
 			errors.ErrCodeNotFound,
@@ -46,86 +48,32 @@ func (s *service) GetTickets(ctx context.Context, tenantID, creatorID string, pa
 	// InvGate will return next_page_key for subsequent pages
 	pageKey := ""
 
-	// Call InvGate API with view_id=7 (hardcoded as per requirement)
+	// Call InvGate API with the portal's ticket view
 	// Note: We don't pass creatorID to InvGate because the API doesn't support filtering by creator
 	// We'll filter the results ourselves after getting the response
-	resp, err := s.client.GetTicketsByView(ctx, 7, pageKey, 0)
+	resp, err := s.client.GetTicketsByView(ctx, constants.InvGateTicketViewID, pageKey, 0)
 	if err != nil {
-		s.logger.WithError(err).
+		s.log(ctx).WithError(err).
 			WithField("invGateUserID", user.InvGateUserID).
 			Error("failed to get tickets from InvGate")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to fetch tickets from external service",
-			err,
-		)
+		return nil, invgate.AsAppError(err, "failed to fetch tickets from external service")
 	}
 
-	// Extract data array from response
-	var allTickets []map[string]interface{}
-	if data, ok := resp["data"].([]interface{}); ok {
-		// Transform each ticket to frontend-compatible format
-		allTickets = TransformInvGateTicketList(data)
+	// Filter tickets by creator (InvGate user ID)
+	// Only include tickets where creator field matches the logged-in user's InvGateUserID
+	filteredTickets := make([]TicketV1, 0, len(resp.Incidents))
+	for _, incident := range resp.Incidents {
+		if incident.CreatorID == user.InvGateUserID {
+			filteredTickets = append(filteredTickets, newTicketV1(incident))
+		}
 	}
 
 	// Sort tickets by ID descending (newest first)
 	// This ensures the most recent tickets appear at the top
-	sort.Slice(allTickets, func(i, j int) bool {
-		idI, okI := allTickets[i]["id"]
-		idJ, okJ := allTickets[j]["id"]
-
-		if !okI || !okJ {
-			return false
-		}
-
-		// Convert to float64 for comparison (JSON unmarshaling uses float64 for numbers)
-		var numI, numJ float64
-		switch v := idI.(type) {
-		case float64:
-			numI = v
-		case int:
-			numI = float64(v)
-		case int64:
-			numI = float64(v)
-		}
-
-		switch v := idJ.(type) {
-		case float64:
-			numJ = v
-		case int:
-			numJ = float64(v)
-		case int64:
-			numJ = float64(v)
-		}
-
-		return numI > numJ // Descending order (newest first)
+	sort.Slice(filteredTickets, func(i, j int) bool {
+		return filteredTickets[i].ID > filteredTickets[j].ID
 	})
 
-	// Filter tickets by creator (InvGate user ID)
-	// Only include tickets where creator field matches the logged-in user's InvGateUserID
-	var filteredTickets []map[string]interface{}
-	for _, ticket := range allTickets {
-		// Check if creator field matches user's InvGateUserID
-		if creatorID, ok := ticket["creator_id"]; ok {
-			// Handle both int and float64 from JSON unmarshaling
-			var creatorIDInt int
-			switch v := creatorID.(type) {
-			case int:
-				creatorIDInt = v
-			case float64:
-				creatorIDInt = int(v)
-			case int64:
-				creatorIDInt = int(v)
-			default:
-				continue
-			}
-
-			if creatorIDInt == user.InvGateUserID {
-				filteredTickets = append(filteredTickets, ticket)
-			}
-		}
-	}
-
 	// Build pagination metadata based on filtered results
 	totalCount := len(filteredTickets)
 	totalPages := 1
@@ -137,45 +85,36 @@ func (s *service) GetTickets(ctx context.Context, tenantID, creatorID string, pa
 	startIdx := (page - 1) * limit
 	endIdx := startIdx + limit
 
-	var paginatedTickets []map[string]interface{}
-	if startIdx >= len(filteredTickets) {
-		paginatedTickets = []map[string]interface{}{}
-	} else {
+	paginatedTickets := []TicketV1{}
+	if startIdx < len(filteredTickets) {
 		if endIdx > len(filteredTickets) {
 			endIdx = len(filteredTickets)
 		}
 		paginatedTickets = filteredTickets[startIdx:endIdx]
 	}
 
-	return map[string]interface{}{
-		"data": paginatedTickets,
-		"pagination": map[string]interface{}{
-			"page":        page,
-			"limit":       limit,
-			"total":       totalCount,
-			"total_pages": totalPages,
-			"has_next":    page < totalPages,
-			"has_prev":    page > 1,
+	return &TicketListV1{
+		Data: paginatedTickets,
+		Pagination: PaginationV1{
+			Page:       page,
+			Limit:      limit,
+			Total:      totalCount,
+			TotalPages: totalPages,
+			HasNext:    page < totalPages,
+			HasPrev:    page > 1,
 		},
 	}, nil
 }
 
-func (s *service) GetTicketDetail(ctx context.Context, ticketID string) (map[string]interface{}, error) {
+func (s *service) GetTicketDetail(ctx context.Context, ticketID string) (*TicketV1, error) {
 	resp, err := s.client.GetTicketDetail(ctx, ticketID)
 	if err != nil {
-		s.logger.WithError(err).
+		s.log(ctx).WithError(err).
 			WithField("ticketID", ticketID).
 			Error("failed to get ticket detail from InvGate")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to fetch ticket detail from external service",
-			err,
-		)
-	}
-
-	if statusID, ok := resp["status_id"]; ok {
-		resp["status"] = getStatusName(statusID)
+		return nil, invgate.AsAppError(err, "failed to fetch ticket detail from external service")
 	}
 
-	return resp, nil
+	ticket := newTicketV1(*resp)
+	return &ticket, nil
 }
@@ -2,27 +2,19 @@ package ticket
 
 import (
 	"context"
-	"strconv"
 
 	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/invgate"
 )
 
-func (s *service) GetCategories(ctx context.Context) (map[string]interface{}, error) {
+func (s *service) GetCategories(ctx context.Context) (*CategoryListV1, error) {
 	resp, err := s.client.GetCategories(ctx)
 	if err != nil {
-		s.logger.WithError(err).Error("failed to get categories from InvGate")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to fetch categories from external service",
-			err,
-		)
+		s.log(ctx).WithError(err).Error("failed to get categories from InvGate")
+		return nil, invgate.AsAppError(err, "failed to fetch categories from external service")
 	}
 
-	filteredCategories := s.filterAllowedCategories(resp)
-
-	return map[string]interface{}{
-		"data": filteredCategories,
-	}, nil
+	return &CategoryListV1{Data: s.filterAllowedCategories(resp)}, nil
 }
 
 func (s *service) GetTicketMeta(ctx context.Context) (map[string]interface{}, error) {
@@ -66,29 +58,32 @@ func (s *service) GetStatuses(ctx context.Context) (map[string]interface{}, erro
 	}, nil
 }
 
-func (s *service) GetTicketAttachment(ctx context.Context, attachmentID string) ([]byte, string, string, error) {
-	data, filename, contentType, err := s.client.GetTicketAttachment(ctx, attachmentID)
+func (s *service) GetTicketAttachment(ctx context.Context, attachmentID string, opts invgate.DownloadOptions) (*invgate.Download, error) {
+	download, err := s.client.GetTicketAttachment(ctx, attachmentID, opts)
 	if err != nil {
-		s.logger.WithError(err).WithField("attachmentID", attachmentID).Error("failed to get ticket attachment from InvGate")
-		return nil, "", "", errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to fetch attachment",
-			err,
-		)
+		s.log(ctx).WithError(err).WithField("attachmentID", attachmentID).Error("failed to get ticket attachment from InvGate")
+		return nil, invgate.AsAppError(err, "failed to fetch attachment")
 	}
 
-	if contentType == "" {
-		contentType = "application/octet-stream"
+	if download.ContentType == "" {
+		download.ContentType = "application/octet-stream"
 	}
 
-	return data, filename, contentType, nil
+	return download, nil
 }
 
-func (s *service) GetTicketAttachmentInfo(ctx context.Context, attachmentID string) (map[string]interface{}, error) {
-	return s.client.GetTicketAttachmentInfo(ctx, attachmentID)
+func (s *service) GetTicketAttachmentInfo(ctx context.Context, attachmentID string) (*AttachmentV1, error) {
+	info, err := s.client.GetTicketAttachmentInfo(ctx, attachmentID)
+	if err != nil {
+		s.log(ctx).WithError(err).WithField("attachmentID", attachmentID).Error("failed to get ticket attachment info from InvGate")
+		return nil, invgate.AsAppError(err, "failed to fetch attachment info")
+	}
+
+	attachment := newAttachmentV1(*info)
+	return &attachment, nil
 }
 
-func (s *service) GetArticlesByCategory(ctx context.Context, categoryID int) (map[string]interface{}, error) {
+func (s *service) GetArticlesByCategory(ctx context.Context, categoryID int) (*ArticleListV1, error) {
 	if categoryID <= 0 {
 		return nil, errors.NewAppError(
 			errors.ErrCodeInvalidInput,
@@ -99,18 +94,18 @@ func (s *service) GetArticlesByCategory(ctx context.Context, categoryID int) (ma
 
 	resp, err := s.client.GetArticlesByCategory(ctx, categoryID)
 	if err != nil {
-		s.logger.WithError(err).WithField("categoryID", categoryID).Error("failed to get articles from InvGate")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to fetch articles from external service",
-			err,
-		)
+		s.log(ctx).WithError(err).WithField("categoryID", categoryID).Error("failed to get articles from InvGate")
+		return nil, invgate.AsAppError(err, "failed to fetch articles from external service")
 	}
 
-	return resp, nil
+	articles := make([]ArticleV1, 0, len(resp))
+	for _, article := range resp {
+		articles = append(articles, newArticleV1(article))
+	}
+	return &ArticleListV1{Data: articles}, nil
 }
 
-func (s *service) filterAllowedCategories(resp map[string]interface{}) []map[string]interface{} {
+func (s *service) filterAllowedCategories(categories []invgate.Category) []CategoryV1 {
 	allowedIDs := map[int]bool{
 		115: true, // ESS
 		116: true, // Kehadiran
@@ -123,57 +118,12 @@ func (s *service) filterAllowedCategories(resp map[string]interface{}) []map[str
 		123: true, // Pengaturan Perusahaan
 	}
 
-	var categories []interface{}
-
-	if data, ok := resp["data"]; ok {
-		if arr, ok := data.([]interface{}); ok {
-			categories = arr
-		}
-	} else if arr, ok := resp["categories"].([]interface{}); ok {
-		categories = arr
-	} else {
-		for _, v := range resp {
-			if arr, ok := v.([]interface{}); ok {
-				categories = arr
-				break
-			}
-		}
-	}
-
-	var filtered []map[string]interface{}
-	for _, item := range categories {
-		category, ok := item.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
-		var id int
-		if idVal, exists := category["id"]; exists {
-			switch v := idVal.(type) {
-			case int:
-				id = v
-			case int64:
-				id = int(v)
-			case float64:
-				id = int(v)
-			case string:
-				parsed, err := strconv.Atoi(v)
-				if err != nil {
-					continue
-				}
-				id = parsed
-			default:
-				continue
-			}
-		} else {
-			continue
-		}
-
-		if allowedIDs[id] {
-			filtered = append(filtered, category)
+	filtered := make([]CategoryV1, 0, len(categories))
+	for _, category := range categories {
+		if allowedIDs[category.ID] {
+			filtered = append(filtered, CategoryV1{ID: category.ID, Name: category.Name})
 		}
 	}
 
 	return filtered
 }
-
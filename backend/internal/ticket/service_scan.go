@@ -0,0 +1,47 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"mime/multipart"
+
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/scanner"
+)
+
+// scanAttachments runs every file through the malware scanner before it is
+// forwarded to InvGate. Scanner failures reject the request rather than
+// letting unscanned files through.
+func (s *service) scanAttachments(ctx context.Context, tenantID, email string, files []*multipart.FileHeader) error {
+	for _, fh := range files {
+		if fh == nil {
+			continue
+		}
+
+		fields := logrus.Fields{
+			"tenant_id": tenantID,
+			"email":     email,
+			"filename":  fh.Filename,
+			"size":      fh.Size,
+		}
+
+		result, err := scanner.ScanFile(ctx, s.scanner, fh)
+		if err != nil {
+			s.log(ctx).WithError(err).WithFields(fields).Error("failed to scan attachment")
+			return errors.NewAppError(errors.ErrCodeExternalService, "failed to scan attachments", err)
+		}
+
+		if result.Infected {
+			s.log(ctx).WithFields(fields).WithField("signature", result.Signature).Warn("infected attachment rejected")
+			return errors.NewAppError(
+				errors.ErrCodeAttachmentRejected,
+				fmt.Sprintf("attachment %s was rejected: malware detected (%s)", fh.Filename, result.Signature),
+				nil,
+			)
+		}
+		s.log(ctx).WithFields(fields).Info("attachment scanned clean")
+	}
+	return nil
+}
@@ -6,11 +6,13 @@ import (
 
 	"github.com/sirupsen/logrus"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/errors"
 	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/webhook"
 )
 
-func (s *service) UpdateTicketSolution(ctx context.Context, req TicketSolutionRequest) (map[string]interface{}, error) {
+func (s *service) UpdateTicketSolution(ctx context.Context, tenantID string, req TicketSolutionRequest, userEmail string) (*ActionResultV1, error) {
 	if req.RequestID <= 0 {
 		return nil, errors.NewAppError(
 			errors.ErrCodeInvalidInput,
@@ -19,7 +21,8 @@ func (s *service) UpdateTicketSolution(ctx context.Context, req TicketSolutionRe
 		)
 	}
 
-	if req.Rating < 1 || req.Rating > 5 {
+	// Rating 0 means no rating; the handler decides whether one is required.
+	if req.Rating < 0 || req.Rating > 5 {
 		return nil, errors.NewAppError(
 			errors.ErrCodeInvalidInput,
 			"rating must be between 1 and 5",
@@ -28,7 +31,7 @@ func (s *service) UpdateTicketSolution(ctx context.Context, req TicketSolutionRe
 	}
 
 	// Comment is required only if rating is less than 4
-	if req.Rating < 4 && strings.TrimSpace(req.Comment) == "" {
+	if req.Rating != 0 && req.Rating < 4 && strings.TrimSpace(req.Comment) == "" {
 		return nil, errors.NewAppError(
 			errors.ErrCodeInvalidInput,
 			"comment is required when rating is less than 4",
@@ -42,21 +45,29 @@ func (s *service) UpdateTicketSolution(ctx context.Context, req TicketSolutionRe
 		Rating:  req.Rating,
 	})
 	if err != nil {
-		s.logger.WithError(err).WithFields(logrus.Fields{
-			"requestID": req.RequestID,
-			"rating":    req.Rating,
+		s.log(ctx).WithError(err).WithFields(logrus.Fields{
+			"ticketID": req.RequestID,
+			"rating":   req.Rating,
 		}).Error("failed to accept ticket solution in InvGate")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to accept ticket solution in external service",
-			err,
-		)
+		return nil, invgate.AsAppError(err, "failed to accept ticket solution in external service")
 	}
 
-	return resp, nil
+	s.record(ctx, tenantID, audit.ActionTicketSolutionAccepted, req.RequestID, userEmail, audit.Changes{
+		"rating":  {After: req.Rating},
+		"comment": {After: req.Comment},
+	})
+	s.publish(ctx, tenantID, webhook.EventSolutionAccepted, webhook.EventData{
+		TicketID:   req.RequestID,
+		ActorEmail: userEmail,
+		Comment:    req.Comment,
+		Rating:     req.Rating,
+	})
+
+	result := newActionResultV1(resp)
+	return &result, nil
 }
 
-func (s *service) RejectTicketSolution(ctx context.Context, req TicketSolutionRejectRequest) (map[string]interface{}, error) {
+func (s *service) RejectTicketSolution(ctx context.Context, tenantID string, req TicketSolutionRejectRequest, userEmail string) (*ActionResultV1, error) {
 	if req.RequestID <= 0 {
 		return nil, errors.NewAppError(
 			errors.ErrCodeInvalidInput,
@@ -78,15 +89,21 @@ func (s *service) RejectTicketSolution(ctx context.Context, req TicketSolutionRe
 		Comment: req.Comment,
 	})
 	if err != nil {
-		s.logger.WithError(err).WithFields(logrus.Fields{
-			"requestID": req.RequestID,
+		s.log(ctx).WithError(err).WithFields(logrus.Fields{
+			"ticketID": req.RequestID,
 		}).Error("failed to reject ticket solution in InvGate")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to reject ticket solution in external service",
-			err,
-		)
+		return nil, invgate.AsAppError(err, "failed to reject ticket solution in external service")
 	}
 
-	return resp, nil
+	s.record(ctx, tenantID, audit.ActionTicketSolutionRejected, req.RequestID, userEmail, audit.Changes{
+		"comment": {After: req.Comment},
+	})
+	s.publish(ctx, tenantID, webhook.EventSolutionRejected, webhook.EventData{
+		TicketID:   req.RequestID,
+		ActorEmail: userEmail,
+		Comment:    req.Comment,
+	})
+
+	result := newActionResultV1(resp)
+	return &result, nil
 }
@@ -1,7 +1,7 @@
 package ticket
 
 // getStatusName returns status name from status_id.
-func getStatusName(statusID interface{}) string {
+func getStatusName(statusID int) string {
 	statusMap := map[int]string{
 		1: "New",
 		2: "Open",
@@ -13,19 +13,7 @@ func getStatusName(statusID interface{}) string {
 		8: "Canceled",
 	}
 
-	var id int
-	switch v := statusID.(type) {
-	case int:
-		id = v
-	case int64:
-		id = int(v)
-	case float64:
-		id = int(v)
-	default:
-		return ""
-	}
-
-	if name, ok := statusMap[id]; ok {
+	if name, ok := statusMap[statusID]; ok {
 		return name
 	}
 	return ""
@@ -0,0 +1,244 @@
+package ticket
+
+import (
+	"context"
+	"mime/multipart"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"werk-ticketing/internal/audit"
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/invgate/invgatetest"
+	"werk-ticketing/internal/webhook"
+)
+
+func TestCreateTicketAndGetDetail(t *testing.T) {
+	f := newTicketFixture(t)
+	ctx := context.Background()
+
+	created, err := f.service.CreateTicket(ctx, testTenantID, TicketRequest{
+		SourceID:    2,
+		CategoryID:  115,
+		TypeID:      1,
+		PriorityID:  2,
+		Title:       "Cannot log in",
+		Description: "Password rejected",
+		AttachmentFiles: []*multipart.FileHeader{
+			invgatetest.FileHeader(t, "error.txt", []byte("401")),
+		},
+	}, "alice@example.com")
+	if err != nil {
+		t.Fatalf("CreateTicket: %v", err)
+	}
+	if created.ID == 0 || created.Status != "OK" {
+		t.Fatalf("CreateTicket = %+v", created)
+	}
+	if len(f.webhooks.events) != 1 || f.webhooks.events[0] != webhook.EventTicketCreated || f.webhooks.data[0].TicketID != created.ID {
+		t.Errorf("webhook events = %v %+v", f.webhooks.events, f.webhooks.data)
+	}
+
+	stored, _ := f.fake.Incident(created.ID)
+	if stored.CreatorID != f.alice || stored.CustomerID != f.alice {
+		t.Errorf("ticket creator/customer = %d/%d, want %d", stored.CreatorID, stored.CustomerID, f.alice)
+	}
+
+	detail, err := f.service.GetTicketDetail(ctx, strconv.Itoa(created.ID))
+	if err != nil {
+		t.Fatalf("GetTicketDetail: %v", err)
+	}
+	if detail.Title != "Cannot log in" || detail.Status != "New" || detail.WrkTicketID != "WRK-#"+strconv.Itoa(created.ID) {
+		t.Errorf("GetTicketDetail = %+v", detail)
+	}
+	if len(detail.Attachments) != 1 {
+		t.Errorf("attachments = %v, want one", detail.Attachments)
+	}
+}
+
+func TestCreateTicketUnknownUser(t *testing.T) {
+	f := newTicketFixture(t)
+
+	_, err := f.service.CreateTicket(context.Background(), testTenantID, TicketRequest{Title: "t", Description: "d"}, "nobody@example.com")
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != errors.ErrCodeNotFound {
+		t.Fatalf("CreateTicket error = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestCreateTicketRejectsInfectedAttachment(t *testing.T) {
+	f := newTicketFixture(t)
+	f.scanner.marker = "EICAR"
+
+	_, err := f.service.CreateTicket(context.Background(), testTenantID, TicketRequest{
+		Title: "Invoice", Description: "see attached",
+		AttachmentFiles: []*multipart.FileHeader{
+			invgatetest.FileHeader(t, "notes.txt", []byte("clean")),
+			invgatetest.FileHeader(t, "invoice.pdf", []byte("EICAR payload")),
+		},
+	}, "alice@example.com")
+
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != errors.ErrCodeAttachmentRejected || !strings.Contains(appErr.Message, "invoice.pdf") {
+		t.Fatalf("CreateTicket error = %v", err)
+	}
+	if calls := f.fake.Calls("POST", "incident"); calls != 0 {
+		t.Errorf("incident created %d times despite infected attachment", calls)
+	}
+}
+
+func TestGetTicketsFiltersByCreatorAndPaginates(t *testing.T) {
+	f := newTicketFixture(t)
+	var aliceTickets []int
+	for i := 0; i < 3; i++ {
+		aliceTickets = append(aliceTickets, f.fake.AddIncident(invgatetest.Incident{Title: "alice", CreatorID: f.alice}))
+	}
+	f.fake.AddIncident(invgatetest.Incident{Title: "bob", CreatorID: f.bob})
+
+	list, err := f.service.GetTickets(context.Background(), testTenantID, "alice@example.com", 1, 2)
+	if err != nil {
+		t.Fatalf("GetTickets: %v", err)
+	}
+
+	if list.Pagination.Total != 3 || list.Pagination.TotalPages != 2 || !list.Pagination.HasNext || list.Pagination.HasPrev {
+		t.Errorf("pagination = %+v", list.Pagination)
+	}
+	if len(list.Data) != 2 {
+		t.Fatalf("page size = %d, want 2", len(list.Data))
+	}
+	// Newest (highest id) first.
+	if list.Data[0].ID != aliceTickets[2] || list.Data[1].ID != aliceTickets[1] {
+		t.Errorf("order = %d, %d", list.Data[0].ID, list.Data[1].ID)
+	}
+	for _, ticket := range list.Data {
+		if ticket.CreatorID != f.alice {
+			t.Errorf("ticket %d has creator %d, want %d", ticket.ID, ticket.CreatorID, f.alice)
+		}
+	}
+
+	last, err := f.service.GetTickets(context.Background(), testTenantID, "alice@example.com", 2, 2)
+	if err != nil {
+		t.Fatalf("GetTickets page 2: %v", err)
+	}
+	if len(last.Data) != 1 || last.Data[0].ID != aliceTickets[0] || last.Pagination.HasNext {
+		t.Errorf("page 2 = %+v", last)
+	}
+}
+
+func TestCommentsRoundTrip(t *testing.T) {
+	f := newTicketFixture(t)
+	ctx := context.Background()
+	ticketID := f.fake.AddIncident(invgatetest.Incident{Title: "t", CreatorID: f.alice})
+
+	if _, err := f.service.AddTicketComment(ctx, testTenantID, TicketCommentRequest{RequestID: ticketID, Comment: "any update?"}, "alice@example.com"); err != nil {
+		t.Fatalf("AddTicketComment: %v", err)
+	}
+
+	comments, err := f.service.GetTicketComments(ctx, ticketID)
+	if err != nil {
+		t.Fatalf("GetTicketComments: %v", err)
+	}
+	if len(comments.Data) != 1 || comments.Data[0].Comment != "any update?" || comments.Data[0].AuthorID != f.alice {
+		t.Errorf("comments = %+v", comments.Data)
+	}
+}
+
+func TestSolutionFlow(t *testing.T) {
+	f := newTicketFixture(t)
+	ctx := context.Background()
+	ticketID := f.fake.AddIncident(invgatetest.Incident{Title: "t", CreatorID: f.alice, StatusID: invgatetest.StatusResolved})
+
+	if _, err := f.service.RejectTicketSolution(ctx, testTenantID, TicketSolutionRejectRequest{RequestID: ticketID, Comment: "not fixed"}, "alice@example.com"); err != nil {
+		t.Fatalf("RejectTicketSolution: %v", err)
+	}
+	if _, err := f.service.UpdateTicketSolution(ctx, testTenantID, TicketSolutionRequest{RequestID: ticketID, Rating: 5}, "alice@example.com"); err != nil {
+		t.Fatalf("UpdateTicketSolution: %v", err)
+	}
+
+	detail, err := f.service.GetTicketDetail(ctx, strconv.Itoa(ticketID))
+	if err != nil {
+		t.Fatalf("GetTicketDetail: %v", err)
+	}
+	if detail.Status != "Closed" || detail.ClosedAt == nil || detail.Rating != 5 {
+		t.Errorf("ticket after accept = %+v", detail)
+	}
+	if got := strings.Join(f.webhooks.events, ","); got != webhook.EventSolutionRejected+","+webhook.EventSolutionAccepted {
+		t.Errorf("webhook events = %s", got)
+	}
+}
+
+func TestUpdateTicketRecordsBeforeAndAfter(t *testing.T) {
+	f := newTicketFixture(t)
+	ctx := context.Background()
+	ticketID := f.fake.AddIncident(invgatetest.Incident{Title: "Printer jam", CreatorID: f.alice, PriorityID: 2})
+
+	title, priority := "Printer on fire", 2
+	if _, err := f.service.UpdateTicket(ctx, testTenantID, ticketID, TicketUpdateRequest{
+		Title:      &title,
+		PriorityID: &priority,
+	}, "alice@example.com"); err != nil {
+		t.Fatalf("UpdateTicket: %v", err)
+	}
+
+	events := f.audit.Events()
+	if len(events) != 1 {
+		t.Fatalf("audit events = %d, want 1", len(events))
+	}
+	ev := events[0]
+	if ev.Action != audit.ActionTicketUpdated || ev.ActorEmail != "alice@example.com" || ev.TargetID != strconv.Itoa(ticketID) {
+		t.Errorf("audit event = %+v", ev)
+	}
+	want := audit.Changes{"title": {Before: "Printer jam", After: "Printer on fire"}}
+	if !reflect.DeepEqual(ev.Changes, want) {
+		t.Errorf("audit changes = %v, want %v", ev.Changes, want)
+	}
+}
+
+func TestGetTicketDetailNotFound(t *testing.T) {
+	f := newTicketFixture(t)
+
+	_, err := f.service.GetTicketDetail(context.Background(), "424242")
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Code != errors.ErrCodeNotFound {
+		t.Fatalf("GetTicketDetail error = %v, want NOT_FOUND", err)
+	}
+}
+
+func TestGetCategoriesOnlyReturnsAllowed(t *testing.T) {
+	f := newTicketFixture(t)
+	f.fake.SetCategories(
+		invgatetest.Category{ID: 115, Name: "ESS"},
+		invgatetest.Category{ID: 999, Name: "Internal"},
+		invgatetest.Category{ID: 119, Name: "CRM"},
+	)
+
+	categories, err := f.service.GetCategories(context.Background())
+	if err != nil {
+		t.Fatalf("GetCategories: %v", err)
+	}
+	if len(categories.Data) != 2 || categories.Data[0].ID != 115 || categories.Data[1].ID != 119 {
+		t.Errorf("categories = %+v", categories.Data)
+	}
+}
+
+func TestGetArticlesAndUser(t *testing.T) {
+	f := newTicketFixture(t)
+	ctx := context.Background()
+	f.fake.AddArticle(invgatetest.Article{CategoryID: 16, Title: "Reset your password", AuthorID: f.bob})
+
+	articles, err := f.service.GetArticlesByCategory(ctx, 16)
+	if err != nil {
+		t.Fatalf("GetArticlesByCategory: %v", err)
+	}
+	if len(articles.Data) != 1 || articles.Data[0].AuthorID != f.bob {
+		t.Errorf("articles = %+v", articles.Data)
+	}
+
+	u, err := f.service.GetInvGateUser(ctx, f.bob)
+	if err != nil {
+		t.Fatalf("GetInvGateUser: %v", err)
+	}
+	if u.Email != "bob@example.com" {
+		t.Errorf("GetInvGateUser = %+v", u)
+	}
+}
@@ -2,14 +2,16 @@ package ticket
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/sirupsen/logrus"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/errors"
 	"werk-ticketing/internal/invgate"
 )
 
-func (s *service) UpdateTicket(ctx context.Context, ticketID int, req TicketUpdateRequest) (map[string]interface{}, error) {
+func (s *service) UpdateTicket(ctx context.Context, tenantID string, ticketID int, req TicketUpdateRequest, userEmail string) (*ActionResultV1, error) {
 	if ticketID <= 0 {
 		return nil, errors.NewAppError(
 			errors.ErrCodeInvalidInput,
@@ -18,6 +20,14 @@ func (s *service) UpdateTicket(ctx context.Context, ticketID int, req TicketUpda
 		)
 	}
 
+	// InvGate does not return the previous values, so the ticket is loaded
+	// first for the audit log.
+	current, err := s.client.GetTicketDetail(ctx, strconv.Itoa(ticketID))
+	if err != nil {
+		s.log(ctx).WithError(err).WithField("ticketID", ticketID).Error("failed to get ticket from InvGate")
+		return nil, invgate.AsAppError(err, "failed to fetch ticket from external service")
+	}
+
 	payload := invgate.UpdateTicketPayload{
 		ID: ticketID,
 	}
@@ -52,20 +62,54 @@ func (s *service) UpdateTicket(ctx context.Context, ticketID int, req TicketUpda
 
 	resp, err := s.client.UpdateTicket(ctx, payload)
 	if err != nil {
-		s.logger.WithError(err).WithFields(logrus.Fields{
+		s.log(ctx).WithError(err).WithFields(logrus.Fields{
 			"ticketID": ticketID,
 		}).Error("failed to update ticket in InvGate")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to update ticket in external service",
-			err,
-		)
+		return nil, invgate.AsAppError(err, "failed to update ticket in external service")
 	}
 
-	return resp, nil
+	s.record(ctx, tenantID, audit.ActionTicketUpdated, ticketID, userEmail, audit.Diff(updatedFields(current, req), req))
+
+	result := newActionResultV1(resp)
+	return &result, nil
+}
+
+// updatedFields returns the ticket's values of the fields set in req, in the
+// shape of req so the two can be diffed.
+func updatedFields(current *invgate.Incident, req TicketUpdateRequest) TicketUpdateRequest {
+	var before TicketUpdateRequest
+	if req.SourceID != nil {
+		before.SourceID = &current.SourceID
+	}
+	if req.CreatorID != nil {
+		before.CreatorID = &current.CreatorID
+	}
+	if req.CustomerID != nil {
+		before.CustomerID = &current.CustomerID
+	}
+	if req.CategoryID != nil {
+		before.CategoryID = &current.CategoryID
+	}
+	if req.TypeID != nil {
+		before.TypeID = &current.TypeID
+	}
+	if req.PriorityID != nil {
+		before.PriorityID = &current.PriorityID
+	}
+	if req.Title != nil {
+		before.Title = &current.Title
+	}
+	if req.Description != nil {
+		before.Description = &current.Description
+	}
+	if req.DateOcurred != nil {
+		dateOcurred := int(current.DateOcurred)
+		before.DateOcurred = &dateOcurred
+	}
+	return before
 }
 
-func (s *service) GetInvGateUser(ctx context.Context, userID int) (map[string]interface{}, error) {
+func (s *service) GetInvGateUser(ctx context.Context, userID int) (*UserV1, error) {
 	if userID <= 0 {
 		return nil, errors.NewAppError(
 			errors.ErrCodeInvalidInput,
@@ -76,14 +120,11 @@ func (s *service) GetInvGateUser(ctx context.Context, userID int) (map[string]in
 
 	resp, err := s.client.GetUser(ctx, userID)
 	if err != nil {
-		s.logger.WithError(err).WithField("userID", userID).Error("failed to get user from InvGate")
-		return nil, errors.NewAppError(
-			errors.ErrCodeExternalService,
-			"failed to fetch user from external service",
-			err,
-		)
+		s.log(ctx).WithError(err).WithField("userID", userID).Error("failed to get user from InvGate")
+		return nil, invgate.AsAppError(err, "failed to fetch user from external service")
 	}
 
-	return resp, nil
+	user := newUserV1(*resp)
+	return &user, nil
 }
 
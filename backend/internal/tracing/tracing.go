@@ -0,0 +1,81 @@
+// Package tracing sets up OpenTelemetry tracing and holds the helpers the
+// HTTP, database, InvGate and email instrumentation share.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"werk-ticketing/internal/config"
+)
+
+const instrumentationName = "werk-ticketing"
+
+// Setup installs the global tracer provider, exporting spans over OTLP/HTTP
+// to cfg.OTLPEndpoint. When tracing is disabled the global no-op provider is
+// kept and spans cost next to nothing. The returned function flushes
+// pending spans and must be called on shutdown.
+func Setup(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	if !cfg.TracingEnabled {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+	if err != nil {
+		return nil, err
+	}
+	provider := NewProvider(exporter, cfg.TracingSampleRatio, resource.NewSchemaless(
+		attribute.String("service.name", cfg.TracingServiceName),
+		attribute.String("deployment.environment.name", cfg.AppEnv),
+	))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider creates a tracer provider batching spans to exporter. Root
+// spans are sampled with probability ratio; child spans follow their
+// parent, so a trace propagated by a caller is never cut in half.
+func NewProvider(exporter sdktrace.SpanExporter, ratio float64, res *resource.Resource) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(res),
+	)
+}
+
+// Tracer returns the application's tracer from the global provider. It is
+// looked up on every use so tests can swap the provider.
+func Tracer() trace.Tracer {
+	return otel.GetTracerProvider().Tracer(instrumentationName)
+}
+
+// End records err on span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// TraceID returns the ID of the trace ctx belongs to, or "" when the span
+// in ctx is not sampled.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsSampled() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
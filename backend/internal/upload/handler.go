@@ -1,72 +1,236 @@
 package upload
 
 import (
+	stdErrors "errors"
 	"fmt"
+	"mime"
+	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
+	"path"
 	"strings"
 
+	"werk-ticketing/internal/attachment"
 	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/middleware"
 	"werk-ticketing/internal/response"
+	"werk-ticketing/internal/scanner"
+	"werk-ticketing/internal/storage"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
 )
 
-type Handler struct{}
+// Handler stores image uploads after policy and malware checks.
+type Handler struct {
+	service Service
+	blob    storage.Blob
+	scanner scanner.Scanner
+	logger  *logrus.Logger
+}
 
-func NewHandler() *Handler {
-	return &Handler{}
+// NewHandler creates upload handler.
+func NewHandler(service Service, blob storage.Blob, scanner scanner.Scanner, logger *logrus.Logger) *Handler {
+	return &Handler{service: service, blob: blob, scanner: scanner, logger: logger}
 }
 
+// UploadFile handles POST /api/v1/upload. The optional "purpose" form field
+// is "general" or "logo"; logos stay private until set as a tenant's logo.
 func (h *Handler) UploadFile(c *gin.Context) {
 	file, err := c.FormFile("file")
 	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if stdErrors.As(err, &tooLarge) {
+			response.ErrorWithCode(c, http.StatusRequestEntityTooLarge, errors.ErrCodeAttachmentRejected, "request body too large")
+			return
+		}
 		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "no file provided")
 		return
 	}
 
-	// Validate extension
-	ext := strings.ToLower(filepath.Ext(file.Filename))
-	allowed := map[string]bool{
-		".jpg":  true,
-		".jpeg": true,
-		".png":  true,
-		".svg":  true,
-		".webp": true,
-		".gif":  true,
+	purpose := c.DefaultPostForm("purpose", PurposeGeneral)
+	if purpose != PurposeGeneral && purpose != PurposeLogo {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid purpose (allowed: general, logo)")
+		return
 	}
 
-	if !allowed[ext] {
-		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid file type (allowed: jpg, jpeg, png, svg, webp, gif)")
+	// Validate size and sniffed content type against the tenant policy
+	var policy attachment.Policy
+	if t := middleware.GetTenant(c); t != nil {
+		policy = t.ImageUploadPolicy()
+	} else {
+		policy = (*attachment.Policy)(nil).Resolve(attachment.ImageTypes)
+	}
+	if err := policy.Check([]*multipart.FileHeader{file}); err != nil {
+		var rejected *attachment.RejectedError
+		if stdErrors.As(err, &rejected) {
+			response.ErrorWithDetails(c, http.StatusBadRequest, errors.ErrCodeAttachmentRejected, rejected.Error(), rejected.Violations)
+		} else {
+			response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "failed to read file")
+		}
 		return
 	}
 
-	// Generate unique filename
-	filename := fmt.Sprintf("%s%s", uuid.New().String(), ext)
-	uploadDir := "./uploads"
+	fields := logrus.Fields{
+		"tenant_id": middleware.GetTenantID(c),
+		"filename":  file.Filename,
+		"size":      file.Size,
+	}
+	result, err := scanner.ScanFile(c.Request.Context(), h.scanner, file)
+	if err != nil {
+		h.logger.WithError(err).WithFields(fields).Error("failed to scan upload")
+		response.ErrorWithCode(c, http.StatusBadGateway, errors.ErrCodeExternalService, "failed to scan file")
+		return
+	}
+	if result.Infected {
+		h.logger.WithFields(fields).WithField("signature", result.Signature).Warn("infected upload rejected")
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeAttachmentRejected,
+			fmt.Sprintf("file %s was rejected: malware detected (%s)", file.Filename, result.Signature))
+		return
+	}
+	h.logger.WithFields(fields).Info("upload scanned clean")
 
-	// Create dir if not exists
-	if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(uploadDir, 0755); err != nil {
-			response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to create upload directory")
-			return
-		}
+	detected, err := attachment.Sniff(file)
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "failed to read file")
+		return
 	}
+	// Name the stored file after its content, not the client's extension
+	ext := detected.Extension()
 
-	dst := filepath.Join(uploadDir, filename)
-	if err := c.SaveUploadedFile(file, dst); err != nil {
+	src, err := file.Open()
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "failed to read file")
+		return
+	}
+	defer src.Close()
+
+	upload, err := h.service.Store(c.Request.Context(), StoreInput{
+		TenantID:   middleware.GetTenantID(c),
+		UploadedBy: middleware.GetUserEmail(c),
+		Filename:   file.Filename,
+		MimeType:   detected.String(),
+		Ext:        ext,
+		Purpose:    purpose,
+		Size:       file.Size,
+		Body:       src,
+	})
+	if stdErrors.Is(err, ErrInvalidImage) {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.WithError(err).WithFields(fields).Error("failed to store upload")
 		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to save file")
 		return
 	}
 
-	// Return public URL
-	// Note: In production, this should be a full URL if serving from CDN or separate domain.
-	// For local/simple setup, relative path works if static middleware is configured.
-	url := fmt.Sprintf("/uploads/%s", filename)
+	url, err := h.service.URL(c.Request.Context(), upload)
+	if err != nil {
+		h.logger.WithError(err).WithField("upload_id", upload.ID).Error("failed to build upload URL")
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to build file URL")
+		return
+	}
 
 	response.Success(c, http.StatusOK, gin.H{
-		"url": url,
+		"id":        upload.ID,
+		"url":       url,
+		"purpose":   upload.Purpose,
+		"is_public": upload.IsPublic,
+		"mime_type": upload.MimeType,
+		"size":      upload.Size,
+		"checksum":  upload.Checksum,
+		"variants":  upload.Variants,
 	})
 }
+
+// Get handles GET /api/v1/uploads/:id. Private files are only visible to
+// the tenant that uploaded them.
+func (h *Handler) Get(c *gin.Context) {
+	h.serve(c, func(upload *Upload) bool {
+		return upload.IsPublic || upload.TenantID == middleware.GetTenantID(c)
+	})
+}
+
+// GetPublic handles GET /uploads/:id without authentication. Only files
+// explicitly flagged public (attached tenant logos) are served.
+func (h *Handler) GetPublic(c *gin.Context) {
+	h.serve(c, func(upload *Upload) bool {
+		return upload.IsPublic
+	})
+}
+
+func (h *Handler) serve(c *gin.Context, visible func(*Upload) bool) {
+	file, err := h.service.Open(c.Request.Context(), c.Param("id"), c.Query("size"))
+	if err != nil && !stdErrors.Is(err, storage.ErrNotFound) {
+		h.logger.WithError(err).WithField("upload_id", c.Param("id")).Error("failed to open upload")
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to read file")
+		return
+	}
+	if file == nil || !visible(file.Upload) {
+		if file != nil {
+			file.Body.Close()
+		}
+		// Hidden files are reported as missing so IDs cannot be probed.
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "file not found")
+		return
+	}
+	defer file.Body.Close()
+
+	etag := `"` + file.ETag + `"`
+	c.Header("ETag", etag)
+	if file.Upload.IsPublic {
+		c.Header("Cache-Control", "public, max-age=86400")
+	} else {
+		c.Header("Cache-Control", "private, no-store")
+	}
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.DataFromReader(http.StatusOK, file.Size, file.MimeType, file.Body, map[string]string{
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "default-src 'none'; style-src 'unsafe-inline'; sandbox",
+	})
+}
+
+// ServeFile handles GET <StoragePublicPath>/*key for local storage. Access is
+// granted by the signature in the URL, not by tenant headers, so signed URLs
+// work in <img> tags.
+func (h *Handler) ServeFile(c *gin.Context) {
+	local, ok := h.blob.(*storage.Local)
+	if !ok {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "file not found")
+		return
+	}
+
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := local.Verify(key, c.Query("expires"), c.Query("signature")); err != nil {
+		response.ErrorWithCode(c, http.StatusForbidden, errors.ErrCodeForbidden, "invalid or expired file URL")
+		return
+	}
+
+	file, obj, err := local.Open(key)
+	if err != nil {
+		if stdErrors.Is(err, storage.ErrNotFound) {
+			response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "file not found")
+		} else {
+			response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to read file")
+		}
+		return
+	}
+	defer file.Close()
+
+	if obj.ContentType != "" {
+		c.Header("Content-Type", obj.ContentType)
+	}
+	// Files are served from the API origin, so a browser must neither guess
+	// their type nor run anything in them; only images are shown inline.
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "default-src 'none'; sandbox")
+	if !strings.HasPrefix(obj.ContentType, "image/") {
+		c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(key)}))
+	}
+	c.Header("Cache-Control", "private, max-age=300")
+	http.ServeContent(c.Writer, c.Request, path.Base(key), obj.ModTime, file)
+}
@@ -0,0 +1,112 @@
+package upload
+
+import (
+	"bytes"
+	stdErrors "errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders for logo uploads
+	_ "image/jpeg"
+	"image/png"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+
+	"werk-ticketing/internal/constants"
+)
+
+// Standard logo sizes served through "?size=" on the upload URL.
+const (
+	LogoOriginal = "original"
+	LogoFavicon  = "favicon"
+	LogoHeader   = "header"
+	LogoEmail    = "email"
+)
+
+// ErrInvalidImage is returned when a logo cannot be decoded or sanitized.
+var ErrInvalidImage = stdErrors.New("invalid image")
+
+// logoVariants are the bounding boxes each raster logo is scaled into.
+var logoVariants = []struct {
+	name          string
+	width, height int
+}{
+	{LogoFavicon, 32, 32},
+	{LogoHeader, 320, 64},
+	{LogoEmail, 200, 80},
+}
+
+type processedLogo struct {
+	data     []byte
+	mimeType string
+	ext      string
+	// variants holds PNG renditions keyed by size name; empty for SVG,
+	// which scales without them.
+	variants map[string][]byte
+}
+
+// processLogo sanitizes SVG logos and re-encodes raster logos as PNG.
+// Re-encoding keeps only pixel data, so EXIF and other metadata are dropped.
+func processLogo(data []byte, mimeType string) (*processedLogo, error) {
+	if mimeType == "image/svg+xml" {
+		clean, err := sanitizeSVG(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		return &processedLogo{data: clean, mimeType: mimeType, ext: ".svg"}, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	if cfg.Width > constants.LogoMaxDimension || cfg.Height > constants.LogoMaxDimension {
+		return nil, fmt.Errorf("%w: logo is larger than %dx%d pixels", ErrInvalidImage, constants.LogoMaxDimension, constants.LogoMaxDimension)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	original, err := encodePNG(src)
+	if err != nil {
+		return nil, err
+	}
+	logo := &processedLogo{data: original, mimeType: "image/png", ext: ".png", variants: map[string][]byte{}}
+	for _, v := range logoVariants {
+		encoded, err := encodePNG(fitWithin(src, v.width, v.height))
+		if err != nil {
+			return nil, err
+		}
+		logo.variants[v.name] = encoded
+	}
+	return logo, nil
+}
+
+// fitWithin scales src down to fit a width x height box, keeping its aspect
+// ratio. Images that already fit are returned unchanged.
+func fitWithin(src image.Image, width, height int) image.Image {
+	b := src.Bounds()
+	if b.Dx() <= width && b.Dy() <= height {
+		return src
+	}
+
+	w, h := width, b.Dy()*width/b.Dx()
+	if h > height {
+		w, h = b.Dx()*height/b.Dy(), height
+	}
+	w, h = max(w, 1), max(h, 1)
+
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,173 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	stdErrors "errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeSVG(t *testing.T) {
+	dirty := `<?xml version="1.0"?>
+<!DOCTYPE svg [<!ENTITY x "boom">]>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 10 10" onload="alert(1)">
+  <script>alert(2)</script>
+  <!-- comment -->
+  <defs><linearGradient id="g"><stop offset="0" stop-color="red"/></linearGradient></defs>
+  <rect width="10" height="10" fill="url(#g)" style="fill:red"/>
+  <circle r="2" fill="url(https://evil.example/x.svg#a)"/>
+  <use xlink:href="https://evil.example/sprite.svg#logo"/>
+  <use href="#g"/>
+  <a href="javascript:alert(3)"><path d="M0 0"/></a>
+  <foreignObject><div xmlns="http://www.w3.org/1999/xhtml">html</div></foreignObject>
+</svg>`
+
+	out, err := sanitizeSVG([]byte(dirty))
+	if err != nil {
+		t.Fatalf("sanitizeSVG: %v", err)
+	}
+	clean := string(out)
+	for _, banned := range []string{"script", "alert", "onload", "style", "evil.example", "foreignObject", "html", "ENTITY", "comment"} {
+		if strings.Contains(clean, banned) {
+			t.Errorf("sanitized SVG contains %q:\n%s", banned, clean)
+		}
+	}
+	for _, kept := range []string{`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 10">`, `fill="url(#g)"`, `<use href="#g">`} {
+		if !strings.Contains(clean, kept) {
+			t.Errorf("sanitized SVG is missing %q:\n%s", kept, clean)
+		}
+	}
+
+	for _, bad := range []string{`<html/>`, `<svg>`, `not xml`} {
+		if _, err := sanitizeSVG([]byte(bad)); err == nil {
+			t.Errorf("sanitizeSVG(%q) succeeded", bad)
+		}
+	}
+}
+
+// jpegWithEXIF encodes a width x height JPEG with an EXIF APP1 segment.
+func jpegWithEXIF(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, height/2, color.RGBA{R: 200, A: 255})
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+
+	payload := append([]byte("Exif\x00\x00"), []byte("GPS 52.37N 4.89E")...)
+	segment := []byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	segment = append(segment, payload...)
+	data := buf.Bytes()
+	// Insert the segment right after the SOI marker.
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func TestStoreProcessesRasterLogo(t *testing.T) {
+	f := newUploadFixture(t)
+	ctx := context.Background()
+	data := jpegWithEXIF(t, 800, 200)
+
+	u, err := f.service.Store(ctx, StoreInput{
+		TenantID: "tenant-1", Filename: "logo.jpg", MimeType: "image/jpeg", Ext: ".jpg",
+		Purpose: PurposeLogo, Size: int64(len(data)), Body: bytes.NewReader(data),
+	})
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if u.MimeType != "image/png" || !strings.HasSuffix(u.StorageKey, ".png") || len(u.Variants) != len(logoVariants) {
+		t.Fatalf("Store = %+v", u)
+	}
+
+	original := openImage(t, f.service, u.ID, "")
+	if bytes.Contains(original, []byte("Exif")) || bytes.Contains(original, []byte("GPS")) {
+		t.Error("re-encoded logo still carries EXIF data")
+	}
+
+	for _, v := range logoVariants {
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(openImage(t, f.service, u.ID, v.name)))
+		if err != nil || format != "png" {
+			t.Fatalf("%s variant: format %q, %v", v.name, format, err)
+		}
+		if cfg.Width > v.width || cfg.Height > v.height || (cfg.Width != v.width && cfg.Height != v.height) {
+			t.Errorf("%s variant is %dx%d, want to fit %dx%d", v.name, cfg.Width, cfg.Height, v.width, v.height)
+		}
+	}
+
+	if err := f.service.(*service).deleteBlobs(ctx, u); err != nil {
+		t.Fatalf("deleteBlobs: %v", err)
+	}
+	if _, err := f.service.Open(ctx, u.ID, LogoFavicon); err == nil {
+		t.Error("variant still readable after deleteBlobs")
+	}
+}
+
+func openImage(t *testing.T, svc Service, id, variant string) []byte {
+	t.Helper()
+	file, err := svc.Open(context.Background(), id, variant)
+	if err != nil {
+		t.Fatalf("Open(%q): %v", variant, err)
+	}
+	defer file.Body.Close()
+	data, _ := io.ReadAll(file.Body)
+	return data
+}
+
+func TestStoreRejectsInvalidLogo(t *testing.T) {
+	f := newUploadFixture(t)
+	_, err := f.service.Store(context.Background(), StoreInput{
+		TenantID: "tenant-1", MimeType: "image/png", Ext: ".png", Purpose: PurposeLogo,
+		Size: 9, Body: strings.NewReader("not a png"),
+	})
+	if !stdErrors.Is(err, ErrInvalidImage) {
+		t.Errorf("Store error = %v, want ErrInvalidImage", err)
+	}
+}
+
+func TestStoreSanitizesGeneralSVG(t *testing.T) {
+	f := newUploadFixture(t)
+	svg := `<svg xmlns="http://www.w3.org/2000/svg"><script>alert(1)</script><rect width="1" height="1"/></svg>`
+	u, err := f.service.Store(context.Background(), StoreInput{
+		TenantID: "tenant-1", MimeType: "image/svg+xml", Ext: ".svg", Purpose: PurposeGeneral,
+		Size: int64(len(svg)), Body: strings.NewReader(svg),
+	})
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	file, err := f.service.Open(context.Background(), u.ID, "")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer file.Body.Close()
+	stored, _ := io.ReadAll(file.Body)
+	if strings.Contains(string(stored), "script") || !strings.Contains(string(stored), "<rect") || u.Size != int64(len(stored)) {
+		t.Errorf("stored SVG = %q (size %d)", stored, u.Size)
+	}
+}
+
+func TestLogoURLs(t *testing.T) {
+	svc := newUploadFixture(t).service
+
+	urls := svc.LogoURLs("/uploads/3f2b8c1e-1111-4222-8333-944455556666")
+	if urls[LogoOriginal] != "/uploads/3f2b8c1e-1111-4222-8333-944455556666" ||
+		urls[LogoFavicon] != "/uploads/3f2b8c1e-1111-4222-8333-944455556666?size=favicon" {
+		t.Errorf("LogoURLs(upload) = %v", urls)
+	}
+
+	external := "https://cdn.example.com/logo.png"
+	urls = svc.LogoURLs(external)
+	if urls[LogoOriginal] != external || urls[LogoEmail] != external {
+		t.Errorf("LogoURLs(external) = %v", urls)
+	}
+	if svc.LogoURLs("") != nil {
+		t.Error("LogoURLs(\"\") should be nil")
+	}
+}
@@ -0,0 +1,33 @@
+package upload
+
+import "time"
+
+// Upload purposes
+const (
+	PurposeGeneral = "general"
+	PurposeLogo    = "logo"
+)
+
+// Upload records a file stored in blob storage and the tenant that owns it.
+// Only logos attached to a tenant are public; everything else, including a
+// logo that is uploaded but not yet attached, is visible to the owning tenant.
+type Upload struct {
+	ID         string `gorm:"type:char(36);primaryKey" json:"id"`
+	TenantID   string `gorm:"type:char(36);not null;index:idx_uploads_tenant_purpose,priority:1" json:"tenant_id"`
+	UploadedBy string `gorm:"column:uploaded_by;size:190;not null" json:"uploaded_by"`
+	StorageKey string `gorm:"column:storage_key;size:255;not null;uniqueIndex" json:"-"`
+	Filename   string `gorm:"size:255;not null" json:"filename"`
+	MimeType   string `gorm:"column:mime_type;size:127;not null" json:"mime_type"`
+	Size       int64  `gorm:"not null" json:"size"`
+	Checksum   string `gorm:"type:char(64);not null" json:"checksum"` // hex SHA-256
+	Purpose    string `gorm:"size:32;not null;default:general;index:idx_uploads_tenant_purpose,priority:2" json:"purpose"`
+	IsPublic   bool   `gorm:"column:is_public;not null;default:false" json:"is_public"`
+	// Variants lists the generated logo sizes stored next to the file.
+	Variants  []string  `gorm:"type:json;serializer:json" json:"variants,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Upload) TableName() string {
+	return "uploads"
+}
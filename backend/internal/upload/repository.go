@@ -0,0 +1,59 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Repository defines the interface for upload metadata access
+type Repository interface {
+	Create(ctx context.Context, upload *Upload) error
+	FindByID(ctx context.Context, id string) (*Upload, error)
+	// FindByPurposeBefore returns uploads with the given purpose created before t.
+	FindByPurposeBefore(ctx context.Context, purpose string, t time.Time) ([]*Upload, error)
+	// MarkPublic flags an upload as servable without authentication.
+	MarkPublic(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+}
+
+type gormRepository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new upload repository
+func NewRepository(db *gorm.DB) Repository {
+	return &gormRepository{db: db}
+}
+
+func (r *gormRepository) Create(ctx context.Context, upload *Upload) error {
+	return r.db.WithContext(ctx).Create(upload).Error
+}
+
+func (r *gormRepository) FindByID(ctx context.Context, id string) (*Upload, error) {
+	var upload Upload
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&upload).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &upload, nil
+}
+
+func (r *gormRepository) FindByPurposeBefore(ctx context.Context, purpose string, t time.Time) ([]*Upload, error) {
+	var uploads []*Upload
+	err := r.db.WithContext(ctx).Where("purpose = ? AND created_at < ?", purpose, t).Find(&uploads).Error
+	return uploads, err
+}
+
+func (r *gormRepository) MarkPublic(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&Upload{}).Where("id = ?", id).Update("is_public", true).Error
+}
+
+func (r *gormRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&Upload{}, "id = ?", id).Error
+}
@@ -0,0 +1,353 @@
+package upload
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/storage"
+	"werk-ticketing/internal/tenant"
+)
+
+// publicPathPrefix is the route serving public uploads (attached logos).
+const publicPathPrefix = "/uploads/"
+
+// StoreInput describes a file to store. Body must yield exactly Size bytes.
+type StoreInput struct {
+	TenantID   string
+	UploadedBy string
+	Filename   string
+	MimeType   string
+	Ext        string
+	Purpose    string
+	Size       int64
+	Body       io.Reader
+}
+
+// File is an opened upload, or one of its logo variants.
+type File struct {
+	Upload   *Upload
+	Body     io.ReadCloser
+	Size     int64
+	MimeType string
+	ETag     string
+}
+
+// Service manages stored files and their metadata.
+type Service interface {
+	// Store saves a file. Logos are sanitized and resized first, other SVG
+	// images sanitized, and both may fail with ErrInvalidImage.
+	Store(ctx context.Context, in StoreInput) (*Upload, error)
+	// Open returns the upload content, or the named variant when it exists;
+	// visibility is checked by the caller. It returns nil (and no error) when
+	// the upload does not exist.
+	Open(ctx context.Context, id, variant string) (*File, error)
+	// URL returns the stable public URL for logos and public uploads and a
+	// signed, time-limited URL for other private ones. A logo's public URL
+	// only serves the file once PublishLogo has been called for it.
+	URL(ctx context.Context, upload *Upload) (string, error)
+	// LogoURLs returns the URL of each standard logo size for a tenant LogoURL.
+	LogoURLs(url string) map[string]string
+	// PublishLogo makes the uploaded logo behind url public when a tenant
+	// starts using it. External URLs are left alone; URLs of uploads that are
+	// not logos uploaded by tenantID fail with tenant.ErrInvalidLogo.
+	PublishLogo(ctx context.Context, tenantID, url string) error
+	// ReleaseLogo deletes the logo behind url unless a tenant still uses it.
+	ReleaseLogo(ctx context.Context, url string)
+	// PruneOrphanLogos deletes logos older than the grace period that no
+	// tenant references, e.g. uploads from an abandoned branding form.
+	PruneOrphanLogos(ctx context.Context) (int, error)
+}
+
+type service struct {
+	repo    Repository
+	blob    storage.Blob
+	tenants tenant.Repository
+	logger  *logrus.Logger
+}
+
+// NewService creates a new upload service.
+func NewService(repo Repository, blob storage.Blob, tenants tenant.Repository, logger *logrus.Logger) Service {
+	return &service{repo: repo, blob: blob, tenants: tenants, logger: logger}
+}
+
+func (s *service) Store(ctx context.Context, in StoreInput) (*Upload, error) {
+	var variants map[string][]byte
+	switch {
+	case in.Purpose == PurposeLogo:
+		data, err := io.ReadAll(io.LimitReader(in.Body, in.Size))
+		if err != nil {
+			return nil, err
+		}
+		logo, err := processLogo(data, in.MimeType)
+		if err != nil {
+			return nil, err
+		}
+		in.Body, in.Size = bytes.NewReader(logo.data), int64(len(logo.data))
+		in.MimeType, in.Ext = logo.mimeType, logo.ext
+		variants = logo.variants
+	case in.MimeType == "image/svg+xml":
+		// Any SVG may end up opened directly in a browser, so scripts are
+		// stripped whatever the purpose.
+		data, err := io.ReadAll(io.LimitReader(in.Body, in.Size))
+		if err != nil {
+			return nil, err
+		}
+		clean, err := sanitizeSVG(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+		}
+		in.Body, in.Size = bytes.NewReader(clean), int64(len(clean))
+	}
+
+	id := uuid.New().String()
+	key, err := storage.TenantKey(in.TenantID, "uploads", id+in.Ext)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	if err := s.blob.Put(ctx, key, io.TeeReader(in.Body, hash), in.Size, in.MimeType); err != nil {
+		return nil, err
+	}
+
+	purpose := in.Purpose
+	if purpose == "" {
+		purpose = PurposeGeneral
+	}
+	upload := &Upload{
+		ID:         id,
+		TenantID:   in.TenantID,
+		UploadedBy: in.UploadedBy,
+		StorageKey: key,
+		Filename:   in.Filename,
+		MimeType:   in.MimeType,
+		Size:       in.Size,
+		Checksum:   hex.EncodeToString(hash.Sum(nil)),
+		Purpose:    purpose,
+	}
+
+	for _, v := range logoVariants {
+		data, ok := variants[v.name]
+		if !ok {
+			continue
+		}
+		if err := s.blob.Put(ctx, variantKey(key, v.name), bytes.NewReader(data), int64(len(data)), "image/png"); err != nil {
+			s.deleteBlobs(ctx, upload)
+			return nil, err
+		}
+		upload.Variants = append(upload.Variants, v.name)
+	}
+
+	if err := s.repo.Create(ctx, upload); err != nil {
+		s.deleteBlobs(ctx, upload)
+		return nil, err
+	}
+	return upload, nil
+}
+
+func (s *service) Open(ctx context.Context, id, variant string) (*File, error) {
+	upload, err := s.repo.FindByID(ctx, id)
+	if err != nil || upload == nil {
+		return nil, err
+	}
+
+	if slices.Contains(upload.Variants, variant) {
+		body, obj, err := s.blob.Get(ctx, variantKey(upload.StorageKey, variant))
+		if err != nil {
+			return nil, err
+		}
+		return &File{Upload: upload, Body: body, Size: obj.Size, MimeType: "image/png", ETag: upload.Checksum + "-" + variant}, nil
+	}
+
+	body, _, err := s.blob.Get(ctx, upload.StorageKey)
+	if err != nil {
+		return nil, err
+	}
+	return &File{Upload: upload, Body: body, Size: upload.Size, MimeType: upload.MimeType, ETag: upload.Checksum}, nil
+}
+
+func (s *service) URL(ctx context.Context, upload *Upload) (string, error) {
+	if upload.IsPublic || upload.Purpose == PurposeLogo {
+		return publicPathPrefix + upload.ID, nil
+	}
+	return s.blob.SignedURL(ctx, upload.StorageKey, constants.SignedURLTTL)
+}
+
+func (s *service) LogoURLs(url string) map[string]string {
+	if url == "" {
+		return nil
+	}
+	urls := map[string]string{LogoOriginal: url}
+	if uploadIDFromURL(url) == "" {
+		// External logo: the same image serves every size.
+		for _, v := range logoVariants {
+			urls[v.name] = url
+		}
+		return urls
+	}
+
+	base, _, _ := strings.Cut(url, "?")
+	urls[LogoOriginal] = base
+	for _, v := range logoVariants {
+		urls[v.name] = base + "?size=" + v.name
+	}
+	return urls
+}
+
+func (s *service) PublishLogo(ctx context.Context, tenantID, url string) error {
+	id := uploadIDFromURL(url)
+	if id == "" {
+		return nil
+	}
+
+	upload, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if upload == nil || upload.Purpose != PurposeLogo || upload.TenantID != tenantID {
+		return tenant.ErrInvalidLogo
+	}
+	if upload.IsPublic {
+		return nil
+	}
+	return s.repo.MarkPublic(ctx, id)
+}
+
+func (s *service) ReleaseLogo(ctx context.Context, url string) {
+	id := uploadIDFromURL(url)
+	if id == "" {
+		return
+	}
+
+	upload, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		s.logger.WithError(err).WithField("upload_id", id).Warn("failed to look up released logo")
+		return
+	}
+	if upload == nil || upload.Purpose != PurposeLogo {
+		return
+	}
+
+	referenced, err := s.referencedLogos(ctx)
+	if err != nil {
+		s.logger.WithError(err).Warn("failed to list tenant logos")
+		return
+	}
+	if referenced[id] {
+		return
+	}
+	s.remove(ctx, upload)
+}
+
+func (s *service) PruneOrphanLogos(ctx context.Context) (int, error) {
+	candidates, err := s.repo.FindByPurposeBefore(ctx, PurposeLogo, time.Now().Add(-constants.OrphanUploadGracePeriod))
+	if err != nil || len(candidates) == 0 {
+		return 0, err
+	}
+
+	referenced, err := s.referencedLogos(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, upload := range candidates {
+		if !referenced[upload.ID] && s.remove(ctx, upload) {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// referencedLogos returns the upload IDs used as a LogoURL by any tenant.
+func (s *service) referencedLogos(ctx context.Context) (map[string]bool, error) {
+	tenants, err := s.tenants.FindAllIncludingInactive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(tenants))
+	for _, t := range tenants {
+		if id := uploadIDFromURL(t.LogoURL); id != "" {
+			ids[id] = true
+		}
+	}
+	return ids, nil
+}
+
+func (s *service) remove(ctx context.Context, upload *Upload) bool {
+	fields := logrus.Fields{"upload_id": upload.ID, "tenant_id": upload.TenantID, "key": upload.StorageKey}
+	if err := s.deleteBlobs(ctx, upload); err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("failed to delete upload blob")
+		return false
+	}
+	if err := s.repo.Delete(ctx, upload.ID); err != nil {
+		s.logger.WithError(err).WithFields(fields).Warn("failed to delete upload record")
+		return false
+	}
+	s.logger.WithFields(fields).Info("orphaned upload removed")
+	return true
+}
+
+// deleteBlobs removes the stored file and its variants.
+func (s *service) deleteBlobs(ctx context.Context, upload *Upload) error {
+	var firstErr error
+	for _, name := range upload.Variants {
+		if err := s.blob.Delete(ctx, variantKey(upload.StorageKey, name)); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if err := s.blob.Delete(ctx, upload.StorageKey); err != nil && firstErr == nil {
+		firstErr = err
+	}
+	return firstErr
+}
+
+// variantKey derives the key of a logo variant from the original's key,
+// e.g. "t/uploads/<id>.png" -> "t/uploads/<id>-favicon.png".
+func variantKey(key, variant string) string {
+	return strings.TrimSuffix(key, path.Ext(key)) + "-" + variant + ".png"
+}
+
+// uploadIDFromURL extracts the upload ID from a public upload URL, relative
+// ("/uploads/<id>") or absolute.
+func uploadIDFromURL(url string) string {
+	_, rest, ok := strings.Cut(url, publicPathPrefix)
+	if !ok {
+		return ""
+	}
+	id, _, _ := strings.Cut(rest, "?")
+	if _, err := uuid.Parse(id); err != nil {
+		return ""
+	}
+	return id
+}
+
+// SweepOrphans prunes orphaned logos periodically until ctx is cancelled.
+func SweepOrphans(ctx context.Context, svc Service, logger *logrus.Logger) {
+	ticker := time.NewTicker(constants.OrphanUploadSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if removed, err := svc.PruneOrphanLogos(ctx); err != nil {
+				logger.WithError(err).Warn("orphaned upload sweep failed")
+			} else if removed > 0 {
+				logger.WithField("removed", removed).Info("orphaned uploads swept")
+			}
+		}
+	}
+}
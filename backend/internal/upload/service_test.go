@@ -0,0 +1,255 @@
+package upload
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/storage"
+	"werk-ticketing/internal/tenant"
+)
+
+type memoryRepo struct {
+	uploads map[string]*Upload
+}
+
+func (r *memoryRepo) Create(ctx context.Context, u *Upload) error {
+	if u.CreatedAt.IsZero() {
+		u.CreatedAt = time.Now()
+	}
+	r.uploads[u.ID] = u
+	return nil
+}
+
+func (r *memoryRepo) FindByID(ctx context.Context, id string) (*Upload, error) {
+	return r.uploads[id], nil
+}
+
+func (r *memoryRepo) FindByPurposeBefore(ctx context.Context, purpose string, t time.Time) ([]*Upload, error) {
+	var out []*Upload
+	for _, u := range r.uploads {
+		if u.Purpose == purpose && u.CreatedAt.Before(t) {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func (r *memoryRepo) MarkPublic(ctx context.Context, id string) error {
+	r.uploads[id].IsPublic = true
+	return nil
+}
+
+func (r *memoryRepo) Delete(ctx context.Context, id string) error {
+	delete(r.uploads, id)
+	return nil
+}
+
+// memoryTenants implements the tenant.Repository methods used by the service.
+type memoryTenants struct {
+	tenant.Repository
+	tenants []*tenant.Tenant
+}
+
+func (r *memoryTenants) FindAllIncludingInactive(ctx context.Context) ([]*tenant.Tenant, error) {
+	return r.tenants, nil
+}
+
+type uploadFixture struct {
+	service Service
+	repo    *memoryRepo
+	blob    storage.Blob
+	tenants *memoryTenants
+}
+
+func newUploadFixture(t *testing.T) *uploadFixture {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	f := &uploadFixture{
+		repo:    &memoryRepo{uploads: map[string]*Upload{}},
+		blob:    storage.NewLocal(t.TempDir(), "secret", "/files"),
+		tenants: &memoryTenants{},
+	}
+	f.service = NewService(f.repo, f.blob, f.tenants, logger)
+	return f
+}
+
+// store saves content as an upload; logos are wrapped in a minimal SVG so
+// they pass the logo pipeline.
+func (f *uploadFixture) store(t *testing.T, tenantID, purpose, content string) *Upload {
+	t.Helper()
+	in := StoreInput{
+		TenantID: tenantID, UploadedBy: "admin@example.com", Filename: "notes.txt",
+		MimeType: "text/plain", Ext: ".txt", Purpose: purpose,
+	}
+	if purpose == PurposeLogo {
+		content = `<svg xmlns="http://www.w3.org/2000/svg"><title>` + content + `</title></svg>`
+		in.Filename, in.MimeType, in.Ext = "logo.svg", "image/svg+xml", ".svg"
+	}
+	in.Size, in.Body = int64(len(content)), strings.NewReader(content)
+	u, err := f.service.Store(context.Background(), in)
+	if err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	return u
+}
+
+func TestStoreRecordsMetadata(t *testing.T) {
+	f := newUploadFixture(t)
+	u := f.store(t, "tenant-1", PurposeLogo, "acme")
+
+	if u.IsPublic || u.Size == 0 || len(u.Checksum) != 64 || !strings.HasPrefix(u.StorageKey, "tenant-1/uploads/") {
+		t.Errorf("Store = %+v", u)
+	}
+	url, _ := f.service.URL(context.Background(), u)
+	if url != "/uploads/"+u.ID {
+		t.Errorf("URL = %q", url)
+	}
+
+	private := f.store(t, "tenant-1", "", "notes")
+	if private.IsPublic || private.Purpose != PurposeGeneral {
+		t.Errorf("private upload = %+v", private)
+	}
+}
+
+func TestPublishLogo(t *testing.T) {
+	f := newUploadFixture(t)
+	ctx := context.Background()
+	logo := f.store(t, "tenant-1", PurposeLogo, "acme")
+	general := f.store(t, "tenant-1", PurposeGeneral, "notes")
+
+	if err := f.service.PublishLogo(ctx, "tenant-1", "/uploads/"+general.ID); err != tenant.ErrInvalidLogo {
+		t.Errorf("PublishLogo(general upload) = %v, want ErrInvalidLogo", err)
+	}
+	if err := f.service.PublishLogo(ctx, "tenant-2", "/uploads/"+logo.ID); err != tenant.ErrInvalidLogo || f.repo.uploads[logo.ID].IsPublic {
+		t.Errorf("PublishLogo(other tenant's logo) = %v, want ErrInvalidLogo", err)
+	}
+	if err := f.service.PublishLogo(ctx, "tenant-1", "https://cdn.example.com/logo.png"); err != nil {
+		t.Errorf("PublishLogo(external) = %v", err)
+	}
+	if err := f.service.PublishLogo(ctx, "tenant-1", "/uploads/"+logo.ID); err != nil || !f.repo.uploads[logo.ID].IsPublic {
+		t.Errorf("PublishLogo(logo) = %v, public = %v", err, f.repo.uploads[logo.ID].IsPublic)
+	}
+	if f.repo.uploads[general.ID].IsPublic {
+		t.Error("general upload made public")
+	}
+}
+
+func TestReleaseLogoKeepsReferencedFiles(t *testing.T) {
+	f := newUploadFixture(t)
+	ctx := context.Background()
+	old := f.store(t, "tenant-1", PurposeLogo, "old")
+	shared := f.store(t, "tenant-1", PurposeLogo, "shared")
+	f.tenants.tenants = []*tenant.Tenant{{ID: "tenant-2", LogoURL: "https://portal.example.com/uploads/" + shared.ID}}
+
+	f.service.ReleaseLogo(ctx, "/uploads/"+old.ID)
+	f.service.ReleaseLogo(ctx, "/uploads/"+shared.ID)
+
+	if _, ok := f.repo.uploads[old.ID]; ok {
+		t.Error("released logo still recorded")
+	}
+	if _, _, err := f.blob.Get(ctx, old.StorageKey); err != storage.ErrNotFound {
+		t.Errorf("released logo blob error = %v, want ErrNotFound", err)
+	}
+	if _, ok := f.repo.uploads[shared.ID]; !ok {
+		t.Error("logo used by another tenant was deleted")
+	}
+}
+
+func TestPruneOrphanLogos(t *testing.T) {
+	f := newUploadFixture(t)
+	orphan := f.store(t, "tenant-1", PurposeLogo, "orphan")
+	inUse := f.store(t, "tenant-1", PurposeLogo, "in use")
+	fresh := f.store(t, "tenant-1", PurposeLogo, "fresh")
+	orphan.CreatedAt = time.Now().Add(-48 * time.Hour)
+	inUse.CreatedAt = orphan.CreatedAt
+	f.tenants.tenants = []*tenant.Tenant{{ID: "tenant-1", LogoURL: "/uploads/" + inUse.ID}}
+
+	removed, err := f.service.PruneOrphanLogos(context.Background())
+	if err != nil || removed != 1 {
+		t.Fatalf("PruneOrphanLogos = %d, %v", removed, err)
+	}
+	if _, ok := f.repo.uploads[orphan.ID]; ok {
+		t.Error("orphan logo kept")
+	}
+	if f.repo.uploads[inUse.ID] == nil || f.repo.uploads[fresh.ID] == nil {
+		t.Error("referenced or recent logo removed")
+	}
+}
+
+func TestGetEnforcesTenantVisibility(t *testing.T) {
+	f := newUploadFixture(t)
+	private := f.store(t, "tenant-1", PurposeGeneral, "secret")
+	logo := f.store(t, "tenant-1", PurposeLogo, "logo")
+
+	gin.SetMode(gin.TestMode)
+	h := NewHandler(f.service, f.blob, nil, logrus.New())
+	serve := func(handler gin.HandlerFunc, tenantID, id string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/uploads/:id", func(c *gin.Context) {
+			if tenantID != "" {
+				c.Set("tenant_id", tenantID)
+			}
+			handler(c)
+		})
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/uploads/"+id, nil))
+		return rec
+	}
+
+	if rec := serve(h.Get, "tenant-1", private.ID); rec.Code != http.StatusOK || rec.Body.String() != "secret" {
+		t.Errorf("owner GET = %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := serve(h.Get, "tenant-2", private.ID); rec.Code != http.StatusNotFound {
+		t.Errorf("other tenant GET = %d, want 404", rec.Code)
+	}
+	if rec := serve(h.GetPublic, "", private.ID); rec.Code != http.StatusNotFound {
+		t.Errorf("public GET of private file = %d, want 404", rec.Code)
+	}
+	if rec := serve(h.GetPublic, "", logo.ID); rec.Code != http.StatusNotFound {
+		t.Errorf("public GET of unattached logo = %d, want 404", rec.Code)
+	}
+	if err := f.service.PublishLogo(context.Background(), "tenant-1", "/uploads/"+logo.ID); err != nil {
+		t.Fatalf("PublishLogo: %v", err)
+	}
+	if rec := serve(h.GetPublic, "", logo.ID); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "<title>logo</title>") {
+		t.Errorf("public GET of logo = %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestServeFileKeepsFilesInert(t *testing.T) {
+	f := newUploadFixture(t)
+	notes := f.store(t, "tenant-1", PurposeGeneral, "<script>alert(1)</script>")
+	url, err := f.blob.SignedURL(context.Background(), notes.StorageKey, time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/files/*key", NewHandler(f.service, f.blob, nil, logrus.New()).ServeFile)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, url, nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET signed URL = %d %q", rec.Code, rec.Body.String())
+	}
+	for key, want := range map[string]string{
+		"X-Content-Type-Options":  "nosniff",
+		"Content-Security-Policy": "default-src 'none'; sandbox",
+		"Content-Disposition":     "attachment; filename=" + notes.ID + ".txt",
+	} {
+		if got := rec.Header().Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
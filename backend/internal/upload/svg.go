@@ -0,0 +1,166 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const svgNamespace = "http://www.w3.org/2000/svg"
+
+// svgElements are the SVG elements kept by sanitizeSVG. Scripts, styles,
+// foreignObject, images and animation elements are dropped with their
+// content.
+var svgElements = map[string]bool{
+	"svg": true, "g": true, "defs": true, "symbol": true, "use": true,
+	"title": true, "desc": true,
+	"path": true, "rect": true, "circle": true, "ellipse": true,
+	"line": true, "polyline": true, "polygon": true,
+	"text": true, "tspan": true,
+	"linearGradient": true, "radialGradient": true, "stop": true,
+	"clipPath": true, "mask": true, "pattern": true,
+}
+
+// svgAttributes are the attributes kept by sanitizeSVG; event handlers and
+// the style attribute are never kept.
+var svgAttributes = map[string]bool{
+	"id": true, "class": true, "viewBox": true, "width": true, "height": true,
+	"x": true, "y": true, "x1": true, "y1": true, "x2": true, "y2": true,
+	"cx": true, "cy": true, "r": true, "rx": true, "ry": true, "fx": true, "fy": true,
+	"d": true, "points": true, "transform": true, "preserveAspectRatio": true,
+	"fill": true, "fill-opacity": true, "fill-rule": true, "opacity": true,
+	"stroke": true, "stroke-width": true, "stroke-opacity": true, "stroke-linecap": true,
+	"stroke-linejoin": true, "stroke-miterlimit": true, "stroke-dasharray": true, "stroke-dashoffset": true,
+	"clip-path": true, "clip-rule": true, "mask": true,
+	"offset": true, "stop-color": true, "stop-opacity": true,
+	"gradientUnits": true, "gradientTransform": true, "spreadMethod": true,
+	"patternUnits": true, "patternTransform": true, "clipPathUnits": true, "maskUnits": true,
+	"font-family": true, "font-size": true, "font-weight": true, "font-style": true,
+	"text-anchor": true, "dominant-baseline": true, "letter-spacing": true,
+	"dx": true, "dy": true, "href": true, "version": true,
+}
+
+// sanitizeSVG rewrites an SVG document keeping only allow-listed elements
+// and attributes. References may only point inside the document ("#id"),
+// so the result cannot run scripts or load external resources.
+func sanitizeSVG(data []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = true
+
+	var out bytes.Buffer
+	encoder := xml.NewEncoder(&out)
+
+	depth := 0    // open elements written to the output
+	skipping := 0 // nesting inside a dropped element
+	sawRoot := false
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid SVG: %w", err)
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if skipping > 0 {
+				skipping++
+				continue
+			}
+			if !sawRoot {
+				if t.Name.Local != "svg" {
+					return nil, fmt.Errorf("invalid SVG: root element is %s", t.Name.Local)
+				}
+				sawRoot = true
+			} else if depth == 0 {
+				return nil, fmt.Errorf("invalid SVG: multiple root elements")
+			}
+			if (t.Name.Space != "" && t.Name.Space != svgNamespace) || !svgElements[t.Name.Local] {
+				skipping = 1
+				continue
+			}
+
+			start := xml.StartElement{Name: xml.Name{Local: t.Name.Local}, Attr: sanitizeSVGAttrs(t.Attr)}
+			if depth == 0 {
+				start.Attr = append([]xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: svgNamespace}}, start.Attr...)
+			}
+			if err := encoder.EncodeToken(start); err != nil {
+				return nil, err
+			}
+			depth++
+		case xml.EndElement:
+			if skipping > 0 {
+				skipping--
+				continue
+			}
+			if err := encoder.EncodeToken(xml.EndElement{Name: xml.Name{Local: t.Name.Local}}); err != nil {
+				return nil, err
+			}
+			depth--
+		case xml.CharData:
+			if skipping == 0 && depth > 0 {
+				if err := encoder.EncodeToken(t.Copy()); err != nil {
+					return nil, err
+				}
+			}
+		}
+		// Comments, processing instructions and directives (DOCTYPE,
+		// entity declarations) are dropped.
+	}
+
+	if !sawRoot || depth != 0 {
+		return nil, fmt.Errorf("invalid SVG: incomplete document")
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func sanitizeSVGAttrs(attrs []xml.Attr) []xml.Attr {
+	kept := make([]xml.Attr, 0, len(attrs))
+	for _, attr := range attrs {
+		name := attr.Name.Local
+		// xlink:href is kept as plain href; other namespaced attributes go.
+		if attr.Name.Space != "" && !(attr.Name.Space == "http://www.w3.org/1999/xlink" && name == "href") {
+			continue
+		}
+		if !svgAttributes[name] || strings.HasPrefix(strings.ToLower(name), "on") {
+			continue
+		}
+
+		value := strings.TrimSpace(attr.Value)
+		lower := strings.ToLower(value)
+		if name == "href" && !strings.HasPrefix(value, "#") {
+			continue
+		}
+		if strings.Contains(lower, "javascript:") || strings.Contains(lower, "data:") {
+			continue
+		}
+		if hasExternalURL(lower) {
+			continue
+		}
+		kept = append(kept, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	}
+	return kept
+}
+
+// hasExternalURL reports whether a value contains a url() reference that
+// does not point at an element of the same document.
+func hasExternalURL(value string) bool {
+	value = strings.ReplaceAll(value, " ", "")
+	for {
+		i := strings.Index(value, "url(")
+		if i < 0 {
+			return false
+		}
+		value = strings.TrimLeft(value[i+len("url("):], `'"`)
+		if !strings.HasPrefix(value, "#") {
+			return true
+		}
+	}
+}
@@ -6,18 +6,21 @@ import (
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/response"
 )
 
 // Handler handles HTTP requests for user operations
 type Handler struct {
-	repo Repository
+	repo  Repository
+	audit audit.Recorder
 }
 
 // NewHandler creates a new user handler
-func NewHandler(repo Repository) *Handler {
+func NewHandler(repo Repository, auditLog audit.Recorder) *Handler {
 	return &Handler{
-		repo: repo,
+		repo:  repo,
+		audit: auditLog,
 	}
 }
 
@@ -70,14 +73,19 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	changes := audit.Changes{}
+
 	// Update fields if provided
-	if req.Name != nil && *req.Name != "" {
+	if req.Name != nil && *req.Name != "" && *req.Name != user.Name {
+		changes["name"] = audit.Change{Before: user.Name, After: *req.Name}
 		user.Name = *req.Name
 	}
-	if req.LastName != nil && *req.LastName != "" {
+	if req.LastName != nil && *req.LastName != "" && *req.LastName != user.LastName {
+		changes["lastname"] = audit.Change{Before: user.LastName, After: *req.LastName}
 		user.LastName = *req.LastName
 	}
-	if req.Password != nil && *req.Password != "" {
+	passwordChanged := req.Password != nil && *req.Password != ""
+	if passwordChanged {
 		// Hash the new password
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*req.Password), bcrypt.DefaultCost)
 		if err != nil {
@@ -92,6 +100,12 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 		response.Error(c, http.StatusInternalServerError, "Failed to update profile")
 		return
 	}
+	if len(changes) > 0 {
+		h.record(c, user, audit.ActionProfileUpdated, changes)
+	}
+	if passwordChanged {
+		h.record(c, user, audit.ActionPasswordChanged, audit.Changes{}.Secret("password"))
+	}
 
 	// Return updated user data (without password)
 	userResp := UserResponse{
@@ -104,3 +118,16 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 
 	response.Success(c, http.StatusOK, userResp)
 }
+
+// record appends a change the user made to their own account to the audit log.
+func (h *Handler) record(c *gin.Context, u *User, action string, changes audit.Changes) {
+	h.audit.Record(c.Request.Context(), &audit.Event{
+		TenantID:   u.TenantID,
+		ActorID:    u.ID,
+		ActorEmail: u.Email,
+		Action:     action,
+		TargetType: audit.TargetUser,
+		TargetID:   u.ID,
+		Changes:    changes,
+	})
+}
@@ -13,7 +13,8 @@ type User struct {
 	LastName      string    `gorm:"size:100;not null;column:last_name"` // Explicit column name to match migration
 	Email         string    `gorm:"size:190;not null;uniqueIndex:idx_users_tenant_email,priority:2"`
 	Password      string    `gorm:"size:255;not null"`
-	InvGateUserID int       `gorm:"not null;column:invgate_user_id"`
+	InvGateUserID int       `gorm:"not null;column:invgate_user_id;index:idx_users_invgate_user_id"`
+	Role          string    `gorm:"size:16;not null;default:user"`
 	CreatedBy     string    `gorm:"size:190;column:created_by"` // Email of user who created this record
 	UpdatedBy     string    `gorm:"size:190;column:updated_by"` // Email of user who last updated this record
 	CreatedAt     time.Time `gorm:"autoCreateTime"`
@@ -23,6 +24,24 @@ type User struct {
 	Tenant Tenant `gorm:"foreignKey:TenantID;constraint:OnDelete:RESTRICT"`
 }
 
+// User roles, from least to most privileged. Registration always creates
+// RoleUser; admins are promoted directly in the database.
+const (
+	// RoleUser can only manage their own tickets and profile.
+	RoleUser = "user"
+	// RoleAdmin manages their tenant's email templates, webhooks and audit log.
+	RoleAdmin = "admin"
+	// RoleOperator additionally creates and manages tenants.
+	RoleOperator = "operator"
+)
+
+var roleRank = map[string]int{RoleUser: 1, RoleAdmin: 2, RoleOperator: 3}
+
+// HasRole reports whether the user's role grants at least role.
+func (u *User) HasRole(role string) bool {
+	return roleRank[u.Role] >= roleRank[role] && roleRank[role] > 0
+}
+
 // TableName specifies the table name for GORM
 func (User) TableName() string {
 	return "users"
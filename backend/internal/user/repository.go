@@ -17,6 +17,9 @@ type Repository interface {
 	GetByID(ctx context.Context, tenantID, id string) (*User, error)
 	Update(ctx context.Context, tenantID string, user *User) error
 	Delete(ctx context.Context, tenantID, id string) error
+	// ListByInvGateUserID finds the local accounts of an InvGate user across
+	// all tenants. It is meant for background jobs with no tenant context.
+	ListByInvGateUserID(ctx context.Context, invGateUserID int) ([]*User, error)
 	// Password reset methods
 	CreateResetToken(ctx context.Context, tenantID string, token *ResetToken) error
 	GetResetToken(ctx context.Context, token string) (*ResetToken, error)
@@ -127,6 +130,12 @@ func (r *gormRepository) Delete(ctx context.Context, tenantID, id string) error
 	return r.db.WithContext(ctx).Delete(&User{}, "tenant_id = ? AND id = ?", tenantID, id).Error
 }
 
+func (r *gormRepository) ListByInvGateUserID(ctx context.Context, invGateUserID int) ([]*User, error) {
+	var users []*User
+	err := r.db.WithContext(ctx).Where("invgate_user_id = ?", invGateUserID).Find(&users).Error
+	return users, err
+}
+
 // CreateResetToken creates a new password reset token
 func (r *gormRepository) CreateResetToken(ctx context.Context, tenantID string, token *ResetToken) error {
 	token.TenantID = tenantID // Ensure tenant_id is set
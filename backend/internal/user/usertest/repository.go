@@ -0,0 +1,82 @@
+// Package usertest provides an in-memory user.Repository for tests.
+package usertest
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"werk-ticketing/internal/user"
+)
+
+// Repository keeps users in memory. It implements the account methods of
+// user.Repository; the others panic through the embedded nil interface.
+type Repository struct {
+	user.Repository
+
+	mu    sync.Mutex
+	users map[string]*user.User
+}
+
+// NewRepository returns a repository holding copies of users.
+func NewRepository(users ...*user.User) *Repository {
+	r := &Repository{users: make(map[string]*user.User)}
+	for _, u := range users {
+		stored := *u
+		r.users[u.ID] = &stored
+	}
+	return r
+}
+
+// Create stores a copy of u with a new ID, rejecting an email already used
+// in the tenant like the database's unique index does.
+func (r *Repository) Create(ctx context.Context, tenantID string, u *user.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.users {
+		if existing.TenantID == tenantID && strings.EqualFold(existing.Email, u.Email) {
+			return &user.DuplicateKeyError{Field: "email", Value: u.Email}
+		}
+	}
+	u.ID = uuid.NewString()
+	u.TenantID = tenantID
+	stored := *u
+	r.users[u.ID] = &stored
+	return nil
+}
+
+// GetByEmail returns a copy of the tenant's user with email, or nil.
+func (r *Repository) GetByEmail(ctx context.Context, tenantID, email string) (*user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if u.TenantID == tenantID && strings.EqualFold(u.Email, email) {
+			found := *u
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetByID returns a copy of the tenant's user with id, or nil.
+func (r *Repository) GetByID(ctx context.Context, tenantID, id string) (*user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u := r.users[id]; u != nil && u.TenantID == tenantID {
+		found := *u
+		return &found, nil
+	}
+	return nil, nil
+}
+
+// Delete removes the user with id.
+func (r *Repository) Delete(ctx context.Context, tenantID, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if u := r.users[id]; u != nil && u.TenantID == tenantID {
+		delete(r.users, id)
+	}
+	return nil
+}
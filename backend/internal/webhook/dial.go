@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// errBlockedAddress is returned when a webhook URL points to the server's
+// own network.
+var errBlockedAddress = errors.New("address is not allowed for webhooks")
+
+// blockedIP reports whether ip is a loopback, private, link-local or other
+// non-public address. Webhook URLs are chosen by tenants, so sending to
+// such addresses would let them reach internal services.
+func blockedIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast()
+}
+
+// newTransport returns a transport that refuses to connect to blocked
+// addresses. The check runs on the address actually dialed, after DNS
+// resolution, so a host name that resolves to an internal address is
+// refused too.
+func newTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || blockedIP(ip) {
+				return fmt.Errorf("%w: %s", errBlockedAddress, host)
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would make the connection on our behalf, bypassing the check.
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
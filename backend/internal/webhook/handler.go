@@ -0,0 +1,267 @@
+package webhook
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"werk-ticketing/internal/constants"
+	"werk-ticketing/internal/errors"
+	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/response"
+	"werk-ticketing/internal/tenant"
+)
+
+// Handler handles HTTP requests for tenant webhook management
+type Handler struct {
+	service    Service
+	subs       SubscriptionRepository
+	deliveries DeliveryRepository
+}
+
+// NewHandler creates a new webhook handler
+func NewHandler(service Service, subs SubscriptionRepository, deliveries DeliveryRepository) *Handler {
+	return &Handler{service: service, subs: subs, deliveries: deliveries}
+}
+
+// ListEvents handles GET /admin/webhook-events
+func (h *Handler) ListEvents(c *gin.Context) {
+	response.Success(c, http.StatusOK, Events)
+}
+
+// ListSubscriptions handles GET /admin/tenants/:id/webhooks
+func (h *Handler) ListSubscriptions(c *gin.Context) {
+	t, ok := h.loadTenant(c)
+	if !ok {
+		return
+	}
+
+	subs, err := h.subs.ListByTenant(c.Request.Context(), t.ID)
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to list webhooks")
+		return
+	}
+	response.Success(c, http.StatusOK, subs)
+}
+
+// CreateSubscription handles POST /admin/tenants/:id/webhooks
+func (h *Handler) CreateSubscription(c *gin.Context) {
+	var req CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "url and events are required")
+		return
+	}
+	if !validateSubscription(c, req.URL, req.Events) {
+		return
+	}
+
+	t, ok := h.loadTenant(c)
+	if !ok {
+		return
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to generate webhook secret")
+			return
+		}
+	}
+
+	sub := &Subscription{
+		ID:          uuid.New().String(),
+		TenantID:    t.ID,
+		URL:         req.URL,
+		Secret:      secret,
+		Events:      req.Events,
+		Description: req.Description,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+	if err := h.subs.Create(c.Request.Context(), sub); err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to create webhook")
+		return
+	}
+	response.Success(c, http.StatusCreated, SubscriptionSecretResponse{Subscription: sub, Secret: secret})
+}
+
+// UpdateSubscription handles PUT /admin/tenants/:id/webhooks/:webhook_id
+func (h *Handler) UpdateSubscription(c *gin.Context) {
+	var req UpdateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "invalid request body")
+		return
+	}
+
+	sub, ok := h.loadSubscription(c)
+	if !ok {
+		return
+	}
+
+	if req.URL != "" {
+		sub.URL = req.URL
+	}
+	if req.Events != nil {
+		sub.Events = req.Events
+	}
+	if req.Description != nil {
+		sub.Description = *req.Description
+	}
+	if req.IsActive != nil {
+		sub.IsActive = *req.IsActive
+	}
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+	if !validateSubscription(c, sub.URL, sub.Events) {
+		return
+	}
+
+	if err := h.subs.Update(c.Request.Context(), sub); err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to update webhook")
+		return
+	}
+	if req.Secret != "" {
+		response.Success(c, http.StatusOK, SubscriptionSecretResponse{Subscription: sub, Secret: sub.Secret})
+		return
+	}
+	response.Success(c, http.StatusOK, sub)
+}
+
+// DeleteSubscription handles DELETE /admin/tenants/:id/webhooks/:webhook_id
+func (h *Handler) DeleteSubscription(c *gin.Context) {
+	sub, ok := h.loadSubscription(c)
+	if !ok {
+		return
+	}
+
+	if err := h.subs.Delete(c.Request.Context(), sub.ID); err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to delete webhook")
+		return
+	}
+	response.Success(c, http.StatusOK, gin.H{"message": "webhook deleted"})
+}
+
+// ListDeliveries handles GET /admin/tenants/:id/webhooks/:webhook_id/deliveries?status=
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", StatusPending, StatusDelivered, StatusFailed:
+	default:
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "status must be pending, delivered or failed")
+		return
+	}
+
+	sub, ok := h.loadSubscription(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := h.deliveries.List(c.Request.Context(), sub.ID, status, constants.WebhookDeliveryListLimit)
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to list webhook deliveries")
+		return
+	}
+	response.Success(c, http.StatusOK, deliveries)
+}
+
+// GetDelivery handles GET /admin/webhook-deliveries/:id
+func (h *Handler) GetDelivery(c *gin.Context) {
+	delivery, err := h.deliveries.FindByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to get webhook delivery")
+		return
+	}
+	if delivery == nil || delivery.TenantID != middleware.GetTenantID(c) {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "webhook delivery not found")
+		return
+	}
+	response.Success(c, http.StatusOK, delivery)
+}
+
+// Redeliver handles POST /admin/webhook-deliveries/:id/redeliver
+func (h *Handler) Redeliver(c *gin.Context) {
+	delivery, err := h.service.Redeliver(c.Request.Context(), middleware.GetTenantID(c), c.Param("id"))
+	if err != nil {
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+		} else {
+			response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to redeliver webhook")
+		}
+		return
+	}
+	response.Success(c, http.StatusAccepted, delivery)
+}
+
+// loadTenant returns the caller's tenant, which must be the tenant in the
+// path; other tenants are reported as not found.
+func (h *Handler) loadTenant(c *gin.Context) (*tenant.Tenant, bool) {
+	t := middleware.GetTenant(c)
+	if t == nil || t.ID != c.Param("id") {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "tenant not found")
+		return nil, false
+	}
+	return t, true
+}
+
+// loadSubscription loads the webhook named in the path, which must belong
+// to the caller's tenant and the tenant in the path.
+func (h *Handler) loadSubscription(c *gin.Context) (*Subscription, bool) {
+	tenantID := middleware.GetTenantID(c)
+	if tenantID == "" || tenantID != c.Param("id") {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "webhook not found")
+		return nil, false
+	}
+	sub, err := h.subs.FindByID(c.Request.Context(), c.Param("webhook_id"))
+	if err != nil {
+		response.ErrorWithCode(c, http.StatusInternalServerError, errors.ErrCodeInternal, "failed to get webhook")
+		return nil, false
+	}
+	if sub == nil || sub.TenantID != tenantID {
+		response.ErrorWithCode(c, http.StatusNotFound, errors.ErrCodeNotFound, "webhook not found")
+		return nil, false
+	}
+	return sub, true
+}
+
+// validateSubscription checks a subscription's URL and events. It writes
+// the error response and returns false when they are invalid.
+func validateSubscription(c *gin.Context, rawURL string, events []string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "url must be an absolute http or https URL")
+		return false
+	}
+	// Addresses are checked again when connecting, after DNS resolution.
+	if ip := net.ParseIP(u.Hostname()); u.Hostname() == "localhost" || (ip != nil && blockedIP(ip)) {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "url must not point to a private, loopback or link-local address")
+		return false
+	}
+	if len(events) == 0 {
+		response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput, "at least one event is required")
+		return false
+	}
+	for _, e := range events {
+		if !IsEvent(e) {
+			response.ErrorWithCode(c, http.StatusBadRequest, errors.ErrCodeInvalidInput,
+				"unknown event "+e+"; supported events are "+strings.Join(Events, ", "))
+			return false
+		}
+	}
+	return true
+}
+
+// generateSecret returns a random 256-bit signing secret.
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
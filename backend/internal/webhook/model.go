@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event types tenants can subscribe to.
+const (
+	EventTicketCreated    = "ticket.created"
+	EventCommentAdded     = "comment.added"
+	EventSolutionAccepted = "solution.accepted"
+	EventSolutionRejected = "solution.rejected"
+)
+
+// Events lists every event type in the order shown to admins.
+var Events = []string{EventTicketCreated, EventCommentAdded, EventSolutionAccepted, EventSolutionRejected}
+
+// IsEvent reports whether name is a known event type.
+func IsEvent(name string) bool {
+	for _, e := range Events {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusDelivered = "delivered"
+	StatusFailed    = "failed" // gave up after too many attempts
+)
+
+// Subscription sends a tenant's events of the listed types to a URL.
+type Subscription struct {
+	ID          string   `gorm:"type:char(36);primaryKey" json:"id"`
+	TenantID    string   `gorm:"type:char(36);not null;index" json:"tenant_id"`
+	URL         string   `gorm:"size:500;not null" json:"url"`
+	Secret      string   `gorm:"size:255;not null" json:"-"` // Never expose in JSON
+	Events      []string `gorm:"type:json;serializer:json" json:"events"`
+	Description string   `gorm:"size:255" json:"description,omitempty"`
+	IsActive    bool     `gorm:"not null;default:true" json:"is_active"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Wants reports whether the subscription receives events of the given type.
+func (s *Subscription) Wants(event string) bool {
+	if !s.IsActive {
+		return false
+	}
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery is one event queued for, or sent to, one subscription. Rows are
+// kept after sending as the delivery log.
+type Delivery struct {
+	ID             string `gorm:"type:char(36);primaryKey" json:"id"`
+	SubscriptionID string `gorm:"type:char(36);not null;index" json:"subscription_id"`
+	TenantID       string `gorm:"type:char(36);not null;index" json:"tenant_id"`
+	Event          string `gorm:"size:64;not null" json:"event"`
+	// EventID is the same for every delivery of an event, redeliveries
+	// included, so receivers can drop duplicates.
+	EventID string          `gorm:"type:char(36);not null" json:"event_id"`
+	Payload json.RawMessage `gorm:"type:mediumtext" json:"payload"`
+	// RedeliveryOf is the delivery an admin asked to send again.
+	RedeliveryOf *string `gorm:"type:char(36)" json:"redelivery_of,omitempty"`
+
+	Status         string     `gorm:"size:16;not null;default:pending;index:idx_webhook_deliveries_due,priority:1" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"not null;index:idx_webhook_deliveries_due,priority:2" json:"next_attempt_at"`
+	LockedUntil    *time.Time `json:"-"`
+	ClaimToken     string     `gorm:"size:36;index" json:"-"`
+	LastError      string     `gorm:"type:text" json:"last_error,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	ResponseBody   string     `gorm:"type:text" json:"response_body,omitempty"`
+	DurationMS     int64      `gorm:"column:duration_ms" json:"duration_ms,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime;index" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Envelope is the JSON body sent for every event.
+type Envelope struct {
+	ID         string    `json:"id"`
+	Event      string    `json:"event"`
+	TenantID   string    `json:"tenant_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Data       EventData `json:"data"`
+}
+
+// EventData describes what happened to a ticket.
+type EventData struct {
+	TicketID   int    `json:"ticket_id"`
+	Title      string `json:"title,omitempty"`
+	CategoryID int    `json:"category_id,omitempty"`
+	PriorityID int    `json:"priority_id,omitempty"`
+	// ActorEmail is the portal user who made the change.
+	ActorEmail string `json:"actor_email,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	Rating     int    `json:"rating,omitempty"`
+}
+
+// CreateSubscriptionRequest is the DTO for creating a subscription. A
+// secret is generated when none is given.
+type CreateSubscriptionRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Events      []string `json:"events" binding:"required"`
+	Secret      string   `json:"secret,omitempty"`
+	Description string   `json:"description,omitempty"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+// UpdateSubscriptionRequest is the DTO for updating a subscription.
+type UpdateSubscriptionRequest struct {
+	URL         string   `json:"url,omitempty"`
+	Events      []string `json:"events,omitempty"`
+	Secret      string   `json:"secret,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	IsActive    *bool    `json:"is_active,omitempty"`
+}
+
+// SubscriptionSecretResponse returns a subscription with its signing
+// secret, which is only shown when it is set.
+type SubscriptionSecretResponse struct {
+	*Subscription
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SubscriptionRepository stores tenants' webhook subscriptions
+type SubscriptionRepository interface {
+	Create(ctx context.Context, sub *Subscription) error
+	Update(ctx context.Context, sub *Subscription) error
+	Delete(ctx context.Context, id string) error
+	FindByID(ctx context.Context, id string) (*Subscription, error)
+	ListByTenant(ctx context.Context, tenantID string) ([]*Subscription, error)
+}
+
+type gormSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+// NewSubscriptionRepository creates a new webhook subscription repository
+func NewSubscriptionRepository(db *gorm.DB) SubscriptionRepository {
+	return &gormSubscriptionRepository{db: db}
+}
+
+func (r *gormSubscriptionRepository) Create(ctx context.Context, sub *Subscription) error {
+	return r.db.WithContext(ctx).Create(sub).Error
+}
+
+func (r *gormSubscriptionRepository) Update(ctx context.Context, sub *Subscription) error {
+	return r.db.WithContext(ctx).Save(sub).Error
+}
+
+func (r *gormSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Delete(&Subscription{}, "id = ?", id).Error
+}
+
+func (r *gormSubscriptionRepository) FindByID(ctx context.Context, id string) (*Subscription, error) {
+	var sub Subscription
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&sub).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+func (r *gormSubscriptionRepository) ListByTenant(ctx context.Context, tenantID string) ([]*Subscription, error) {
+	var subs []*Subscription
+	err := r.db.WithContext(ctx).Where("tenant_id = ?", tenantID).Order("created_at").Find(&subs).Error
+	return subs, err
+}
+
+// DeliveryRepository stores queued webhook deliveries and their log
+type DeliveryRepository interface {
+	Enqueue(ctx context.Context, deliveries ...*Delivery) error
+	// Claim locks up to limit due pending deliveries for lease and returns
+	// them, so several workers never send the same delivery at once.
+	Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*Delivery, error)
+	// Record stores the outcome of an attempt: the status, attempt count,
+	// next attempt time and response fields of d.
+	Record(ctx context.Context, d *Delivery) error
+	FindByID(ctx context.Context, id string) (*Delivery, error)
+	// List returns a subscription's latest deliveries, optionally only
+	// those with the given status.
+	List(ctx context.Context, subscriptionID, status string, limit int) ([]*Delivery, error)
+}
+
+type gormDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// NewDeliveryRepository creates a new webhook delivery repository
+func NewDeliveryRepository(db *gorm.DB) DeliveryRepository {
+	return &gormDeliveryRepository{db: db}
+}
+
+func (r *gormDeliveryRepository) Enqueue(ctx context.Context, deliveries ...*Delivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Create(deliveries).Error
+}
+
+func (r *gormDeliveryRepository) Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*Delivery, error) {
+	token := uuid.New().String()
+	err := r.db.WithContext(ctx).Model(&Delivery{}).
+		Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+		Where("(locked_until IS NULL OR locked_until < ?)", now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Updates(map[string]interface{}{"claim_token": token, "locked_until": now.Add(lease)}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []*Delivery
+	err = r.db.WithContext(ctx).Where("claim_token = ? AND status = ?", token, StatusPending).Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *gormDeliveryRepository) Record(ctx context.Context, d *Delivery) error {
+	return r.db.WithContext(ctx).Model(&Delivery{}).Where("id = ?", d.ID).Updates(map[string]interface{}{
+		"status":          d.Status,
+		"attempts":        d.Attempts,
+		"next_attempt_at": d.NextAttemptAt,
+		"last_error":      d.LastError,
+		"response_status": d.ResponseStatus,
+		"response_body":   d.ResponseBody,
+		"duration_ms":     d.DurationMS,
+		"delivered_at":    d.DeliveredAt,
+		"locked_until":    nil,
+	}).Error
+}
+
+func (r *gormDeliveryRepository) FindByID(ctx context.Context, id string) (*Delivery, error) {
+	var d Delivery
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&d).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *gormDeliveryRepository) List(ctx context.Context, subscriptionID, status string, limit int) ([]*Delivery, error) {
+	query := r.db.WithContext(ctx).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var deliveries []*Delivery
+	err := query.Order("created_at DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
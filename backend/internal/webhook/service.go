@@ -0,0 +1,95 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"werk-ticketing/internal/errors"
+)
+
+// Publisher queues events for the tenant's webhook subscriptions.
+type Publisher interface {
+	// Publish queues one delivery for each active subscription to event.
+	Publish(ctx context.Context, tenantID, event string, data EventData) error
+}
+
+// Service publishes events and redelivers logged ones
+type Service interface {
+	Publisher
+	// Redeliver queues the payload of one of the tenant's logged deliveries
+	// again. The new delivery keeps the event ID.
+	Redeliver(ctx context.Context, tenantID, deliveryID string) (*Delivery, error)
+}
+
+type service struct {
+	subs       SubscriptionRepository
+	deliveries DeliveryRepository
+	now        func() time.Time
+}
+
+// NewService creates a new webhook service
+func NewService(subs SubscriptionRepository, deliveries DeliveryRepository) Service {
+	return &service{subs: subs, deliveries: deliveries, now: time.Now}
+}
+
+func (s *service) Publish(ctx context.Context, tenantID, event string, data EventData) error {
+	subs, err := s.subs.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	var queued []*Delivery
+	var payload []byte
+	now := s.now().UTC()
+	envelope := Envelope{ID: uuid.New().String(), Event: event, TenantID: tenantID, OccurredAt: now, Data: data}
+	for _, sub := range subs {
+		if !sub.Wants(event) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(envelope); err != nil {
+				return err
+			}
+		}
+		queued = append(queued, &Delivery{
+			ID:             uuid.New().String(),
+			SubscriptionID: sub.ID,
+			TenantID:       tenantID,
+			Event:          event,
+			EventID:        envelope.ID,
+			Payload:        payload,
+			Status:         StatusPending,
+			NextAttemptAt:  now,
+		})
+	}
+	return s.deliveries.Enqueue(ctx, queued...)
+}
+
+func (s *service) Redeliver(ctx context.Context, tenantID, deliveryID string) (*Delivery, error) {
+	original, err := s.deliveries.FindByID(ctx, deliveryID)
+	if err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInternal, "failed to get webhook delivery", err)
+	}
+	if original == nil || original.TenantID != tenantID {
+		return nil, errors.NewAppError(errors.ErrCodeNotFound, "webhook delivery not found", nil)
+	}
+
+	delivery := &Delivery{
+		ID:             uuid.New().String(),
+		SubscriptionID: original.SubscriptionID,
+		TenantID:       original.TenantID,
+		Event:          original.Event,
+		EventID:        original.EventID,
+		Payload:        original.Payload,
+		RedeliveryOf:   &original.ID,
+		Status:         StatusPending,
+		NextAttemptAt:  s.now(),
+	}
+	if err := s.deliveries.Enqueue(ctx, delivery); err != nil {
+		return nil, errors.NewAppError(errors.ErrCodeInternal, "failed to queue webhook delivery", err)
+	}
+	return delivery, nil
+}
@@ -0,0 +1,194 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+)
+
+// Request headers sent with every delivery.
+const (
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader carries the event ID, which redeliveries keep.
+	DeliveryHeader  = "X-Webhook-Delivery"
+	TimestampHeader = "X-Webhook-Timestamp"
+	// SignatureHeader carries "sha256=<hex HMAC-SHA256 of timestamp.body>".
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+// Sign returns the signature header value for a body sent at timestamp.
+// Signing the timestamp lets receivers reject replayed requests.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Worker sends queued webhook deliveries, retrying failures with
+// exponential backoff.
+type Worker struct {
+	subs       SubscriptionRepository
+	deliveries DeliveryRepository
+	client     *http.Client
+	logger     *logrus.Logger
+	now        func() time.Time
+}
+
+// NewWorker creates a webhook delivery worker.
+func NewWorker(subs SubscriptionRepository, deliveries DeliveryRepository, logger *logrus.Logger) *Worker {
+	client := &http.Client{
+		Timeout:   constants.WebhookTimeout,
+		Transport: newTransport(),
+		// A redirect is reported as a failure rather than followed.
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	return &Worker{subs: subs, deliveries: deliveries, client: client, logger: logger, now: time.Now}
+}
+
+// Run processes the queue periodically until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(constants.WebhookPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Drain full batches right away; wait for the next tick otherwise.
+		for {
+			n, err := w.ProcessDue(ctx)
+			if err != nil {
+				w.logger.WithError(err).Error("failed to claim webhook deliveries")
+			}
+			if err != nil || n < constants.WebhookBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue sends one batch of due deliveries and returns how many it claimed.
+func (w *Worker) ProcessDue(ctx context.Context) (int, error) {
+	deliveries, err := w.deliveries.Claim(ctx, w.now(), constants.WebhookBatchSize, constants.WebhookLease)
+	if err != nil {
+		return 0, err
+	}
+	for _, d := range deliveries {
+		w.deliver(ctx, d)
+	}
+	return len(deliveries), nil
+}
+
+func (w *Worker) deliver(ctx context.Context, d *Delivery) {
+	logger := w.logger.WithFields(logrus.Fields{
+		"deliveryID":     d.ID,
+		"subscriptionID": d.SubscriptionID,
+		"tenantID":       d.TenantID,
+		"event":          d.Event,
+		"attempt":        d.Attempts + 1,
+	})
+	d.Attempts++
+
+	sub, err := w.subs.FindByID(ctx, d.SubscriptionID)
+	if err != nil {
+		w.fail(ctx, logger, d, err, false)
+		return
+	}
+	if sub == nil || !sub.IsActive {
+		w.fail(ctx, logger, d, errors.New("subscription is disabled"), true)
+		return
+	}
+
+	if err := w.send(ctx, sub, d); err != nil {
+		w.fail(ctx, logger, d, err, false)
+		return
+	}
+
+	now := w.now()
+	d.Status, d.LastError, d.DeliveredAt = StatusDelivered, "", &now
+	if err := w.deliveries.Record(ctx, d); err != nil {
+		logger.WithError(err).Error("failed to record webhook delivery")
+		return
+	}
+	logger.WithField("responseStatus", d.ResponseStatus).Info("webhook delivered")
+}
+
+// send posts the payload and records the response on d. Any status but
+// 2xx is an error.
+func (w *Worker) send(ctx context.Context, sub *Subscription, d *Delivery) error {
+	timestamp := w.now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(d.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "werk-ticketing-webhooks/1.0")
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.EventID)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, timestamp, d.Payload))
+
+	start := time.Now()
+	resp, err := w.client.Do(req)
+	d.DurationMS = time.Since(start).Milliseconds()
+	if err != nil {
+		d.ResponseStatus, d.ResponseBody = 0, ""
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, constants.WebhookResponseBodyLimit))
+	d.ResponseStatus, d.ResponseBody = resp.StatusCode, strings.ToValidUTF8(string(body), "")
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *Worker) fail(ctx context.Context, logger *logrus.Entry, d *Delivery, sendErr error, permanent bool) {
+	d.Status, d.NextAttemptAt, d.LastError = StatusPending, w.now().Add(retryDelay(d.Attempts)), sendErr.Error()
+	if permanent || d.Attempts >= constants.WebhookMaxAttempts {
+		d.Status = StatusFailed
+	}
+
+	if err := w.deliveries.Record(ctx, d); err != nil {
+		logger.WithError(err).Error("failed to record webhook failure")
+	}
+	if d.Status == StatusFailed {
+		logger.WithError(sendErr).Error("webhook delivery failed")
+	} else {
+		logger.WithError(sendErr).WithField("nextAttemptAt", d.NextAttemptAt).Warn("webhook delivery failed, will retry")
+	}
+}
+
+// retryDelay is the wait before the next attempt after attempts failures:
+// exponential from WebhookRetryBaseDelay, capped, with up to 10% jitter.
+func retryDelay(attempts int) time.Duration {
+	delay := constants.WebhookRetryBaseDelay
+	for i := 1; i < attempts && delay < constants.WebhookRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > constants.WebhookRetryMaxDelay {
+		delay = constants.WebhookRetryMaxDelay
+	}
+	return delay + time.Duration(rand.Int63n(int64(delay/10)+1))
+}
@@ -0,0 +1,247 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"werk-ticketing/internal/constants"
+)
+
+type memorySubs struct {
+	SubscriptionRepository
+	subs []*Subscription
+}
+
+func (r *memorySubs) FindByID(ctx context.Context, id string) (*Subscription, error) {
+	for _, s := range r.subs {
+		if s.ID == id {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memorySubs) ListByTenant(ctx context.Context, tenantID string) ([]*Subscription, error) {
+	var subs []*Subscription
+	for _, s := range r.subs {
+		if s.TenantID == tenantID {
+			subs = append(subs, s)
+		}
+	}
+	return subs, nil
+}
+
+// memoryDeliveries is an in-memory DeliveryRepository; Claim ignores
+// leases since the tests run a single worker.
+type memoryDeliveries struct {
+	DeliveryRepository
+	deliveries []*Delivery
+}
+
+func (r *memoryDeliveries) Enqueue(ctx context.Context, deliveries ...*Delivery) error {
+	r.deliveries = append(r.deliveries, deliveries...)
+	return nil
+}
+
+func (r *memoryDeliveries) Claim(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*Delivery, error) {
+	var due []*Delivery
+	for _, d := range r.deliveries {
+		if d.Status == StatusPending && !d.NextAttemptAt.After(now) && len(due) < limit {
+			copied := *d
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryDeliveries) Record(ctx context.Context, d *Delivery) error {
+	for i, existing := range r.deliveries {
+		if existing.ID == d.ID {
+			copied := *d
+			r.deliveries[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (r *memoryDeliveries) FindByID(ctx context.Context, id string) (*Delivery, error) {
+	for _, d := range r.deliveries {
+		if d.ID == id {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+type webhookFixture struct {
+	service    Service
+	worker     *Worker
+	subs       *memorySubs
+	deliveries *memoryDeliveries
+	now        *time.Time
+}
+
+func newWebhookFixture(t *testing.T, subs ...*Subscription) *webhookFixture {
+	t.Helper()
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	f := &webhookFixture{subs: &memorySubs{subs: subs}, deliveries: &memoryDeliveries{}}
+	now := time.Now()
+	f.now = &now
+	f.service = NewService(f.subs, f.deliveries)
+	f.service.(*service).now = func() time.Time { return *f.now }
+	f.worker = NewWorker(f.subs, f.deliveries, logger)
+	f.worker.now = func() time.Time { return *f.now }
+	// Test receivers listen on loopback, which the worker refuses.
+	f.worker.client.Transport = http.DefaultTransport
+	return f
+}
+
+func (f *webhookFixture) process(t *testing.T) {
+	t.Helper()
+	if _, err := f.worker.ProcessDue(context.Background()); err != nil {
+		t.Fatalf("ProcessDue: %v", err)
+	}
+}
+
+func TestPublishQueuesForSubscribedEndpoints(t *testing.T) {
+	f := newWebhookFixture(t,
+		&Subscription{ID: "s1", TenantID: "t1", Events: []string{EventTicketCreated}, IsActive: true},
+		&Subscription{ID: "s2", TenantID: "t1", Events: []string{EventCommentAdded}, IsActive: true},
+		&Subscription{ID: "s3", TenantID: "t1", Events: []string{EventTicketCreated}, IsActive: false},
+		&Subscription{ID: "s4", TenantID: "t2", Events: []string{EventTicketCreated}, IsActive: true},
+	)
+
+	if err := f.service.Publish(context.Background(), "t1", EventTicketCreated, EventData{TicketID: 42, Title: "VPN down"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if len(f.deliveries.deliveries) != 1 || f.deliveries.deliveries[0].SubscriptionID != "s1" {
+		t.Fatalf("deliveries = %+v, want one for s1", f.deliveries.deliveries)
+	}
+
+	var envelope Envelope
+	d := f.deliveries.deliveries[0]
+	if err := json.Unmarshal(d.Payload, &envelope); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if envelope.ID != d.EventID || envelope.Event != EventTicketCreated || envelope.TenantID != "t1" || envelope.Data.TicketID != 42 {
+		t.Errorf("envelope = %+v", envelope)
+	}
+}
+
+func TestWorkerSignsAndDelivers(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.Write([]byte("ok"))
+	}))
+	defer receiver.Close()
+
+	f := newWebhookFixture(t, &Subscription{ID: "s1", TenantID: "t1", URL: receiver.URL, Secret: "shh", Events: []string{EventCommentAdded}, IsActive: true})
+	if err := f.service.Publish(context.Background(), "t1", EventCommentAdded, EventData{TicketID: 7, Comment: "hi"}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	f.process(t)
+
+	if got == nil {
+		t.Fatal("receiver was not called")
+	}
+	timestamp, _ := strconv.ParseInt(got.Header.Get(TimestampHeader), 10, 64)
+	if want := Sign("shh", timestamp, body); got.Header.Get(SignatureHeader) != want {
+		t.Errorf("signature = %q, want %q", got.Header.Get(SignatureHeader), want)
+	}
+	d := f.deliveries.deliveries[0]
+	if got.Header.Get(EventHeader) != EventCommentAdded || got.Header.Get(DeliveryHeader) != d.EventID {
+		t.Errorf("headers = %v", got.Header)
+	}
+	if d.Status != StatusDelivered || d.Attempts != 1 || d.ResponseStatus != http.StatusOK || d.ResponseBody != "ok" || d.DeliveredAt == nil {
+		t.Errorf("delivery = %+v", d)
+	}
+}
+
+func TestWorkerRetriesThenFails(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	f := newWebhookFixture(t, &Subscription{ID: "s1", TenantID: "t1", URL: receiver.URL, Events: []string{EventSolutionRejected}, IsActive: true})
+	if err := f.service.Publish(context.Background(), "t1", EventSolutionRejected, EventData{TicketID: 7}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	for i := 1; i <= constants.WebhookMaxAttempts; i++ {
+		f.process(t)
+		d := f.deliveries.deliveries[0]
+		if d.Attempts != i || d.ResponseStatus != http.StatusServiceUnavailable {
+			t.Fatalf("attempt %d: delivery = %+v", i, d)
+		}
+		if want := i < constants.WebhookMaxAttempts; (d.Status == StatusPending) != want {
+			t.Fatalf("attempt %d: status = %s", i, d.Status)
+		}
+		*f.now = d.NextAttemptAt
+	}
+	if d := f.deliveries.deliveries[0]; d.Status != StatusFailed {
+		t.Errorf("status = %s, want failed", d.Status)
+	}
+}
+
+func TestRedeliverKeepsEventID(t *testing.T) {
+	f := newWebhookFixture(t, &Subscription{ID: "s1", TenantID: "t1", Events: []string{EventTicketCreated}, IsActive: true})
+	f.subs.subs[0].IsActive = false
+	f.deliveries.deliveries = []*Delivery{{ID: "d1", SubscriptionID: "s1", TenantID: "t1", Event: EventTicketCreated, EventID: "e1", Payload: []byte(`{}`), Status: StatusPending, NextAttemptAt: *f.now}}
+
+	// A disabled subscription fails the delivery without sending it.
+	f.process(t)
+	if d := f.deliveries.deliveries[0]; d.Status != StatusFailed || d.Attempts != 1 {
+		t.Fatalf("delivery = %+v", d)
+	}
+
+	redelivery, err := f.service.Redeliver(context.Background(), "t1", "d1")
+	if err != nil {
+		t.Fatalf("Redeliver: %v", err)
+	}
+	if redelivery.ID == "d1" || redelivery.EventID != "e1" || redelivery.RedeliveryOf == nil || *redelivery.RedeliveryOf != "d1" || redelivery.Status != StatusPending {
+		t.Errorf("redelivery = %+v", redelivery)
+	}
+	if _, err := f.service.Redeliver(context.Background(), "t1", "missing"); err == nil {
+		t.Error("expected an error for an unknown delivery")
+	}
+	if _, err := f.service.Redeliver(context.Background(), "t2", "d1"); err == nil {
+		t.Error("expected an error for another tenant's delivery")
+	}
+}
+
+func TestWorkerRefusesLoopbackReceivers(t *testing.T) {
+	called := false
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer receiver.Close()
+
+	f := newWebhookFixture(t, &Subscription{ID: "s1", TenantID: "t1", URL: receiver.URL, Events: []string{EventCommentAdded}, IsActive: true})
+	f.worker.client.Transport = newTransport()
+	if err := f.service.Publish(context.Background(), "t1", EventCommentAdded, EventData{TicketID: 7}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	f.process(t)
+
+	if called {
+		t.Error("receiver on a loopback address was called")
+	}
+	if d := f.deliveries.deliveries[0]; !strings.Contains(d.LastError, errBlockedAddress.Error()) {
+		t.Errorf("last error = %q", d.LastError)
+	}
+}
@@ -12,18 +12,48 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 
+	"werk-ticketing/internal/audit"
 	"werk-ticketing/internal/auth"
 	"werk-ticketing/internal/config"
 	"werk-ticketing/internal/constants"
 	"werk-ticketing/internal/database"
 	"werk-ticketing/internal/email"
+	"werk-ticketing/internal/health"
 	"werk-ticketing/internal/invgate"
+	"werk-ticketing/internal/invgatehook"
+	"werk-ticketing/internal/metrics"
+	"werk-ticketing/internal/middleware"
+	"werk-ticketing/internal/notification"
+	"werk-ticketing/internal/realtime"
 	"werk-ticketing/internal/router"
+	"werk-ticketing/internal/scanner"
+	"werk-ticketing/internal/storage"
 	"werk-ticketing/internal/tenant"
 	"werk-ticketing/internal/ticket"
+	"werk-ticketing/internal/tracing"
+	"werk-ticketing/internal/upload"
 	"werk-ticketing/internal/user"
+	"werk-ticketing/internal/webhook"
 )
 
+// models are auto-migrated on startup; readiness checks their columns exist.
+var models = []interface{}{
+	&tenant.Tenant{},             // Tenants table
+	&user.User{},                 // Users table
+	&user.ResetToken{},           // Password reset tokens table
+	&upload.Upload{},             // Uploaded file metadata
+	&email.TemplateOverride{},    // Per-tenant email template overrides
+	&email.QueuedEmail{},         // Outbound email queue
+	&notification.TicketState{},  // Last seen state of InvGate tickets
+	&notification.Unsubscribe{},  // Ticket update email unsubscribes
+	&notification.DigestItem{},   // Ticket updates waiting for a digest
+	&notification.Notification{}, // In-portal notification center
+	&invgatehook.Delivery{},      // Accepted InvGate webhook deliveries
+	&webhook.Subscription{},      // Tenant webhook subscriptions
+	&webhook.Delivery{},          // Outbound webhook queue and delivery log
+	&audit.Event{},               // Append-only audit log
+}
+
 func main() {
 	cfg, err := config.Load()
 	if err != nil {
@@ -37,6 +67,12 @@ func main() {
 	}
 	gin.SetMode(ginMode)
 
+	// Tracing is set up before the database so startup queries are traced too
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("tracing error: %v", err)
+	}
+
 	db, err := database.Connect(cfg)
 	if err != nil {
 		log.Fatalf("database error: %v", err)
@@ -46,16 +82,18 @@ func main() {
 		log.Fatalf("database pooling error: %v", err)
 	}
 	defer sqlDB.Close()
+	metrics.RegisterDB(sqlDB)
 
 	// Auto migrate all models
 	// This ensures all tables are created/updated when the application starts
-	if err := db.AutoMigrate(
-		&tenant.Tenant{},   // Tenants table
-		&user.User{},       // Users table
-		&user.ResetToken{}, // Password reset tokens table
-	); err != nil {
+	if err := db.AutoMigrate(models...); err != nil {
 		log.Fatalf("auto migrate error: %v", err)
 	}
+	// AutoMigrate does not create triggers; without them the audit log
+	// would not be append-only
+	if err := audit.EnsureAppendOnly(context.Background(), db); err != nil {
+		log.Fatalf("audit log trigger error (the database user needs the TRIGGER privilege): %v", err)
+	}
 
 	// Configure logger based on environment
 	logger := configureLogger(cfg)
@@ -65,12 +103,37 @@ func main() {
 	userRepo := user.NewRepository(db)
 
 	// Initialize services
+	auditService := audit.NewService(audit.NewRepository(db), logger)
 	invgateClient := invgate.NewService(cfg)
-	ticketService := ticket.NewService(invgateClient, userRepo, logger)
+	fileScanner := scanner.New(cfg)
+	webhookSubs := webhook.NewSubscriptionRepository(db)
+	webhookDeliveries := webhook.NewDeliveryRepository(db)
+	webhookService := webhook.NewService(webhookSubs, webhookDeliveries)
+	ticketService := ticket.NewService(invgateClient, userRepo, fileScanner, webhookService, auditService, logger)
 	ticketHandler := ticket.NewHandler(ticketService)
 
-	// Initialize email client
-	emailClient := email.NewMailgunClient(cfg.MailgunDomain, cfg.MailgunAPIKey, cfg.MailgunSender)
+	blob, err := storage.New(cfg)
+	if err != nil {
+		log.Fatalf("storage error: %v", err)
+	}
+	uploadService := upload.NewService(upload.NewRepository(db), blob, tenantRepo, logger)
+
+	// Initialize email client; tenants may override the Mailgun settings
+	emailSender, err := email.NewSender(cfg)
+	if err != nil {
+		log.Fatalf("email error: %v", err)
+	}
+	emailTemplates := email.NewTemplateRepository(db)
+	emailRegistry, err := email.NewRegistry(emailTemplates)
+	if err != nil {
+		log.Fatalf("email template error: %v", err)
+	}
+	emailQueue := email.NewQueueRepository(db)
+	emailClient := email.NewMailer(emailSender, emailRegistry, email.Settings{
+		Domain: cfg.MailgunDomain,
+		APIKey: cfg.MailgunAPIKey,
+		Sender: cfg.MailgunSender,
+	}, uploadService, cfg.PublicBaseURL, emailQueue)
 
 	authService := auth.NewService(
 		userRepo,
@@ -80,13 +143,53 @@ func main() {
 		logger,
 		emailClient,
 		cfg.FrontendURL,
+		auditService,
 	)
 	authHandler := auth.NewHandler(authService)
-	userHandler := user.NewHandler(userRepo)
-	tenantHandler := tenant.NewHandler(tenantRepo)
+	userHandler := user.NewHandler(userRepo, auditService)
+	uploadHandler := upload.NewHandler(uploadService, blob, fileScanner, logger)
+	tenantHandler := tenant.NewHandler(tenantRepo, uploadService, auditService)
+	emailHandler := email.NewHandler(emailClient, emailRegistry, emailTemplates, emailQueue)
+	notificationService := notification.NewService(notification.NewRepository(db), userRepo, tenantRepo, emailClient, cfg.JWTSecret, cfg.FrontendURL, logger)
+	notificationInbox := notification.NewInbox(notification.NewInboxRepository(db), userRepo, tenantRepo, logger)
+	notificationHandler := notification.NewHandler(notificationService, notificationInbox)
+	ticketBroker := realtime.NewBroker(constants.StreamHistorySize, constants.StreamClientBuffer)
+	streamHandler := realtime.NewHandler(ticketBroker, userRepo, logger)
+	ticketSyncer := notification.NewSyncer(invgateClient, notification.NewStateRepository(db), userRepo, logger, notificationService, notificationInbox, ticketBroker)
+	invgateHookDeliveries := invgatehook.NewRepository(db)
+	invgateHookHandler := invgatehook.NewHandler(tenantRepo, invgateHookDeliveries, ticketSyncer, logger)
+	webhookHandler := webhook.NewHandler(webhookService, webhookSubs, webhookDeliveries)
+	auditHandler := audit.NewHandler(auditService, middleware.GetTenantID, logger)
+
+	// Readiness: DB and schema are critical; email and InvGate only degrade
+	readiness := []health.Component{
+		health.Database(sqlDB),
+		health.Schema(db, models...),
+		health.Email(emailClient),
+	}
+	var probedTenants tenant.Repository
+	if cfg.ReadinessInvGateProbe {
+		readiness = append(readiness, health.InvGate("invgate", invgateClient))
+		probedTenants = tenantRepo
+	}
+	healthHandler := health.NewHandler(health.NewChecker(constants.HealthCheckTimeout, constants.HealthCheckCacheTTL), readiness, probedTenants, logger)
+
+	// Background jobs stop when the server shuts down
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go upload.SweepOrphans(bgCtx, uploadService, logger)
+	go email.NewWorker(emailQueue, emailClient, tenantRepo, logger).Run(bgCtx)
+	go email.PruneOutbox(bgCtx, emailQueue, logger)
+	go notification.RunDigests(bgCtx, notificationService, logger)
+	go notification.RunInboxPrune(bgCtx, notificationInbox, logger)
+	go invgatehook.PruneDeliveries(bgCtx, invgateHookDeliveries, logger)
+	go webhook.NewWorker(webhookSubs, webhookDeliveries, logger).Run(bgCtx)
+	if cfg.TicketSyncEnabled {
+		go ticketSyncer.Run(bgCtx)
+	}
 
 	// Setup router
-	appRouter := router.NewRouter(authHandler, ticketHandler, userHandler, tenantHandler, authService, tenantRepo, logger)
+	appRouter := router.NewRouter(authHandler, ticketHandler, userHandler, tenantHandler, uploadHandler, emailHandler, notificationHandler, invgateHookHandler, webhookHandler, streamHandler, auditHandler, healthHandler, authService, tenantRepo, userRepo, cfg.StoragePublicPath, cfg.AccessLogSampleRate, cfg.MetricsToken, logger)
 	ginRouter := appRouter.SetupRoutes()
 
 	// Create HTTP server with timeouts
@@ -94,7 +197,7 @@ func main() {
 	srv := &http.Server{
 		Addr:         addr,
 		Handler:      ginRouter,
-		ReadTimeout:  15 * time.Second,
+		ReadTimeout:  15 * time.Second, // upload routes extend it, see middleware.UploadDeadlines
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
@@ -119,6 +222,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logger.Info("shutting down server...")
+	stopBackground()
 
 	// The context is used to inform the server it has 30 seconds to finish
 	// the request it is currently handling
@@ -130,6 +234,9 @@ func main() {
 	} else {
 		logger.Info("server exited gracefully")
 	}
+	if err := shutdownTracing(ctx); err != nil {
+		logger.WithError(err).Error("failed to flush traces")
+	}
 }
 
 // configureLogger sets up the logger based on configuration
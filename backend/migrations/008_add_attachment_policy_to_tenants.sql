@@ -0,0 +1,4 @@
+-- Migration: Add per-tenant attachment policy
+-- NULL means the built-in defaults (size, count and allowed types) apply
+
+ALTER TABLE tenants ADD COLUMN attachment_policy JSON NULL AFTER primary_color;
@@ -0,0 +1,20 @@
+-- Migration: Create uploads table
+-- Tracks every file stored through /upload, the tenant that owns it and
+-- whether it may be served publicly (tenant logos)
+
+CREATE TABLE IF NOT EXISTS uploads (
+    id CHAR(36) PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    uploaded_by VARCHAR(190) NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(127) NOT NULL,
+    size BIGINT NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    purpose VARCHAR(32) NOT NULL DEFAULT 'general',
+    is_public BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_uploads_storage_key (storage_key),
+    INDEX idx_uploads_tenant_purpose (tenant_id, purpose)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
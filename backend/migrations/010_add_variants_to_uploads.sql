@@ -0,0 +1,4 @@
+-- Migration: Record generated logo sizes on uploads
+-- Holds a JSON array such as ["favicon","header","email"]; NULL when none
+
+ALTER TABLE uploads ADD COLUMN variants JSON NULL AFTER is_public;
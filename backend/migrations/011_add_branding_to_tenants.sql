@@ -0,0 +1,4 @@
+-- Migration: Add versioned branding/portal configuration document to tenants
+-- NULL means the defaults (Indonesian, all features on, rating required)
+
+ALTER TABLE tenants ADD COLUMN branding JSON NULL AFTER primary_color;
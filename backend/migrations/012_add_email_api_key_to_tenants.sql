@@ -0,0 +1,4 @@
+-- Migration: Add per-tenant Mailgun API key
+-- NULL/empty means the global MAILGUN_API_KEY is used
+
+ALTER TABLE tenants ADD COLUMN email_api_key VARCHAR(255) NULL AFTER email_sender;
@@ -0,0 +1,18 @@
+-- Migration: Create email_templates table
+-- Per-tenant overrides of the built-in email templates, one row per
+-- template name and locale; empty parts fall back to the built-in version
+
+CREATE TABLE IF NOT EXISTS email_templates (
+    id CHAR(36) PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    subject TEXT,
+    html_body MEDIUMTEXT,
+    text_body TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE INDEX idx_email_templates_tenant_name_locale (tenant_id, name, locale),
+    CONSTRAINT fk_email_templates_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,27 @@
+-- Migration: Create email_outbox table
+-- Rendered outbound emails sent by the background worker with retries;
+-- failed emails are dead-lettered after too many attempts
+
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id CHAR(36) PRIMARY KEY,
+    tenant_id CHAR(36),
+    template VARCHAR(100),
+    recipient VARCHAR(255) NOT NULL,
+    subject TEXT,
+    html_body MEDIUMTEXT,
+    text_body TEXT,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3) NOT NULL,
+    locked_until DATETIME(3) NULL,
+    claim_token VARCHAR(36),
+    last_error TEXT,
+    provider_message_id VARCHAR(255),
+    sent_at DATETIME(3) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_email_outbox_tenant_id (tenant_id),
+    INDEX idx_email_outbox_due (status, next_attempt_at),
+    INDEX idx_email_outbox_claim_token (claim_token)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,46 @@
+-- Migration: Ticket update email notifications
+-- ticket_states keeps the last seen state of each InvGate ticket so the sync
+-- worker can detect changes; unsubscribes with ticket_id 0 mute all tickets;
+-- digest items wait for tenants that batch updates hourly or daily
+
+ALTER TABLE tenants ADD COLUMN notification_settings JSON NULL AFTER attachment_policy;
+
+CREATE INDEX idx_users_invgate_user_id ON users (invgate_user_id);
+
+CREATE TABLE IF NOT EXISTS ticket_states (
+    ticket_id BIGINT PRIMARY KEY,
+    creator_id BIGINT NOT NULL,
+    status_id BIGINT NOT NULL,
+    last_update BIGINT NOT NULL,
+    last_comment_id BIGINT NOT NULL DEFAULT 0,
+    updated_at DATETIME(3) NULL,
+
+    INDEX idx_ticket_states_creator_id (creator_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS notification_unsubscribes (
+    id CHAR(36) PRIMARY KEY,
+    user_id CHAR(36) NOT NULL,
+    ticket_id BIGINT NOT NULL DEFAULT 0,
+    created_at DATETIME(3) NULL,
+
+    UNIQUE INDEX idx_notification_unsubscribes_user_ticket (user_id, ticket_id),
+    CONSTRAINT fk_notification_unsubscribes_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS notification_digest_items (
+    id CHAR(36) PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    ticket_id BIGINT NOT NULL,
+    ticket_title VARCHAR(255),
+    status_id BIGINT NOT NULL DEFAULT 0,
+    comment TEXT,
+    created_at DATETIME(3) NULL,
+
+    INDEX idx_notification_digest_items_tenant_id (tenant_id),
+    INDEX idx_notification_digest_items_user_id (user_id),
+    INDEX idx_notification_digest_items_created_at (created_at),
+    CONSTRAINT fk_notification_digest_items_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
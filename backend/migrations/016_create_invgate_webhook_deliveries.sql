@@ -0,0 +1,18 @@
+-- Migration: Inbound InvGate webhooks
+-- Each tenant gets its own shared secret; accepted deliveries are kept for a
+-- while so that InvGate's redeliveries are not processed twice
+
+ALTER TABLE tenants ADD COLUMN webhook_secret VARCHAR(255) NULL AFTER email_api_key;
+
+CREATE TABLE IF NOT EXISTS invgate_webhook_deliveries (
+    id CHAR(36) PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    delivery_id VARCHAR(128) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    ticket_id BIGINT NOT NULL,
+    created_at DATETIME(3) NULL,
+
+    UNIQUE INDEX idx_invgate_webhook_deliveries_tenant_delivery (tenant_id, delivery_id),
+    INDEX idx_invgate_webhook_deliveries_created_at (created_at),
+    CONSTRAINT fk_invgate_webhook_deliveries_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
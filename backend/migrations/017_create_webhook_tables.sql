@@ -0,0 +1,47 @@
+-- Migration: Outbound tenant webhooks
+-- Subscriptions send a tenant's ticket events to its own systems; deliveries
+-- are the retrying send queue and, once sent or failed, the delivery log
+
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id CHAR(36) PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    url VARCHAR(500) NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    events JSON NULL,
+    description VARCHAR(255) NULL,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+
+    INDEX idx_webhook_subscriptions_tenant_id (tenant_id),
+    CONSTRAINT fk_webhook_subscriptions_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id CHAR(36) PRIMARY KEY,
+    subscription_id CHAR(36) NOT NULL,
+    tenant_id CHAR(36) NOT NULL,
+    event VARCHAR(64) NOT NULL,
+    event_id CHAR(36) NOT NULL,
+    payload MEDIUMTEXT NULL,
+    redelivery_of CHAR(36) NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at DATETIME(3) NOT NULL,
+    locked_until DATETIME(3) NULL,
+    claim_token VARCHAR(36) NULL,
+    last_error TEXT NULL,
+    response_status BIGINT NULL,
+    response_body TEXT NULL,
+    duration_ms BIGINT NULL,
+    delivered_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+    updated_at DATETIME(3) NULL,
+
+    INDEX idx_webhook_deliveries_subscription_id (subscription_id),
+    INDEX idx_webhook_deliveries_tenant_id (tenant_id),
+    INDEX idx_webhook_deliveries_due (status, next_attempt_at),
+    INDEX idx_webhook_deliveries_claim_token (claim_token),
+    INDEX idx_webhook_deliveries_created_at (created_at),
+    CONSTRAINT fk_webhook_deliveries_subscription FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,20 @@
+-- Migration: In-portal notification center
+-- One row per ticket event per requester account, filled by the same sync as
+-- the update emails; rows older than the tenant's
+-- notification_settings.retention_days are pruned
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id CHAR(36) PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL,
+    user_id CHAR(36) NOT NULL,
+    type VARCHAR(32) NOT NULL,
+    ticket_id BIGINT NOT NULL,
+    payload JSON NULL,
+    read_at DATETIME(3) NULL,
+    created_at DATETIME(3) NULL,
+
+    INDEX idx_notifications_user_created (user_id, created_at),
+    INDEX idx_notifications_tenant_created (tenant_id, created_at),
+    CONSTRAINT fk_notifications_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id) ON DELETE CASCADE,
+    CONSTRAINT fk_notifications_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,32 @@
+-- Migration: Audit log
+-- Append-only record of security and data-changing actions; changes holds
+-- the before/after value of each changed field (secrets are redacted).
+-- There is no foreign key to tenants so events outlive deleted tenants.
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id CHAR(36) PRIMARY KEY,
+    tenant_id CHAR(36) NOT NULL DEFAULT '',
+    actor_id CHAR(36) NOT NULL DEFAULT '',
+    actor_email VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(64) NOT NULL,
+    target_type VARCHAR(32) NOT NULL DEFAULT '',
+    target_id VARCHAR(64) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent VARCHAR(512) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    changes JSON NULL,
+    created_at DATETIME(3) NULL,
+
+    INDEX idx_audit_events_tenant_created (tenant_id, created_at),
+    INDEX idx_audit_events_created_at (created_at),
+    INDEX idx_audit_events_actor_email (actor_email),
+    INDEX idx_audit_events_action (action),
+    INDEX idx_audit_events_target (target_type, target_id),
+    INDEX idx_audit_events_request_id (request_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TRIGGER audit_events_no_update BEFORE UPDATE ON audit_events
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_events is append-only';
+
+CREATE TRIGGER audit_events_no_delete BEFORE DELETE ON audit_events
+FOR EACH ROW SIGNAL SQLSTATE '45000' SET MESSAGE_TEXT = 'audit_events is append-only';
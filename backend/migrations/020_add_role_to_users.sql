@@ -0,0 +1,4 @@
+-- Migration: Add user role
+-- user (default), admin (tenant administration) or operator (tenant management)
+
+ALTER TABLE users ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user' AFTER invgate_user_id;
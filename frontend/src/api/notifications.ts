@@ -0,0 +1,71 @@
+import { http } from "./http";
+
+export interface NotificationPreferences {
+  email_enabled: boolean;
+  unsubscribed_tickets: number[];
+}
+
+export type NotificationType = "status_changed" | "comment_added" | "solution_proposed";
+
+export interface PortalNotification {
+  id: string;
+  type: NotificationType;
+  ticket_id: number;
+  payload: {
+    ticket_title?: string;
+    status_id?: number;
+    comment_id?: number;
+    comment?: string;
+  };
+  read_at: string | null;
+  created_at: string;
+}
+
+export interface NotificationPage {
+  data: PortalNotification[];
+  next_cursor?: string;
+  unread_count: number;
+}
+
+interface ApiResponse<T> {
+  success: boolean;
+  data: T;
+}
+
+export const notificationsApi = {
+  getPreferences: async (): Promise<NotificationPreferences> => {
+    const response = await http.get<ApiResponse<NotificationPreferences>>("/notification-preferences");
+    return response.data.data;
+  },
+
+  setEmailEnabled: async (enabled: boolean): Promise<NotificationPreferences> => {
+    const response = await http.put<ApiResponse<NotificationPreferences>>("/notification-preferences", {
+      email_enabled: enabled,
+    });
+    return response.data.data;
+  },
+
+  setTicketSubscription: async (ticketId: number, subscribed: boolean): Promise<void> => {
+    await http.put(`/tickets/${ticketId}/subscription`, { subscribed });
+  },
+
+  // Notification center, newest first; pass next_cursor to get the next page
+  list: async (params: { cursor?: string; limit?: number; unread?: boolean } = {}): Promise<NotificationPage> => {
+    const response = await http.get<ApiResponse<NotificationPage>>("/notifications", { params });
+    return response.data.data;
+  },
+
+  markRead: async (id: string): Promise<PortalNotification> => {
+    const response = await http.post<ApiResponse<PortalNotification>>(`/notifications/${id}/read`);
+    return response.data.data;
+  },
+
+  markAllRead: async (): Promise<void> => {
+    await http.post("/notifications/read-all");
+  },
+
+  // Applies the signed link from a ticket update email; works without login
+  unsubscribe: async (token: string): Promise<void> => {
+    await http.post("/email/unsubscribe", { token });
+  },
+};
@@ -1,11 +1,42 @@
 import { http } from './http'
 
+export interface TenantBranding {
+  version: number
+  secondary_color?: string
+  accent_color?: string
+  favicon_url?: string
+  portal_title?: string
+  login_message?: string
+  support: {
+    email?: string
+    phone?: string
+    url?: string
+    hours?: string
+  }
+  default_language?: 'id' | 'en'
+  features: {
+    knowledge_base?: boolean
+    attachments?: boolean
+    rating_required?: boolean
+  }
+}
+
+export type NotificationDigest = 'immediate' | 'hourly' | 'daily' | 'off'
+
+export interface TenantNotificationSettings {
+  digest?: NotificationDigest
+  // Days in-portal notifications are kept (1-365, default 90)
+  retention_days?: number
+}
+
 export interface TenantPublicInfo {
   id: string
   name: string
   slug: string
   logo_url?: string
+  logo_urls?: Record<'original' | 'favicon' | 'header' | 'email', string>
   primary_color?: string
+  branding?: TenantBranding
 }
 
 export interface Tenant extends TenantPublicInfo {
@@ -17,6 +48,7 @@ export interface Tenant extends TenantPublicInfo {
   invgate_username: string
   email_domain?: string
   email_sender?: string
+  notification_settings?: TenantNotificationSettings
   created_at?: string
   updated_at?: string
 }
@@ -32,8 +64,12 @@ export interface CreateTenantRequest {
   invgate_password: string
   email_domain?: string
   email_sender?: string
+  email_api_key?: string
+  webhook_secret?: string
+  notification_settings?: TenantNotificationSettings
   logo_url?: string
   primary_color?: string
+  branding?: TenantBranding
   is_active?: boolean
 }
 
@@ -0,0 +1,88 @@
+import { getCookie } from '@/utils/cookies'
+import { COOKIE_NAMES } from '@/utils/constants'
+
+const baseURL = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080/api/v1'
+
+export type TicketStreamEventType = 'status_changed' | 'comment_added' | 'solution_proposed' | 'reset'
+
+export interface TicketStreamEvent {
+  id?: string
+  type: TicketStreamEventType
+  ticket_id?: number
+  status_id?: number
+  comment_id?: number
+  occurred_at?: string
+}
+
+/**
+ * Connects to GET /tickets/stream and calls onEvent for each event until the
+ * returned function is called. EventSource cannot send the Authorization and
+ * X-Tenant-ID headers, so the stream is read with fetch; it reconnects after
+ * errors and resumes from the last event ID.
+ */
+export function connectTicketStream(onEvent: (event: TicketStreamEvent) => void): () => void {
+  const controller = new AbortController()
+  let lastEventId = ''
+  let retryMs = 5000
+
+  const run = async () => {
+    while (!controller.signal.aborted) {
+      try {
+        await readStream()
+      } catch {
+        // Network error or server restart; reconnect below
+      }
+      if (controller.signal.aborted) return
+      await new Promise((resolve) => setTimeout(resolve, retryMs))
+    }
+  }
+
+  const readStream = async () => {
+    const headers: Record<string, string> = { Accept: 'text/event-stream' }
+    const token = getCookie(COOKIE_NAMES.ACCESS_TOKEN)
+    if (token) headers.Authorization = `Bearer ${token}`
+    const tenantId = getCookie('tenant_id')
+    if (tenantId && tenantId !== 'undefined' && tenantId !== 'null') headers['X-Tenant-ID'] = tenantId
+    if (lastEventId) headers['Last-Event-ID'] = lastEventId
+
+    const response = await fetch(`${baseURL}/tickets/stream`, { headers, signal: controller.signal })
+    if (!response.ok || !response.body) return
+
+    const reader = response.body.pipeThrough(new TextDecoderStream()).getReader()
+    let buffer = ''
+    for (;;) {
+      const { value, done } = await reader.read()
+      if (done) return
+      buffer += value
+      let end: number
+      while ((end = buffer.indexOf('\n\n')) !== -1) {
+        dispatch(buffer.slice(0, end))
+        buffer = buffer.slice(end + 2)
+      }
+    }
+  }
+
+  const dispatch = (block: string) => {
+    let type = 'message'
+    let data = ''
+    for (const line of block.split('\n')) {
+      if (line.startsWith(':')) continue // heartbeat
+      const sep = line.indexOf(':')
+      const field = sep === -1 ? line : line.slice(0, sep)
+      const value = sep === -1 ? '' : line.slice(sep + 1).replace(/^ /, '')
+      if (field === 'id') lastEventId = value
+      else if (field === 'event') type = value
+      else if (field === 'data') data += value
+      else if (field === 'retry' && /^\d+$/.test(value)) retryMs = Number(value)
+    }
+    if (!data) return
+    try {
+      onEvent({ ...JSON.parse(data), type } as TicketStreamEvent)
+    } catch {
+      // Ignore malformed events
+    }
+  }
+
+  run()
+  return () => controller.abort()
+}
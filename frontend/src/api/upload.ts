@@ -1,13 +1,22 @@
 import { http } from './http'
 
+export type UploadPurpose = 'general' | 'logo'
+
 export interface UploadResponse {
+  id: string
   url: string
+  purpose: UploadPurpose
+  is_public: boolean
+  mime_type: string
+  size: number
+  checksum: string
 }
 
 export const uploadApi = {
-  uploadFile: async (file: File): Promise<UploadResponse> => {
+  uploadFile: async (file: File, purpose: UploadPurpose = 'general'): Promise<UploadResponse> => {
     const formData = new FormData()
     formData.append('file', file)
+    formData.append('purpose', purpose)
     
     const response = await http.post('/upload', formData, {
       headers: {
@@ -8,7 +8,7 @@ export const useDashboard = () => {
   const { data: ticketsData, isLoading, error } = useQuery({
     queryKey: ['dashboard-tickets'],
     queryFn: () => ticketsApi.list(1, 100), // Get more tickets for stats
-    refetchInterval: 30 * 1000, // Auto-refresh every 30 seconds
+    refetchInterval: 5 * 60 * 1000, // Fallback; the ticket stream pushes changes
   })
 
   const tickets = computed<Ticket[]>(() => ticketsData.value?.data || [])
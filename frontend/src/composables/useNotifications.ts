@@ -0,0 +1,46 @@
+import { computed, type Ref } from 'vue'
+import { useInfiniteQuery, useMutation, useQueryClient } from '@tanstack/vue-query'
+import { notificationsApi, type PortalNotification } from '@/api/notifications'
+
+/**
+ * The current user's notification center, loaded a page at a time.
+ * The ticket stream invalidates it when tickets change.
+ */
+export const useNotifications = (enabled: Ref<boolean>) => {
+  const query = useInfiniteQuery({
+    queryKey: ['notifications'],
+    queryFn: ({ pageParam }) => notificationsApi.list({ cursor: pageParam || undefined }),
+    initialPageParam: '',
+    getNextPageParam: (lastPage) => lastPage.next_cursor || undefined,
+    enabled,
+  })
+
+  const notifications = computed<PortalNotification[]>(
+    () => query.data.value?.pages.flatMap((page) => page.data) || []
+  )
+  const unreadCount = computed(() => query.data.value?.pages[0]?.unread_count || 0)
+
+  return { ...query, notifications, unreadCount }
+}
+
+export const useMarkNotificationRead = () => {
+  const queryClient = useQueryClient()
+
+  return useMutation({
+    mutationFn: (id: string) => notificationsApi.markRead(id),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['notifications'] })
+    },
+  })
+}
+
+export const useMarkAllNotificationsRead = () => {
+  const queryClient = useQueryClient()
+
+  return useMutation({
+    mutationFn: () => notificationsApi.markAllRead(),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: ['notifications'] })
+    },
+  })
+}
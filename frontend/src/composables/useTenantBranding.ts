@@ -13,7 +13,7 @@ export function useTenantBranding() {
     if (!url) return '/logo_white.svg'
     
     // Handle relative uploads
-    if (url.startsWith('/uploads/')) {
+    if (url.startsWith('/uploads/') || url.startsWith('/files/')) {
         const apiBase = import.meta.env.VITE_API_BASE_URL || 'http://localhost:8080/api/v1'
         const origin = new URL(apiBase).origin
         return `${origin}${url}`
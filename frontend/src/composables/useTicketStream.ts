@@ -0,0 +1,40 @@
+import { onScopeDispose, watch, type Ref } from 'vue'
+import { useQueryClient } from '@tanstack/vue-query'
+import { connectTicketStream } from '@/api/ticketStream'
+
+/**
+ * Keeps ticket queries fresh from the server's ticket stream while enabled
+ * (e.g. while the user is logged in).
+ */
+export const useTicketStream = (enabled: Ref<boolean>) => {
+  const queryClient = useQueryClient()
+  let disconnect: (() => void) | null = null
+
+  const stop = () => {
+    disconnect?.()
+    disconnect = null
+  }
+
+  watch(
+    enabled,
+    (on) => {
+      stop()
+      if (!on) return
+      disconnect = connectTicketStream((event) => {
+        queryClient.invalidateQueries({ queryKey: ['tickets'] })
+        queryClient.invalidateQueries({ queryKey: ['dashboard-tickets'] })
+        queryClient.invalidateQueries({ queryKey: ['notifications'] })
+        if (event.type === 'reset' || !event.ticket_id) {
+          queryClient.invalidateQueries({ queryKey: ['comments'] })
+          queryClient.invalidateQueries({ queryKey: ['ticket'] })
+          return
+        }
+        queryClient.invalidateQueries({ queryKey: ['comments', event.ticket_id] })
+        queryClient.invalidateQueries({ queryKey: ['ticket', event.ticket_id] })
+      })
+    },
+    { immediate: true }
+  )
+
+  onScopeDispose(stop)
+}
@@ -9,7 +9,7 @@ export const useTickets = (page: Ref<number> = ref(1), limit: Ref<number> = ref(
   const query = useQuery({
     queryKey: queryKey,
     queryFn: () => ticketsApi.list(page.value, limit.value),
-    refetchInterval: 30 * 1000, // Auto-refresh every 30 seconds
+    refetchInterval: 5 * 60 * 1000, // Fallback; the ticket stream pushes changes
   })
 
   const tickets = computed(() => query.data.value?.data || [])
@@ -64,6 +64,13 @@ export default {
       rememberPassword: 'Remember your password?',
       signIn: 'Sign In',
     },
+    unsubscribe: {
+      title: 'Email Notifications',
+      loading: 'Updating your email preferences...',
+      successMessage: 'You will no longer receive these ticket update emails.',
+      errorMessage: 'This unsubscribe link is invalid.',
+      backToPortal: 'Go to portal',
+    },
     required: 'required',
   },
   header: {
@@ -72,6 +79,17 @@ export default {
     signedInAs: 'Signed in as',
     loggedOutSuccess: 'Logged out successfully',
   },
+  notifications: {
+    title: 'Notifications',
+    markAllRead: 'Mark all as read',
+    empty: 'No notifications yet',
+    loadMore: 'Load more',
+    types: {
+      status_changed: 'Status of {title} changed',
+      comment_added: 'New comment on {title}',
+      solution_proposed: 'A solution was proposed for {title}',
+    },
+  },
   toast: {
     closeNotification: 'Close notification',
   },
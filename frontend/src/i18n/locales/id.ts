@@ -64,6 +64,13 @@ export default {
       rememberPassword: 'Ingat kata sandi Anda?',
       signIn: 'Masuk',
     },
+    unsubscribe: {
+      title: 'Notifikasi Email',
+      loading: 'Memperbarui preferensi email Anda...',
+      successMessage: 'Anda tidak akan menerima email pembaruan tiket ini lagi.',
+      errorMessage: 'Link berhenti berlangganan tidak valid.',
+      backToPortal: 'Ke portal',
+    },
     required: 'wajib diisi',
   },
   header: {
@@ -72,6 +79,17 @@ export default {
     signedInAs: 'Masuk sebagai',
     loggedOutSuccess: 'Berhasil keluar',
   },
+  notifications: {
+    title: 'Notifikasi',
+    markAllRead: 'Tandai semua sudah dibaca',
+    empty: 'Belum ada notifikasi',
+    loadMore: 'Muat lebih banyak',
+    types: {
+      status_changed: 'Status {title} berubah',
+      comment_added: 'Komentar baru di {title}',
+      solution_proposed: 'Solusi diajukan untuk {title}',
+    },
+  },
   toast: {
     closeNotification: 'Tutup notifikasi',
   },
@@ -54,6 +54,12 @@ const router = createRouter({
       component: () => import('@/pages/Auth/ResetPassword.vue'),
       meta: { requiresGuest: true },
     },
+    {
+      path: '/unsubscribe',
+      name: 'Unsubscribe',
+      component: () => import('@/pages/Auth/Unsubscribe.vue'),
+      meta: { requiresGuest: false },
+    },
     {
       path: '/profile',
       name: 'profile',
@@ -16,7 +16,8 @@ export const useTenantStore = defineStore('tenant', () => {
   const tenantId = computed(() => tenant.value?.id || '')
   const tenantName = computed(() => tenant.value?.name || 'Helpdesk')
   const tenantSlug = computed(() => tenant.value?.slug || '')
-  const logoUrl = computed(() => tenant.value?.logo_url || '/logo_white.svg')
+  const logoUrl = computed(() => tenant.value?.logo_urls?.header || tenant.value?.logo_url || '/logo_white.svg')
+  const faviconUrl = computed(() => tenant.value?.logo_urls?.favicon || '')
   const primaryColor = computed(() => tenant.value?.primary_color || '#6929C4')
 
   /**
@@ -196,6 +197,7 @@ export const useTenantStore = defineStore('tenant', () => {
     tenantName,
     tenantSlug,
     logoUrl,
+    faviconUrl,
     primaryColor,
     
     // Actions